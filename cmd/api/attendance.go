@@ -0,0 +1,255 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/validator"
+)
+
+// attendanceAlert is emitted when a mark takes a student's attendance for a course from
+// at or above the policy threshold to below it.
+type attendanceAlert struct {
+	StudentID  int64   `json:"student_id"`
+	RollNo     int32   `json:"rollno"`
+	CourseID   int64   `json:"course_id"`
+	Percentage float64 `json:"percentage"`
+	Threshold  float64 `json:"threshold"`
+}
+
+func (app *application) markAttendanceHandler(c *gin.Context) {
+	courseID, err := app.readIDParam(c, "id")
+	if err != nil {
+		app.notFoundResponse(c)
+		return
+	}
+
+	v := validator.New()
+
+	date, err := data.ParseDate(c.Param("date"))
+	if err != nil {
+		v.AddError("date", err.Error())
+		app.failedValidationResponse(c, v.Errors)
+		return
+	}
+
+	var input struct {
+		Records []struct {
+			RollNo int32  `json:"rollno"`
+			Status string `json:"status"`
+		} `json:"records"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		app.badRequestResponse(c, err)
+		return
+	}
+
+	v.Check(!date.After(time.Now()), "date", "must not be in the future")
+	v.Check(len(input.Records) > 0, "records", "must contain at least one entry")
+
+	rollNos := make([]int32, len(input.Records))
+	for i, r := range input.Records {
+		rollNos[i] = r.RollNo
+		v.Check(validator.PermittedValue(r.Status, data.AttendanceStatuses...), "records", fmt.Sprintf("status for rollno %d must be one of %s", r.RollNo, strings.Join(data.AttendanceStatuses, ", ")))
+	}
+	v.Check(validator.Unique(rollNos), "records", "must not contain duplicate rollnos")
+
+	if !v.Valid() {
+		app.failedValidationResponse(c, v.Errors)
+		return
+	}
+
+	if _, err := app.models.Courses.Get(courseID); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(c)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return
+	}
+
+	resolved, err := app.models.Attendance.ResolveRollNos(courseID, rollNos)
+	if err != nil {
+		app.serverErrorResponse(c, err)
+		return
+	}
+
+	var unknown, ambiguous []string
+	marks := make(map[int64]string, len(input.Records))
+	rollNoOf := make(map[int64]int32, len(input.Records))
+	studentIDs := make([]int64, 0, len(input.Records))
+
+	for _, r := range input.Records {
+		ids := resolved[r.RollNo]
+
+		switch len(ids) {
+		case 0:
+			unknown = append(unknown, strconv.Itoa(int(r.RollNo)))
+		case 1:
+			marks[ids[0]] = r.Status
+			rollNoOf[ids[0]] = r.RollNo
+			studentIDs = append(studentIDs, ids[0])
+		default:
+			ambiguous = append(ambiguous, strconv.Itoa(int(r.RollNo)))
+		}
+	}
+
+	v.Check(len(unknown) == 0, "rollnos", "not enrolled in this course: "+strings.Join(unknown, ", "))
+	v.Check(len(ambiguous) == 0, "rollnos", "shared by more than one enrolled student: "+strings.Join(ambiguous, ", "))
+
+	if !v.Valid() {
+		app.failedValidationResponse(c, v.Errors)
+		return
+	}
+
+	before, after, err := app.models.Attendance.Mark(courseID, date, marks)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(c)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return
+	}
+
+	threshold := app.config.Attendance.Threshold
+	alerts := []attendanceAlert{}
+
+	for _, id := range studentIDs {
+		now, ok := after[id]
+		if !ok || now.Percentage >= threshold {
+			continue
+		}
+
+		if prev, ok := before[id]; ok && prev.Percentage < threshold {
+			continue
+		}
+
+		alert := attendanceAlert{
+			StudentID:  id,
+			RollNo:     rollNoOf[id],
+			CourseID:   courseID,
+			Percentage: now.Percentage,
+			Threshold:  threshold,
+		}
+		app.emitAttendanceAlert(alert)
+		alerts = append(alerts, alert)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"attendance": gin.H{
+			"course_id": courseID,
+			"date":      date,
+			"marked":    len(marks),
+		},
+		"alerts": alerts,
+	})
+}
+
+// emitAttendanceAlert publishes an attendance.below_threshold event as a structured log
+// entry and counts it on /metrics, where alerting rules can pick it up.
+func (app *application) emitAttendanceAlert(alert attendanceAlert) {
+	attendanceAlertsTotal.Inc()
+
+	app.logger.PrintInfo("attendance.below_threshold", map[string]string{
+		"event":      "attendance.below_threshold",
+		"student_id": strconv.FormatInt(alert.StudentID, 10),
+		"rollno":     strconv.Itoa(int(alert.RollNo)),
+		"course_id":  strconv.FormatInt(alert.CourseID, 10),
+		"percentage": strconv.FormatFloat(alert.Percentage, 'f', 2, 64),
+		"threshold":  strconv.FormatFloat(alert.Threshold, 'f', 2, 64),
+	})
+}
+
+type courseAttendance struct {
+	CourseID   int64                   `json:"course_id"`
+	CourseCode string                  `json:"course_code"`
+	Summary    *data.AttendanceSummary `json:"summary"`
+}
+
+func (app *application) listStudentAttendanceHandler(c *gin.Context) {
+	studentID, err := app.readIDParam(c, "id")
+	if err != nil {
+		app.notFoundResponse(c)
+		return
+	}
+
+	v := validator.New()
+
+	var from, to data.Date
+
+	if s := c.Query("from"); s != "" {
+		if from, err = data.ParseDate(s); err != nil {
+			v.AddError("from", err.Error())
+		}
+	}
+
+	if s := c.Query("to"); s != "" {
+		if to, err = data.ParseDate(s); err != nil {
+			v.AddError("to", err.Error())
+		}
+	}
+
+	v.Check(from.IsZero() || to.IsZero() || !from.After(to.Time), "to", "must not be before from")
+
+	if !v.Valid() {
+		app.failedValidationResponse(c, v.Errors)
+		return
+	}
+
+	if _, err := app.models.Students.Get(studentID); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(c)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return
+	}
+
+	records, err := app.models.Attendance.ListForStudent(studentID, from, to)
+	if err != nil {
+		app.serverErrorResponse(c, err)
+		return
+	}
+
+	overall := data.NewAttendanceSummary()
+	byCourse := map[int64]*courseAttendance{}
+
+	for _, r := range records {
+		overall.Add(r.Status)
+
+		ca, ok := byCourse[r.CourseID]
+		if !ok {
+			ca = &courseAttendance{CourseID: r.CourseID, CourseCode: r.CourseCode, Summary: data.NewAttendanceSummary()}
+			byCourse[r.CourseID] = ca
+		}
+		ca.Summary.Add(r.Status)
+	}
+
+	courses := make([]*courseAttendance, 0, len(byCourse))
+	for _, ca := range byCourse {
+		courses = append(courses, ca)
+	}
+	slices.SortFunc(courses, func(a, b *courseAttendance) int {
+		return strings.Compare(a.CourseCode, b.CourseCode)
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"attendance": gin.H{
+			"records": records,
+			"summary": overall,
+			"courses": courses,
+		},
+	})
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+)
+
+type mockAttendanceModel struct {
+	resolveFn func(courseID int64, rollNos []int32) (map[int32][]int64, error)
+	markFn    func(courseID int64, date data.Date, marks map[int64]string) (before, after map[int64]*data.AttendanceSummary, err error)
+	listFn    func(studentID int64, from, to data.Date) ([]*data.AttendanceRecord, error)
+}
+
+func (m *mockAttendanceModel) ResolveRollNos(courseID int64, rollNos []int32) (map[int32][]int64, error) {
+	return m.resolveFn(courseID, rollNos)
+}
+
+func (m *mockAttendanceModel) Mark(courseID int64, date data.Date, marks map[int64]string) (before, after map[int64]*data.AttendanceSummary, err error) {
+	return m.markFn(courseID, date, marks)
+}
+
+func (m *mockAttendanceModel) ListForStudent(studentID int64, from, to data.Date) ([]*data.AttendanceRecord, error) {
+	return m.listFn(studentID, from, to)
+}
+
+func TestMarkAttendanceHandler_AlertsWhenCrossingThreshold(t *testing.T) {
+	history := map[int64][]string{
+		10: {"present", "present", "present"},
+		11: {"present", "absent", "absent"},
+	}
+
+	mock := &mockAttendanceModel{
+		resolveFn: func(courseID int64, rollNos []int32) (map[int32][]int64, error) {
+			return map[int32][]int64{1: {10}, 2: {11}}, nil
+		},
+		markFn: func(courseID int64, date data.Date, marks map[int64]string) (before, after map[int64]*data.AttendanceSummary, err error) {
+			summaries := func() map[int64]*data.AttendanceSummary {
+				out := map[int64]*data.AttendanceSummary{}
+				for id := range marks {
+					s := data.NewAttendanceSummary()
+					for _, status := range history[id] {
+						s.Add(status)
+					}
+					out[id] = s
+				}
+				return out
+			}
+
+			before = summaries()
+			for id, status := range marks {
+				history[id] = append(history[id], status)
+			}
+			return before, summaries(), nil
+		},
+	}
+
+	app := newTestApp(&mockStudentModel{})
+	app.config.Attendance.Threshold = 75
+	app.models.Courses = &mockCourseModel{getFn: func(id int64) (*data.Course, error) { return &data.Course{ID: id}, nil }}
+	app.models.Attendance = mock
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/v1/courses/:id/attendance/:date", app.markAttendanceHandler)
+
+	body := []byte(`{"records":[{"rollno":1,"status":"absent"},{"rollno":2,"status":"absent"}]}`)
+	w := performRequest(router, "POST", "/v1/courses/3/attendance/2024-09-02", body)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Alerts []attendanceAlert `json:"alerts"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	// Student 10 drops from 100% to 75%, which is still at the threshold. Student 11 was
+	// already below it, so neither should trigger a new alert.
+	if len(resp.Alerts) != 0 {
+		t.Fatalf("expected no alerts, got %+v", resp.Alerts)
+	}
+
+	body = []byte(`{"records":[{"rollno":1,"status":"absent"}]}`)
+	w = performRequest(router, "POST", "/v1/courses/3/attendance/2024-09-03", body)
+
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resp.Alerts) != 1 || resp.Alerts[0].StudentID != 10 || resp.Alerts[0].Percentage != 60 {
+		t.Fatalf("expected one alert for student 10 at 60%%, got %+v", resp.Alerts)
+	}
+}
+
+func TestMarkAttendanceHandler_UnknownRollNo(t *testing.T) {
+	mock := &mockAttendanceModel{
+		resolveFn: func(courseID int64, rollNos []int32) (map[int32][]int64, error) {
+			return map[int32][]int64{1: {10}}, nil
+		},
+	}
+
+	app := newTestApp(&mockStudentModel{})
+	app.models.Courses = &mockCourseModel{getFn: func(id int64) (*data.Course, error) { return &data.Course{ID: id}, nil }}
+	app.models.Attendance = mock
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/v1/courses/:id/attendance/:date", app.markAttendanceHandler)
+
+	body := []byte(`{"records":[{"rollno":1,"status":"present"},{"rollno":9,"status":"present"}]}`)
+	w := performRequest(router, "POST", "/v1/courses/3/attendance/2024-09-02", body)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected %d, got %d", http.StatusUnprocessableEntity, w.Code)
+	}
+}
+
+func TestListStudentAttendanceHandler_Summaries(t *testing.T) {
+	mock := &mockAttendanceModel{
+		listFn: func(studentID int64, from, to data.Date) ([]*data.AttendanceRecord, error) {
+			return []*data.AttendanceRecord{
+				{CourseID: 2, CourseCode: "MA101", StudentID: studentID, Status: "excused"},
+				{CourseID: 1, CourseCode: "CS101", StudentID: studentID, Status: "present"},
+				{CourseID: 1, CourseCode: "CS101", StudentID: studentID, Status: "absent"},
+			}, nil
+		},
+	}
+
+	app := newTestApp(&mockStudentModel{getFn: func(id int64) (*data.Student, error) { return &data.Student{ID: id}, nil }})
+	app.models.Attendance = mock
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/v1/students/:id/attendance", app.listStudentAttendanceHandler)
+
+	w := performRequest(router, "GET", "/v1/students/1/attendance", nil)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Attendance struct {
+			Summary data.AttendanceSummary `json:"summary"`
+			Courses []courseAttendance     `json:"courses"`
+		} `json:"attendance"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := resp.Attendance.Summary.Percentage; got != 50 {
+		t.Errorf("expected an overall percentage of 50, got %v", got)
+	}
+
+	// A course with only excused sessions has missed nothing, the same as an empty summary.
+	want := map[string]float64{"CS101": 50, "MA101": 100}
+	for _, ca := range resp.Attendance.Courses {
+		if ca.Summary.Percentage != want[ca.CourseCode] {
+			t.Errorf("%s: expected %v, got %v", ca.CourseCode, want[ca.CourseCode], ca.Summary.Percentage)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/openapi"
+	"github.com/sai29/one2n_sre_bootcamp/pkg/client"
+)
+
+// newClientTestServer serves the real router over the in-memory store, with strict
+// contract validation so that any drift between the client, the handlers and the spec
+// fails the test.
+func newClientTestServer(t *testing.T) *client.Client {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	app := newTestApp(nil)
+	app.models.Students = data.NewMemoryStudentModel()
+	app.config.Contract.Mode = contractStrict
+
+	var err error
+	app.contract, err = openapi.NewValidator(openAPISpec())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(app.routes())
+	t.Cleanup(srv.Close)
+
+	c, err := client.New(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func TestClientStudentLifecycle(t *testing.T) {
+	c := newClientTestServer(t)
+	ctx := context.Background()
+
+	created, err := c.CreateStudent(ctx, client.NewStudent{Name: "Ada", RollNo: 1, DateOfBirth: "2001-02-03"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if created.ID == 0 || created.Status != data.StudentApplicant || created.DateOfBirth != "2001-02-03" {
+		t.Fatalf("unexpected student %+v", created)
+	}
+
+	got, err := c.GetStudent(ctx, created.ID)
+	if err != nil || got.Name != "Ada" {
+		t.Fatalf("GetStudent = %+v, %v", got, err)
+	}
+
+	address := "1 Main St"
+	updated, err := c.UpdateStudent(ctx, created.ID, client.StudentUpdate{Address: &address})
+	if err != nil || updated.Address != address || updated.Version != created.Version+1 {
+		t.Fatalf("UpdateStudent = %+v, %v", updated, err)
+	}
+
+	if err := c.DeleteStudent(ctx, created.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.GetStudent(ctx, created.ID); !errors.Is(err, client.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+
+	if err := c.DeleteStudent(ctx, created.ID); !errors.Is(err, client.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound deleting twice, got %v", err)
+	}
+}
+
+func TestClientValidationError(t *testing.T) {
+	c := newClientTestServer(t)
+
+	_, err := c.CreateStudent(context.Background(), client.NewStudent{Name: "Ada", RollNo: 1, Status: "graduated"})
+
+	var verr *client.ValidationError
+	if !errors.As(err, &verr) || verr.Fields["status"] == "" {
+		t.Fatalf("expected a validation error for status, got %v", err)
+	}
+}
+
+func TestClientListStudents(t *testing.T) {
+	c := newClientTestServer(t)
+	ctx := context.Background()
+
+	var want []int64
+	for i := int32(1); i <= 7; i++ {
+		s, err := c.CreateStudent(ctx, client.NewStudent{Name: "Student", RollNo: i})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, s.ID)
+	}
+
+	for _, size := range []int{1, 3, 7, 100} {
+		var got []int64
+		for s, err := range c.ListStudents(ctx, client.ListOptions{PageSize: size}) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, s.ID)
+		}
+
+		if !slices.Equal(got, want) {
+			t.Errorf("page size %d: got %v, want %v", size, got, want)
+		}
+	}
+
+	// Breaking out of the loop stops paging.
+	n := 0
+	for range c.ListStudents(ctx, client.ListOptions{PageSize: 2}) {
+		if n++; n == 3 {
+			break
+		}
+	}
+	if n != 3 {
+		t.Errorf("expected to stop after 3 students, got %d", n)
+	}
+}
+
+func TestClientStudentMatchesSpec(t *testing.T) {
+	spec := openAPISpec().Components.Schemas["Student"]
+	got := specReflector.Schema(client.Student{})
+
+	for name := range spec.Properties {
+		if got.Properties[name] == nil {
+			t.Errorf("client.Student is missing %q", name)
+		}
+	}
+
+	for name := range got.Properties {
+		if spec.Properties[name] == nil {
+			t.Errorf("client.Student has %q, which the API doesn't return", name)
+		}
+	}
+}
@@ -0,0 +1,395 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/gpa"
+	"github.com/sai29/one2n_sre_bootcamp/internal/jsonlog"
+	"github.com/sai29/one2n_sre_bootcamp/internal/settings"
+	"github.com/sai29/one2n_sre_bootcamp/internal/validator"
+)
+
+// envPrefix starts the environment variable of every setting, e.g. STUDENTS_API_DB_DSN
+// for db.dsn. Settings that predate the config file also keep their old variable, named
+// in the env tag.
+const envPrefix = "STUDENTS_API_"
+
+// config holds every API server setting. It is built from, in increasing precedence,
+// defaultConfig, the file named by -config, the environment and the command line.
+// Field keys in the file come from the yaml tags.
+type config struct {
+	Port int    `yaml:"port" env:"SERVER_PORT"`
+	Env  string `yaml:"env" env:"ENV"`
+
+	Log struct {
+		Level string `yaml:"level"`
+	} `yaml:"log"`
+
+	Server struct {
+		ReadTimeout     time.Duration `yaml:"read_timeout"`
+		WriteTimeout    time.Duration `yaml:"write_timeout"`
+		IdleTimeout     time.Duration `yaml:"idle_timeout"`
+		ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	} `yaml:"server"`
+
+	DB struct {
+		Driver       string        `yaml:"driver"`
+		DSN          string        `yaml:"dsn" env:"DATABASE_URL" secret:"true"`
+		MaxOpenConns int           `yaml:"max_open_conns"`
+		MaxIdleConns int           `yaml:"max_idle_conns"`
+		MaxIdleTime  time.Duration `yaml:"max_idle_time"`
+		QueryTimeout time.Duration `yaml:"query_timeout"`
+		AutoMigrate  bool          `yaml:"auto_migrate"`
+		SchemaMode   string        `yaml:"schema_mode"`
+		Replicas     struct {
+			DSNs           []string      `yaml:"dsns" secret:"true"`
+			CheckInterval  time.Duration `yaml:"check_interval"`
+			ReadYourWrites time.Duration `yaml:"read_your_writes"`
+		} `yaml:"replicas"`
+	} `yaml:"db"`
+
+	CORS struct {
+		TrustedOrigins []string `yaml:"trusted_origins"`
+	} `yaml:"cors"`
+
+	Limiter struct {
+		Enabled bool    `yaml:"enabled"`
+		RPS     float64 `yaml:"rps"`
+		Burst   int     `yaml:"burst"`
+	} `yaml:"limiter"`
+
+	Cache struct {
+		Backend  string        `yaml:"backend"`
+		Size     int           `yaml:"size"`
+		TTL      time.Duration `yaml:"ttl"`
+		RedisURL string        `yaml:"redis_url" env:"REDIS_URL" secret:"true"`
+	} `yaml:"cache"`
+
+	Outbox struct {
+		Sinks      []string      `yaml:"sinks"`
+		WebhookURL string        `yaml:"webhook_url" secret:"true"`
+		NATSURL    string        `yaml:"nats_url" env:"NATS_URL" secret:"true"`
+		Interval   time.Duration `yaml:"interval"`
+		BatchSize  int           `yaml:"batch_size"`
+		Retention  time.Duration `yaml:"retention"`
+	} `yaml:"outbox"`
+
+	Webhooks struct {
+		Workers     int           `yaml:"workers"`
+		MaxAttempts int           `yaml:"max_attempts"`
+		Backoff     time.Duration `yaml:"backoff"`
+		Timeout     time.Duration `yaml:"timeout"`
+
+		// AllowPrivate lets webhooks target loopback and private addresses, for local
+		// development. Leave it off wherever integrators can register URLs.
+		AllowPrivate bool `yaml:"allow_private"`
+	} `yaml:"webhooks"`
+
+	Stream struct {
+		Buffer    int           `yaml:"buffer"`
+		Heartbeat time.Duration `yaml:"heartbeat"`
+	} `yaml:"stream"`
+
+	GraphQL struct {
+		MaxDepth      int `yaml:"max_depth"`
+		MaxComplexity int `yaml:"max_complexity"`
+	} `yaml:"graphql"`
+
+	GRPC struct {
+		Port       int      `yaml:"port"`
+		AuthTokens []string `yaml:"auth_tokens" env:"GRPC_AUTH_TOKENS" secret:"true"`
+
+		// AllowUnauthenticated serves gRPC without tokens, for local development. Without
+		// it the server refuses to start with no auth_tokens.
+		AllowUnauthenticated bool `yaml:"allow_unauthenticated"`
+	} `yaml:"grpc"`
+
+	Grading struct {
+		Scale gpa.Scale `yaml:"scale"`
+	} `yaml:"grading"`
+
+	Attendance struct {
+		Threshold float64 `yaml:"threshold"`
+	} `yaml:"attendance"`
+
+	Contract struct {
+		Mode string `yaml:"mode" env:"CONTRACT_VALIDATION"`
+	} `yaml:"contract"`
+}
+
+func defaultConfig() config {
+	var cfg config
+
+	cfg.Port = 4000
+	cfg.Env = "development"
+
+	cfg.Log.Level = "info"
+
+	cfg.Server.ReadTimeout = 10 * time.Second
+	cfg.Server.WriteTimeout = 30 * time.Second
+	cfg.Server.IdleTimeout = time.Minute
+	cfg.Server.ShutdownTimeout = 5 * time.Second
+
+	cfg.DB.Driver = dbDriverPostgres
+	cfg.DB.MaxOpenConns = 25
+	cfg.DB.MaxIdleConns = 25
+	cfg.DB.MaxIdleTime = 15 * time.Minute
+	cfg.DB.QueryTimeout = 3 * time.Second
+	cfg.DB.SchemaMode = schemaModeStrict
+	cfg.DB.Replicas.CheckInterval = 5 * time.Second
+	cfg.DB.Replicas.ReadYourWrites = 5 * time.Second
+
+	cfg.Limiter.RPS = 10
+	cfg.Limiter.Burst = 20
+
+	cfg.Cache.Backend = cacheBackendNone
+	cfg.Cache.Size = 10000
+	cfg.Cache.TTL = time.Minute
+
+	cfg.Outbox.Sinks = []string{outboxSinkLog}
+	cfg.Outbox.Interval = time.Second
+	cfg.Outbox.BatchSize = 100
+	cfg.Outbox.Retention = 7 * 24 * time.Hour
+
+	cfg.Webhooks.Workers = 4
+	cfg.Webhooks.MaxAttempts = 8
+	cfg.Webhooks.Backoff = 5 * time.Second
+	cfg.Webhooks.Timeout = 10 * time.Second
+
+	cfg.Stream.Buffer = 1000
+	cfg.Stream.Heartbeat = 15 * time.Second
+
+	cfg.GraphQL.MaxDepth = 8
+	cfg.GraphQL.MaxComplexity = 5000
+
+	cfg.Grading.Scale = gpa.DefaultScale()
+
+	cfg.Attendance.Threshold = 75
+
+	cfg.Contract.Mode = contractOff
+
+	return cfg
+}
+
+// flags registers a flag for each setting on fs. The current value of each setting is
+// its flag default, so parsing only changes the settings given on the command line.
+func (cfg *config) flags(fs *flag.FlagSet) {
+	fs.IntVar(&cfg.Port, "port", cfg.Port, "API server port")
+
+	fs.StringVar(&cfg.Env, "env", cfg.Env, "Environment (dev|stage|prod)")
+
+	fs.StringVar(&cfg.Log.Level, "log-level", cfg.Log.Level, "Minimum level of log entries written (info|error|fatal|off)")
+
+	fs.DurationVar(&cfg.Server.ReadTimeout, "server-read-timeout", cfg.Server.ReadTimeout, "Maximum time to read a request, including the body")
+	fs.DurationVar(&cfg.Server.WriteTimeout, "server-write-timeout", cfg.Server.WriteTimeout, "Maximum time to write a response")
+	fs.DurationVar(&cfg.Server.IdleTimeout, "server-idle-timeout", cfg.Server.IdleTimeout, "How long idle keep-alive connections are kept open")
+	fs.DurationVar(&cfg.Server.ShutdownTimeout, "shutdown-timeout", cfg.Server.ShutdownTimeout, "How long in-flight requests are given to finish on shutdown")
+
+	fs.StringVar(&cfg.DB.Driver, "db-driver", cfg.DB.Driver, "Storage backend (postgres|sqlite|memory)")
+	fs.StringVar(&cfg.DB.DSN, "db-dsn", cfg.DB.DSN, "Database DSN (a file path for sqlite)")
+
+	fs.IntVar(&cfg.DB.MaxOpenConns, "db-max-open-conns", cfg.DB.MaxOpenConns, "Postgres max open conns")
+	fs.IntVar(&cfg.DB.MaxIdleConns, "db-max-idle-conns", cfg.DB.MaxIdleConns, "Postgres max idle conns")
+	fs.DurationVar(&cfg.DB.MaxIdleTime, "db-max-idle-time", cfg.DB.MaxIdleTime, "Postgres max conn idle time")
+	fs.DurationVar(&cfg.DB.QueryTimeout, "db-query-timeout", cfg.DB.QueryTimeout, "Timeout for a single database query")
+	fs.BoolVar(&cfg.DB.AutoMigrate, "db-auto-migrate", cfg.DB.AutoMigrate, "Apply pending migrations before serving")
+	fs.StringVar(&cfg.DB.SchemaMode, "db-schema-mode", cfg.DB.SchemaMode, "What to do when the schema is behind or dirty (strict|degraded)")
+
+	fs.Func("db-replica-dsn", "Read replica DSNs (space separated)", func(val string) error {
+		cfg.DB.Replicas.DSNs = strings.Fields(val)
+		return nil
+	})
+	fs.DurationVar(&cfg.DB.Replicas.CheckInterval, "db-replica-check-interval", cfg.DB.Replicas.CheckInterval, "How often replicas are health checked")
+	fs.DurationVar(&cfg.DB.Replicas.ReadYourWrites, "db-read-your-writes", cfg.DB.Replicas.ReadYourWrites, "How long a client's reads stay on the primary after it writes")
+
+	fs.StringVar(&cfg.Cache.Backend, "cache-backend", cfg.Cache.Backend, "Student lookup cache (none|memory|redis)")
+	fs.IntVar(&cfg.Cache.Size, "cache-size", cfg.Cache.Size, "Maximum entries in the in-memory cache")
+	fs.DurationVar(&cfg.Cache.TTL, "cache-ttl", cfg.Cache.TTL, "How long cached students are kept")
+	fs.StringVar(&cfg.Cache.RedisURL, "cache-redis-url", cfg.Cache.RedisURL, "Redis URL for the redis cache backend")
+
+	fs.Func("outbox-sinks", "Sinks for student change events (space separated: log, webhook, nats)", func(val string) error {
+		cfg.Outbox.Sinks = strings.Fields(val)
+		return nil
+	})
+	fs.StringVar(&cfg.Outbox.WebhookURL, "outbox-webhook-url", cfg.Outbox.WebhookURL, "URL the webhook sink POSTs events to")
+	fs.StringVar(&cfg.Outbox.NATSURL, "outbox-nats-url", cfg.Outbox.NATSURL, "NATS server URL for the nats sink")
+	fs.DurationVar(&cfg.Outbox.Interval, "outbox-interval", cfg.Outbox.Interval, "How often the outbox is polled for new events")
+	fs.IntVar(&cfg.Outbox.BatchSize, "outbox-batch-size", cfg.Outbox.BatchSize, "Maximum events claimed and published per outbox batch")
+	fs.DurationVar(&cfg.Outbox.Retention, "outbox-retention", cfg.Outbox.Retention, "How long published outbox events are kept (0 keeps them forever)")
+
+	fs.IntVar(&cfg.Webhooks.Workers, "webhook-workers", cfg.Webhooks.Workers, "Concurrent webhook deliveries")
+	fs.IntVar(&cfg.Webhooks.MaxAttempts, "webhook-max-attempts", cfg.Webhooks.MaxAttempts, "Attempts before a webhook delivery is marked dead")
+	fs.DurationVar(&cfg.Webhooks.Backoff, "webhook-backoff", cfg.Webhooks.Backoff, "Wait before the first webhook retry; doubles on each further failure, up to an hour")
+	fs.DurationVar(&cfg.Webhooks.Timeout, "webhook-timeout", cfg.Webhooks.Timeout, "Timeout for a single webhook request")
+	fs.BoolVar(&cfg.Webhooks.AllowPrivate, "webhook-allow-private", cfg.Webhooks.AllowPrivate, "Allow webhooks to loopback, private and link-local addresses (local development only)")
+
+	fs.IntVar(&cfg.Stream.Buffer, "stream-buffer", cfg.Stream.Buffer, "Student events kept for Last-Event-ID replay")
+	fs.DurationVar(&cfg.Stream.Heartbeat, "stream-heartbeat", cfg.Stream.Heartbeat, "Interval between heartbeat comments on event streams")
+
+	fs.IntVar(&cfg.GraphQL.MaxDepth, "graphql-max-depth", cfg.GraphQL.MaxDepth, "Maximum selection depth of a GraphQL query (0 for no limit)")
+	fs.IntVar(&cfg.GraphQL.MaxComplexity, "graphql-max-complexity", cfg.GraphQL.MaxComplexity, "Maximum estimated cost of a GraphQL query (0 for no limit)")
+
+	fs.IntVar(&cfg.GRPC.Port, "grpc-port", cfg.GRPC.Port, "gRPC server port (0 disables the gRPC server)")
+	fs.Func("grpc-auth-tokens", "Bearer tokens accepted by the gRPC server (space separated)", func(val string) error {
+		cfg.GRPC.AuthTokens = strings.Fields(val)
+		return nil
+	})
+	fs.BoolVar(&cfg.GRPC.AllowUnauthenticated, "grpc-allow-unauthenticated", cfg.GRPC.AllowUnauthenticated, "Serve gRPC without auth tokens (local development only)")
+
+	fs.Func("cors-trusted-origins", "Trusted CORS origins (space separated)", func(val string) error {
+		cfg.CORS.TrustedOrigins = strings.Fields(val)
+		return nil
+	})
+
+	fs.BoolVar(&cfg.Limiter.Enabled, "limiter-enabled", cfg.Limiter.Enabled, "Rate limit requests per client IP")
+	fs.Float64Var(&cfg.Limiter.RPS, "limiter-rps", cfg.Limiter.RPS, "Requests per second allowed for each client IP")
+	fs.IntVar(&cfg.Limiter.Burst, "limiter-burst", cfg.Limiter.Burst, "Requests a client IP may make in a burst")
+
+	fs.TextVar(&cfg.Grading.Scale, "grading-scale", cfg.Grading.Scale, "Grading scale as grade=points pairs, \"-\" excludes a grade from GPA (e.g. \"A=4,B=3,C=2,F=0,W=-\")")
+
+	fs.Float64Var(&cfg.Attendance.Threshold, "attendance-threshold", cfg.Attendance.Threshold, "Attendance percentage below which an alert is emitted")
+
+	fs.StringVar(&cfg.Contract.Mode, "contract-validation", cfg.Contract.Mode, "Check requests and responses against the OpenAPI spec (off|log|strict)")
+}
+
+// configOptions are the flags that control loading rather than being settings.
+type configOptions struct {
+	print bool
+}
+
+func (o *configOptions) flags(fs *flag.FlagSet) {
+	fs.BoolVar(&o.print, "print-config", false, "Print the effective configuration, with secrets redacted, and exit")
+}
+
+// loadConfig builds the configuration from the defaults, the config file, the
+// environment and args, in that order. Every problem found in the file and the
+// environment is returned at once. The configuration is not validated.
+func loadConfig(args []string, lookupEnv func(string) (string, bool), output io.Writer) (config, configOptions, error) {
+	var opts configOptions
+
+	cfg, rest, err := parseConfig("students_api", args, lookupEnv, output, func(fs *flag.FlagSet, cfg *config) {
+		cfg.flags(fs)
+		opts.flags(fs)
+	})
+
+	if len(rest) > 0 {
+		err = errors.Join(err, fmt.Errorf("unexpected arguments: %s", strings.Join(rest, " ")))
+	}
+
+	return cfg, opts, err
+}
+
+// parseConfig loads the configuration like loadConfig for a command whose flags are
+// registered by define, which must write settings into cfg, and returns the arguments
+// left after the flags. The subcommands use it so that they read the same file and
+// environment as the server. -config is added here.
+func parseConfig(name string, args []string, lookupEnv func(string) (string, bool), output io.Writer, define func(fs *flag.FlagSet, cfg *config)) (config, []string, error) {
+	defaultPath, _ := lookupEnv(envPrefix + "CONFIG")
+	configFlag := func(fs *flag.FlagSet, path *string) {
+		fs.StringVar(path, "config", defaultPath, "YAML or TOML config file (also "+envPrefix+"CONFIG)")
+	}
+
+	// The file has to be read before the flags are applied, so the command line is parsed
+	// twice: first into a throwaway config to find -config, and then for real.
+	var path string
+	{
+		scratch := defaultConfig()
+		fs := flag.NewFlagSet(name, flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		define(fs, &scratch)
+		configFlag(fs, &path)
+		_ = fs.Parse(args)
+	}
+
+	cfg := defaultConfig()
+
+	var errs []error
+
+	if path != "" {
+		if err := settings.LoadFile(path, &cfg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := settings.LoadEnv(&cfg, envPrefix, lookupEnv); err != nil {
+		errs = append(errs, err)
+	}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(output)
+	define(fs, &cfg)
+	configFlag(fs, &path)
+
+	if err := fs.Parse(args); err != nil {
+		return cfg, nil, errors.Join(append(errs, err)...)
+	}
+
+	return cfg, fs.Args(), errors.Join(errs...)
+}
+
+// validate checks the settings against each other and returns every problem, keyed by
+// setting.
+func (cfg *config) validate() map[string]string {
+	v := validator.New()
+
+	v.Check(cfg.Port > 0 && cfg.Port <= 65535, "port", "must be between 1 and 65535")
+
+	_, err := jsonlog.ParseLevel(cfg.Log.Level)
+	v.Check(err == nil, "log.level", "must be info, error, fatal or off")
+
+	v.Check(cfg.Server.ReadTimeout > 0, "server.read_timeout", "must be greater than zero")
+	v.Check(cfg.Server.WriteTimeout > 0, "server.write_timeout", "must be greater than zero")
+	v.Check(cfg.Server.IdleTimeout > 0, "server.idle_timeout", "must be greater than zero")
+	v.Check(cfg.Server.ShutdownTimeout > 0, "server.shutdown_timeout", "must be greater than zero")
+
+	v.Check(validator.PermittedValue(cfg.DB.Driver, dbDriverPostgres, dbDriverSQLite, dbDriverMemory), "db.driver", "must be postgres, sqlite or memory")
+	v.Check(cfg.DB.DSN != "" || cfg.DB.Driver == dbDriverMemory, "db.dsn", fmt.Sprintf("must be set for the %s driver", cfg.DB.Driver))
+	v.Check(cfg.DB.MaxOpenConns >= 0, "db.max_open_conns", "must not be negative")
+	v.Check(cfg.DB.MaxIdleConns >= 0, "db.max_idle_conns", "must not be negative")
+	v.Check(cfg.DB.MaxIdleTime >= 0, "db.max_idle_time", "must not be negative")
+	v.Check(cfg.DB.QueryTimeout > 0, "db.query_timeout", "must be greater than zero")
+	v.Check(validator.PermittedValue(cfg.DB.SchemaMode, schemaModeStrict, schemaModeDegraded), "db.schema_mode", "must be strict or degraded")
+	v.Check(len(cfg.DB.Replicas.DSNs) == 0 || cfg.DB.Driver == dbDriverPostgres, "db.replicas.dsns", fmt.Sprintf("are only supported with the %s driver", dbDriverPostgres))
+	v.Check(cfg.DB.Replicas.CheckInterval > 0, "db.replicas.check_interval", "must be greater than zero")
+	v.Check(cfg.DB.Replicas.ReadYourWrites >= 0, "db.replicas.read_your_writes", "must not be negative")
+
+	v.Check(cfg.Limiter.RPS > 0, "limiter.rps", "must be greater than zero")
+	v.Check(cfg.Limiter.Burst >= 1, "limiter.burst", "must be at least 1")
+
+	v.Check(validator.PermittedValue(cfg.Cache.Backend, cacheBackendNone, cacheBackendMemory, cacheBackendRedis), "cache.backend", "must be none, memory or redis")
+	v.Check(cfg.Cache.Backend != cacheBackendMemory || cfg.Cache.Size >= 1, "cache.size", "must be at least 1")
+	v.Check(cfg.Cache.TTL > 0, "cache.ttl", "must be greater than zero")
+
+	for _, sink := range cfg.Outbox.Sinks {
+		v.Check(validator.PermittedValue(sink, outboxSinkLog, outboxSinkWebhook, outboxSinkNATS), "outbox.sinks", fmt.Sprintf("contains unknown sink %q", sink))
+	}
+	v.Check(cfg.Outbox.Interval > 0, "outbox.interval", "must be greater than zero")
+	v.Check(cfg.Outbox.BatchSize >= 1, "outbox.batch_size", "must be at least 1")
+	v.Check(cfg.Outbox.Retention >= 0, "outbox.retention", "must not be negative")
+
+	v.Check(cfg.Webhooks.Workers >= 1, "webhooks.workers", "must be at least 1")
+	v.Check(cfg.Webhooks.MaxAttempts >= 1, "webhooks.max_attempts", "must be at least 1")
+	v.Check(cfg.Webhooks.Backoff > 0, "webhooks.backoff", "must be greater than zero")
+	v.Check(cfg.Webhooks.Timeout > 0, "webhooks.timeout", "must be greater than zero")
+
+	v.Check(cfg.Stream.Buffer >= 1, "stream.buffer", "must be at least 1")
+	v.Check(cfg.Stream.Heartbeat > 0, "stream.heartbeat", "must be greater than zero")
+
+	v.Check(cfg.GraphQL.MaxDepth >= 0, "graphql.max_depth", "must not be negative")
+	v.Check(cfg.GraphQL.MaxComplexity >= 0, "graphql.max_complexity", "must not be negative")
+
+	v.Check(cfg.GRPC.Port >= 0 && cfg.GRPC.Port <= 65535, "grpc.port", "must be between 0 and 65535")
+	v.Check(cfg.GRPC.Port == 0 || cfg.GRPC.Port != cfg.Port, "grpc.port", "must differ from port")
+	v.Check(cfg.GRPC.Port == 0 || len(cfg.GRPC.AuthTokens) > 0 || cfg.GRPC.AllowUnauthenticated, "grpc.auth_tokens", "must be provided unless grpc.allow_unauthenticated is set")
+
+	v.Check(cfg.Attendance.Threshold >= 0 && cfg.Attendance.Threshold <= 100, "attendance.threshold", "must be between 0 and 100")
+
+	v.Check(validator.PermittedValue(cfg.Contract.Mode, contractOff, contractLog, contractStrict), "contract.mode", "must be off, log or strict")
+
+	return v.Errors
+}
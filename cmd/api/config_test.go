@@ -0,0 +1,222 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/settings"
+)
+
+func envLookup(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte(`
+port: 5000
+env: staging
+server:
+  write_timeout: 45s
+db:
+  driver: memory
+  max_open_conns: 50
+  query_timeout: 2s
+cache:
+  backend: memory
+  ttl: 10m
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env := map[string]string{
+		"STUDENTS_API_CONFIG":           path,
+		"SERVER_PORT":                   "6000",
+		"STUDENTS_API_DB_QUERY_TIMEOUT": "4s",
+		"STUDENTS_API_CACHE_TTL":        "20m",
+	}
+
+	cfg, _, err := loadConfig([]string{"-cache-ttl", "30m"}, envLookup(env), io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checks := []struct {
+		name      string
+		got, want any
+	}{
+		{"default", cfg.Server.ReadTimeout, 10 * time.Second},
+		{"file over default", cfg.Server.WriteTimeout, 45 * time.Second},
+		{"file over default", cfg.DB.MaxOpenConns, 50},
+		{"file over default", cfg.Env, "staging"},
+		{"env over file", cfg.Port, 6000},
+		{"env over file", cfg.DB.QueryTimeout, 4 * time.Second},
+		{"flag over env", cfg.Cache.TTL, 30 * time.Minute},
+	}
+
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("%s: got %v, want %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestLoadConfigReportsFileAndEnvErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	err := os.WriteFile(path, []byte("port = \"x\"\nunknown = 1\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env := map[string]string{"STUDENTS_API_CACHE_SIZE": "lots"}
+
+	_, _, err = loadConfig([]string{"-config", path}, envLookup(env), io.Discard)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	for _, want := range []string{"port:", "unknown:", "STUDENTS_API_CACHE_SIZE:"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %s:\n%v", want, err)
+		}
+	}
+}
+
+func TestLoadConfigKeepsFileErrorsOnBadFlags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte("port = \"x\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err := loadConfig([]string{"-config", path, "-no-such-flag"}, envLookup(nil), io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "port:") || !strings.Contains(err.Error(), "no-such-flag") {
+		t.Fatalf("expected both the file and the flag error, got %v", err)
+	}
+}
+
+func TestParseConfigForSubcommands(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("env: staging\ndb:\n  dsn: postgres://file/students\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var steps string
+
+	cfg, rest, err := parseConfig("migrate", []string{"-config", path, "-steps", "2", "down", "2"}, envLookup(nil), io.Discard, func(fs *flag.FlagSet, cfg *config) {
+		fs.StringVar(&cfg.DB.DSN, "db-dsn", cfg.DB.DSN, "")
+		fs.StringVar(&steps, "steps", "", "")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.DB.DSN != "postgres://file/students" || cfg.Env != "staging" || steps != "2" {
+		t.Errorf("unexpected config: dsn %q, env %q, steps %q", cfg.DB.DSN, cfg.Env, steps)
+	}
+	if len(rest) != 2 || rest[0] != "down" {
+		t.Errorf("unexpected remaining arguments %q", rest)
+	}
+
+	cfg, _, err = parseConfig("migrate", []string{"-config", path, "-db-dsn", "postgres://flag/students"}, envLookup(nil), io.Discard, func(fs *flag.FlagSet, cfg *config) {
+		fs.StringVar(&cfg.DB.DSN, "db-dsn", cfg.DB.DSN, "")
+	})
+	if err != nil || cfg.DB.DSN != "postgres://flag/students" {
+		t.Errorf("expected -db-dsn to override the file, got %q, %v", cfg.DB.DSN, err)
+	}
+}
+
+func TestLoadConfigHelp(t *testing.T) {
+	_, _, err := loadConfig([]string{"-h"}, envLookup(nil), io.Discard)
+	if !errors.Is(err, flag.ErrHelp) {
+		t.Fatalf("got %v, want flag.ErrHelp", err)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.DB.DSN = "postgres://localhost/students"
+
+	if problems := cfg.validate(); len(problems) != 0 {
+		t.Fatalf("defaults are invalid: %v", problems)
+	}
+
+	cfg.Port = 0
+	cfg.Server.ReadTimeout = 0
+	cfg.DB.Driver = "mysql"
+	cfg.Cache.Backend = "memcached"
+	cfg.Attendance.Threshold = 120
+	cfg.GRPC.Port = 9090
+
+	problems := cfg.validate()
+
+	for _, key := range []string{"port", "server.read_timeout", "db.driver", "cache.backend", "attendance.threshold", "grpc.auth_tokens"} {
+		if _, ok := problems[key]; !ok {
+			t.Errorf("no problem reported for %s: %v", key, problems)
+		}
+	}
+}
+
+func TestPrintConfigRedactsSecrets(t *testing.T) {
+	env := map[string]string{
+		"DATABASE_URL":     "postgres://students:hunter2@db:5432/students",
+		"GRPC_AUTH_TOKENS": "token-one token-two",
+	}
+
+	cfg, opts, err := loadConfig([]string{"-print-config"}, envLookup(env), io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opts.print {
+		t.Fatal("-print-config was not recognised")
+	}
+
+	b, err := settings.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(b)
+
+	for _, secret := range []string{"hunter2", "token-one", "token-two"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("%s leaked:\n%s", secret, out)
+		}
+	}
+
+	for _, want := range []string{"dsn: postgres://students:xxxxx@db:5432/students", "query_timeout: 3s", "scale: A=4,A+=4"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+// config.example.yaml documents the defaults, so it must stay in step with them.
+func TestExampleConfigMatchesDefaults(t *testing.T) {
+	cfg := defaultConfig()
+	if err := settings.LoadFile("../../config.example.yaml", &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := settings.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := settings.Marshal(defaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != string(want) {
+		t.Errorf("config.example.yaml differs from the defaults:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sai29/one2n_sre_bootcamp/internal/openapi"
+)
+
+const (
+	contractOff    = "off"
+	contractLog    = "log"
+	contractStrict = "strict"
+)
+
+// maxContractBody caps how much of a request or logged response body is held for
+// checking. Larger bodies are passed through unchecked.
+const maxContractBody = 8 << 20
+
+// validateContract checks requests and responses against the OpenAPI spec. Violations
+// are logged with the operation ID and counted in contract_violations_total. In strict
+// mode a request that breaks the contract is rejected with a 400 before it reaches the
+// handler, and a response that breaks it is replaced by a 500, so drift fails loudly in
+// dev and staging rather than in a client. The mode is read on each request, so a
+// reload can switch validation on or off.
+func (app *application) validateContract() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		mode := app.liveConfig().Contract.Mode
+		if mode == contractOff {
+			c.Next()
+			return
+		}
+
+		strict := mode == contractStrict
+
+		rt := app.contract.Route(c.Request.Method, openapi.PathFromGin(c.FullPath()))
+		if rt == nil {
+			c.Next()
+			return
+		}
+
+		if body, ok := readBody(c.Request, rt); ok {
+			params := make(map[string]string, len(c.Params))
+			for _, p := range c.Params {
+				params[p.Key] = p.Value
+			}
+
+			if problems := rt.CheckRequest(c.Request, params, body); len(problems) > 0 {
+				app.contractViolation(c, rt, "request", problems)
+
+				if strict {
+					message := "the request does not match the API contract: " + strings.Join(problems, "; ")
+					app.errorResponse(c, http.StatusBadRequest, message)
+					c.Abort()
+					return
+				}
+			}
+		}
+
+		// File downloads and event streams are passed straight through.
+		if rt.Streams() {
+			c.Next()
+			return
+		}
+
+		w := &contractWriter{ResponseWriter: c.Writer, status: http.StatusOK, buffer: strict}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		var problems []string
+		if !w.truncated {
+			problems = rt.CheckResponse(w.status, w.Header(), w.body.Bytes())
+		}
+
+		if len(problems) > 0 {
+			app.contractViolation(c, rt, "response", problems)
+		}
+
+		if !strict {
+			return
+		}
+
+		if len(problems) > 0 {
+			for key := range w.Header() {
+				w.Header().Del(key)
+			}
+			message := "the server encountered a problem and could not process your request"
+			app.errorResponse(c, http.StatusInternalServerError, message)
+			return
+		}
+
+		c.Writer.WriteHeader(w.status)
+		c.Writer.Write(w.body.Bytes())
+	}
+}
+
+func (app *application) contractViolation(c *gin.Context, rt *openapi.Route, direction string, problems []string) {
+	contractViolationsTotal.WithLabelValues(rt.OperationID, direction).Inc()
+
+	properties := map[string]string{
+		"operation_id": rt.OperationID,
+		"method":       c.Request.Method,
+		"path":         c.Request.URL.Path,
+		"problems":     strings.Join(problems, "; "),
+	}
+
+	// A bad request is the client's mistake; a bad response is ours.
+	if direction == "request" {
+		app.logger.PrintInfo("request does not match the API contract", properties)
+		return
+	}
+
+	app.logger.PrintError(errors.New("response does not match the API contract"), properties)
+}
+
+// readBody reads a request body the route checks and puts it back for the handler. It
+// reports false when the body is too large to check.
+func readBody(r *http.Request, rt *openapi.Route) ([]byte, bool) {
+	if r.Body == nil || r.Body == http.NoBody || !rt.ChecksBody(r.Header.Get("Content-Type")) {
+		return nil, true
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxContractBody+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+
+	if err != nil || len(body) > maxContractBody {
+		return nil, false
+	}
+
+	return body, true
+}
+
+// contractWriter captures a response body for checking. When buffer is set nothing
+// reaches the client until the middleware releases it; otherwise the body is copied as
+// it is written, up to maxContractBody.
+type contractWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	status    int
+	buffer    bool
+	truncated bool
+}
+
+func (w *contractWriter) WriteHeader(status int) {
+	w.status = status
+	if !w.buffer {
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (w *contractWriter) WriteHeaderNow() {
+	if !w.buffer {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *contractWriter) Status() int {
+	return w.status
+}
+
+func (w *contractWriter) Write(b []byte) (int, error) {
+	if w.buffer {
+		return w.body.Write(b)
+	}
+
+	if w.body.Len()+len(b) > maxContractBody {
+		w.truncated = true
+	} else {
+		w.body.Write(b)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *contractWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/openapi"
+)
+
+func newContractTestApp(t *testing.T, mode string, mock *mockStudentModel) *application {
+	t.Helper()
+
+	app := newTestApp(mock)
+	app.config.Contract.Mode = mode
+
+	var err error
+	app.contract, err = openapi.NewValidator(openAPISpec())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return app
+}
+
+func TestContractValidationPassesHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	student := &data.Student{ID: 1, Name: "Ada", RollNo: 7, Status: data.StudentApplicant, Version: 1}
+
+	app := newContractTestApp(t, contractStrict, &mockStudentModel{
+		insertFn: func(s *data.Student) error {
+			s.ID = 1
+			return nil
+		},
+		getFn: func(id int64) (*data.Student, error) {
+			if id != 1 {
+				return nil, data.ErrRecordNotFound
+			}
+			return student, nil
+		},
+		listFn: func() ([]*data.Student, error) { return []*data.Student{student}, nil },
+	})
+	router := app.routes()
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{http.MethodPost, "/v1/students", `{"name": "Ada", "rollno": 7, "date_of_birth": "2001-02-03"}`, http.StatusCreated},
+		{http.MethodGet, "/v1/students", "", http.StatusOK},
+		{http.MethodGet, "/v1/students/1", "", http.StatusOK},
+		{http.MethodGet, "/v1/students/2", "", http.StatusNotFound},
+		{http.MethodPost, "/v1/students", `{"name": "", "rollno": 7}`, http.StatusUnprocessableEntity},
+		{http.MethodGet, "/v1/healthcheck", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		w := performRequest(router, tt.method, tt.path, []byte(tt.body))
+		if w.Code != tt.status {
+			t.Errorf("%s %s: expected status %d, got %d: %s", tt.method, tt.path, tt.status, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestContractValidationRejectsRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	called := false
+	app := newContractTestApp(t, contractStrict, &mockStudentModel{
+		insertFn: func(s *data.Student) error {
+			called = true
+			return nil
+		},
+	})
+
+	before := testutil.ToFloat64(contractViolationsTotal.WithLabelValues("createStudent", "request"))
+
+	w := performRequest(app.routes(), http.MethodPost, "/v1/students", []byte(`{"name": "Ada", "rollno": "seven"}`))
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
+	}
+
+	if !strings.Contains(w.Body.String(), "/rollno") {
+		t.Errorf("expected the error to name the field, got %s", w.Body.String())
+	}
+
+	if called {
+		t.Error("expected the handler not to run")
+	}
+
+	if got := testutil.ToFloat64(contractViolationsTotal.WithLabelValues("createStudent", "request")); got != before+1 {
+		t.Errorf("expected the violation to be counted, got %v -> %v", before, got)
+	}
+}
+
+func TestContractValidationResponses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	drifted := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"student": gin.H{"id": "1"}})
+	}
+
+	tests := []struct {
+		mode   string
+		status int
+	}{
+		{contractLog, http.StatusOK},
+		{contractStrict, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			app := newContractTestApp(t, tt.mode, &mockStudentModel{})
+
+			router := gin.New()
+			router.Use(app.validateContract())
+			router.GET("/v1/students/:id", drifted)
+
+			before := testutil.ToFloat64(contractViolationsTotal.WithLabelValues("showStudent", "response"))
+
+			w := performRequest(router, http.MethodGet, "/v1/students/1", nil)
+
+			if w.Code != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, w.Code)
+			}
+
+			if tt.mode == contractStrict && strings.Contains(w.Body.String(), "student") {
+				t.Errorf("expected the drifted body to be withheld, got %s", w.Body.String())
+			}
+
+			if got := testutil.ToFloat64(contractViolationsTotal.WithLabelValues("showStudent", "response")); got != before+1 {
+				t.Errorf("expected the violation to be counted, got %v -> %v", before, got)
+			}
+		})
+	}
+}
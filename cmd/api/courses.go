@@ -0,0 +1,181 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/validator"
+)
+
+func (app *application) createCourseHandler(c *gin.Context) {
+	var course data.Course
+
+	if err := c.ShouldBindJSON(&course); err != nil {
+		app.badRequestResponse(c, err)
+		return
+	}
+
+	v := validator.New()
+
+	if data.ValidateCourse(v, &course); !v.Valid() {
+		app.failedValidationResponse(c, v.Errors)
+		return
+	}
+
+	if err := app.models.Courses.Insert(&course); err != nil {
+		switch {
+		case errors.Is(err, data.ErrDuplicateCourse):
+			v.AddError("code", "a course with this code already exists for the term")
+			app.failedValidationResponse(c, v.Errors)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return
+	}
+
+	location := fmt.Sprintf("/v1/courses/%d", course.ID)
+
+	c.Header("Location", location)
+	c.JSON(http.StatusCreated, gin.H{
+		"course": course,
+	})
+}
+
+func (app *application) showCourseHandler(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 1 {
+		app.notFoundResponse(c)
+		return
+	}
+
+	course, err := app.readModels(c).Courses.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(c)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"course": course,
+	})
+}
+
+func (app *application) listCoursesHandler(c *gin.Context) {
+	courses, err := app.readModels(c).Courses.ListAll()
+	if err != nil {
+		app.serverErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"courses": courses,
+	})
+}
+
+func (app *application) updateCourseHandler(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 1 {
+		app.notFoundResponse(c)
+		return
+	}
+
+	course, err := app.models.Courses.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(c)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return
+	}
+
+	var input struct {
+		Code     *string `json:"code"`
+		Title    *string `json:"title"`
+		Credits  *int32  `json:"credits"`
+		Capacity *int32  `json:"capacity"`
+		Term     *string `json:"term"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		app.badRequestResponse(c, err)
+		return
+	}
+
+	if input.Code != nil {
+		course.Code = *input.Code
+	}
+
+	if input.Title != nil {
+		course.Title = *input.Title
+	}
+
+	if input.Credits != nil {
+		course.Credits = *input.Credits
+	}
+
+	if input.Capacity != nil {
+		course.Capacity = *input.Capacity
+	}
+
+	if input.Term != nil {
+		course.Term = *input.Term
+	}
+
+	v := validator.New()
+
+	if data.ValidateCourse(v, course); !v.Valid() {
+		app.failedValidationResponse(c, v.Errors)
+		return
+	}
+
+	if err := app.models.Courses.Update(course); err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(c)
+		case errors.Is(err, data.ErrDuplicateCourse):
+			v.AddError("code", "a course with this code already exists for the term")
+			app.failedValidationResponse(c, v.Errors)
+		case errors.Is(err, data.ErrCapacityBelowEnrolled):
+			v.AddError("capacity", "must not be less than the number of enrolled students")
+			app.failedValidationResponse(c, v.Errors)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"course": course})
+}
+
+func (app *application) deleteCourseHandler(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 1 {
+		app.notFoundResponse(c)
+		return
+	}
+
+	err = app.models.Courses.Delete(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(c)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "course deleted",
+	})
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+)
+
+type mockCourseModel struct {
+	insertFn func(c *data.Course) error
+	getFn    func(id int64) (*data.Course, error)
+	listFn   func() ([]*data.Course, error)
+	updateFn func(c *data.Course) error
+	deleteFn func(id int64) error
+}
+
+func (m *mockCourseModel) Insert(c *data.Course) error {
+	return m.insertFn(c)
+}
+
+func (m *mockCourseModel) Get(id int64) (*data.Course, error) {
+	return m.getFn(id)
+}
+
+func (m *mockCourseModel) ListAll() ([]*data.Course, error) {
+	return m.listFn()
+}
+
+func (m *mockCourseModel) Update(c *data.Course) error {
+	return m.updateFn(c)
+}
+
+func (m *mockCourseModel) Delete(id int64) error {
+	return m.deleteFn(id)
+}
+
+func TestCreateCourseHandler(t *testing.T) {
+	mock := &mockCourseModel{
+		insertFn: func(c *data.Course) error {
+			c.ID = 1
+			return nil
+		},
+	}
+
+	app := newTestApp(&mockStudentModel{})
+	app.models.Courses = mock
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/v1/courses", app.createCourseHandler)
+
+	body := []byte(`{"code":"CS101","title":"Intro to CS","credits":4,"capacity":30,"term":"2025-fall"}`)
+	w := performRequest(router, "POST", "/v1/courses", body)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected %d, got %d", http.StatusCreated, w.Code)
+	}
+}
+
+func TestCreateCourseHandler_Invalid(t *testing.T) {
+	app := newTestApp(&mockStudentModel{})
+	app.models.Courses = &mockCourseModel{}
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/v1/courses", app.createCourseHandler)
+
+	body := []byte(`{"code":"CS101","credits":0,"capacity":30,"term":"2025-fall"}`)
+	w := performRequest(router, "POST", "/v1/courses", body)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected %d, got %d", http.StatusUnprocessableEntity, w.Code)
+	}
+}
+
+func TestUpdateCourseHandler_EditConflict(t *testing.T) {
+	mock := &mockCourseModel{
+		getFn: func(id int64) (*data.Course, error) {
+			return &data.Course{ID: id, Code: "CS101", Title: "Intro", Credits: 4, Capacity: 30, Term: "2025-fall", Version: 1}, nil
+		},
+		updateFn: func(c *data.Course) error { return data.ErrEditConflict },
+	}
+
+	app := newTestApp(&mockStudentModel{})
+	app.models.Courses = mock
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.PATCH("/v1/courses/:id", app.updateCourseHandler)
+
+	w := performRequest(router, "PATCH", "/v1/courses/1", []byte(`{"capacity":40}`))
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected %d, got %d", http.StatusConflict, w.Code)
+	}
+}
+
+func TestUpdateCourseHandler_CapacityBelowEnrolled(t *testing.T) {
+	mock := &mockCourseModel{
+		getFn: func(id int64) (*data.Course, error) {
+			return &data.Course{ID: id, Code: "CS101", Title: "Intro", Credits: 4, Capacity: 30, Term: "2025-fall", Version: 1}, nil
+		},
+		updateFn: func(c *data.Course) error { return data.ErrCapacityBelowEnrolled },
+	}
+
+	app := newTestApp(&mockStudentModel{})
+	app.models.Courses = mock
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.PATCH("/v1/courses/:id", app.updateCourseHandler)
+
+	w := performRequest(router, "PATCH", "/v1/courses/1", []byte(`{"capacity":1}`))
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected %d, got %d", http.StatusUnprocessableEntity, w.Code)
+	}
+}
+
+func TestShowCourseHandler_NotFound(t *testing.T) {
+	mock := &mockCourseModel{
+		getFn: func(id int64) (*data.Course, error) { return nil, data.ErrRecordNotFound },
+	}
+
+	app := newTestApp(&mockStudentModel{})
+	app.models.Courses = mock
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/v1/courses/:id", app.showCourseHandler)
+
+	w := performRequest(router, "GET", "/v1/courses/7", nil)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
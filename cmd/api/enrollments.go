@@ -0,0 +1,157 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/validator"
+)
+
+func (app *application) createEnrollmentHandler(c *gin.Context) {
+	courseID, err := app.readIDParam(c, "id")
+	if err != nil {
+		app.notFoundResponse(c)
+		return
+	}
+
+	var input struct {
+		StudentID int64 `json:"student_id"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		app.badRequestResponse(c, err)
+		return
+	}
+
+	v := validator.New()
+
+	if v.Check(input.StudentID > 0, "student_id", "must be provided"); !v.Valid() {
+		app.failedValidationResponse(c, v.Errors)
+		return
+	}
+
+	if _, err := app.models.Students.Get(input.StudentID); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			v.AddError("student_id", "student does not exist")
+			app.failedValidationResponse(c, v.Errors)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return
+	}
+
+	enrollment, err := app.models.Enrollments.Enroll(courseID, input.StudentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(c)
+		case errors.Is(err, data.ErrAlreadyEnrolled):
+			v.AddError("student_id", "student is already enrolled or waitlisted for this course")
+			app.failedValidationResponse(c, v.Errors)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"enrollment": enrollment,
+	})
+}
+
+func (app *application) listCourseEnrollmentsHandler(c *gin.Context) {
+	courseID, err := app.readIDParam(c, "id")
+	if err != nil {
+		app.notFoundResponse(c)
+		return
+	}
+
+	if _, err := app.models.Courses.Get(courseID); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(c)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return
+	}
+
+	enrollments, err := app.models.Enrollments.ListForCourse(courseID)
+	if err != nil {
+		app.serverErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"enrollments": enrollments,
+	})
+}
+
+func (app *application) deleteEnrollmentHandler(c *gin.Context) {
+	courseID, err := app.readIDParam(c, "id")
+	if err != nil {
+		app.notFoundResponse(c)
+		return
+	}
+
+	studentID, err := app.readIDParam(c, "student_id")
+	if err != nil {
+		app.notFoundResponse(c)
+		return
+	}
+
+	promoted, err := app.models.Enrollments.Drop(courseID, studentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(c)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return
+	}
+
+	for _, e := range promoted {
+		app.logger.PrintInfo("waitlisted student promoted", map[string]string{
+			"course_id":  strconv.FormatInt(e.CourseID, 10),
+			"student_id": strconv.FormatInt(e.StudentID, 10),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "enrollment dropped",
+		"promoted": promoted,
+	})
+}
+
+func (app *application) listStudentCoursesHandler(c *gin.Context) {
+	studentID, err := app.readIDParam(c, "id")
+	if err != nil {
+		app.notFoundResponse(c)
+		return
+	}
+
+	if _, err := app.models.Students.Get(studentID); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(c)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return
+	}
+
+	courses, err := app.models.Enrollments.ListForStudent(studentID)
+	if err != nil {
+		app.serverErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"courses": courses,
+	})
+}
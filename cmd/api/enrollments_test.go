@@ -0,0 +1,99 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+)
+
+type mockEnrollmentModel struct {
+	enrollFn         func(courseID, studentID int64) (*data.Enrollment, error)
+	dropFn           func(courseID, studentID int64) ([]*data.Enrollment, error)
+	listForCourseFn  func(courseID int64) ([]*data.Enrollment, error)
+	listForStudentFn func(studentID int64) ([]*data.StudentCourse, error)
+}
+
+func (m *mockEnrollmentModel) Enroll(courseID, studentID int64) (*data.Enrollment, error) {
+	return m.enrollFn(courseID, studentID)
+}
+
+func (m *mockEnrollmentModel) Drop(courseID, studentID int64) ([]*data.Enrollment, error) {
+	return m.dropFn(courseID, studentID)
+}
+
+func (m *mockEnrollmentModel) ListForCourse(courseID int64) ([]*data.Enrollment, error) {
+	return m.listForCourseFn(courseID)
+}
+
+func (m *mockEnrollmentModel) ListForStudent(studentID int64) ([]*data.StudentCourse, error) {
+	return m.listForStudentFn(studentID)
+}
+
+func existingStudent(id int64) (*data.Student, error) {
+	return &data.Student{ID: id, Name: "Bob", RollNo: 22}, nil
+}
+
+func TestCreateEnrollmentHandler(t *testing.T) {
+	mock := &mockEnrollmentModel{
+		enrollFn: func(courseID, studentID int64) (*data.Enrollment, error) {
+			return &data.Enrollment{ID: 1, CourseID: courseID, StudentID: studentID, Status: data.EnrollmentWaitlisted, WaitlistPosition: 1}, nil
+		},
+	}
+
+	app := newTestApp(&mockStudentModel{getFn: existingStudent})
+	app.models.Enrollments = mock
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/v1/courses/:id/enrollments", app.createEnrollmentHandler)
+
+	w := performRequest(router, "POST", "/v1/courses/3/enrollments", []byte(`{"student_id":5}`))
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected %d, got %d", http.StatusCreated, w.Code)
+	}
+}
+
+func TestCreateEnrollmentHandler_AlreadyEnrolled(t *testing.T) {
+	mock := &mockEnrollmentModel{
+		enrollFn: func(courseID, studentID int64) (*data.Enrollment, error) {
+			return nil, data.ErrAlreadyEnrolled
+		},
+	}
+
+	app := newTestApp(&mockStudentModel{getFn: existingStudent})
+	app.models.Enrollments = mock
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/v1/courses/:id/enrollments", app.createEnrollmentHandler)
+
+	w := performRequest(router, "POST", "/v1/courses/3/enrollments", []byte(`{"student_id":5}`))
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected %d, got %d", http.StatusUnprocessableEntity, w.Code)
+	}
+}
+
+func TestDeleteEnrollmentHandler_NotFound(t *testing.T) {
+	mock := &mockEnrollmentModel{
+		dropFn: func(courseID, studentID int64) ([]*data.Enrollment, error) {
+			return nil, data.ErrRecordNotFound
+		},
+	}
+
+	app := newTestApp(&mockStudentModel{})
+	app.models.Enrollments = mock
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.DELETE("/v1/courses/:id/enrollments/:student_id", app.deleteEnrollmentHandler)
+
+	w := performRequest(router, "DELETE", "/v1/courses/3/enrollments/5", nil)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
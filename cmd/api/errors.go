@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
 )
 
 // logError logs an error with request context information.
@@ -45,17 +46,22 @@ func (app *application) badRequestResponse(c *gin.Context, err error) {
 	app.errorResponse(c, http.StatusBadRequest, err.Error())
 }
 
-//nolint:unused
 func (app *application) failedValidationResponse(c *gin.Context, errors map[string]string) {
 	app.errorResponse(c, http.StatusUnprocessableEntity, errors)
 }
 
+func (app *application) invalidTransitionResponse(c *gin.Context, from, to string) {
+	app.errorResponse(c, http.StatusUnprocessableEntity, gin.H{
+		"to":                  fmt.Sprintf("a student can't move from %s to %s", from, to),
+		"allowed_transitions": data.AllowedTransitions(from),
+	})
+}
+
 func (app *application) editConflictResponse(c *gin.Context) {
 	message := "unable to update the record due to an edit conflict, please try again"
 	app.errorResponse(c, http.StatusConflict, message)
 }
 
-//nolint:unused
 func (app *application) rateLimitExceededResponse(c *gin.Context) {
 	message := "rate limit exceeded"
 	app.errorResponse(c, http.StatusTooManyRequests, message)
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+)
+
+// studentEventsHandler streams student changes as Server-Sent Events. Each event's id is
+// the outbox event id and its name is the event type. A client that reconnects with
+// Last-Event-ID (or ?last_event_id=) is sent the buffered events it missed; if the buffer
+// no longer reaches back that far it is sent a "reset" event first and should refetch.
+func (app *application) studentEventsHandler(c *gin.Context) {
+	lastID := c.GetHeader("Last-Event-ID")
+	if lastID == "" {
+		lastID = c.Query("last_event_id")
+	}
+
+	var after int64
+
+	if lastID != "" {
+		id, err := strconv.ParseInt(lastID, 10, 64)
+		if err != nil || id < 0 {
+			app.badRequestResponse(c, errors.New("Last-Event-ID must be a non-negative integer"))
+			return
+		}
+		after = id
+	}
+
+	// The server's write timeout would otherwise cut every stream off after 30 seconds.
+	err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		app.serverErrorResponse(c, err)
+		return
+	}
+
+	sub, replay, complete := app.streams.Subscribe(after)
+	defer app.streams.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if !complete {
+		if err := sse.Encode(c.Writer, sse.Event{Event: "reset", Data: gin.H{"last_event_id": after}}); err != nil {
+			return
+		}
+	}
+
+	for _, event := range replay {
+		if err := writeStudentEvent(c, event); err != nil {
+			return
+		}
+	}
+
+	c.Writer.Flush()
+
+	// loadConfig rejects a heartbeat that isn't positive, but an application built by
+	// hand may not have one, and NewTicker panics on zero.
+	interval := app.config.Stream.Heartbeat
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+
+	heartbeat := time.NewTicker(interval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+
+		case event, ok := <-sub.C:
+			// The hub closes the channel when the client falls behind or the server is
+			// shutting down; either way the client reconnects with Last-Event-ID.
+			if !ok {
+				return
+			}
+
+			if err := writeStudentEvent(c, event); err != nil {
+				return
+			}
+			c.Writer.Flush()
+
+		case <-heartbeat.C:
+			if _, err := c.Writer.WriteString(": heartbeat\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+func writeStudentEvent(c *gin.Context, event *data.OutboxEvent) error {
+	return sse.Encode(c.Writer, sse.Event{
+		Id:    strconv.FormatInt(event.ID, 10),
+		Event: event.Type,
+		Data:  event,
+	})
+}
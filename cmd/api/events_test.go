@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/stream"
+)
+
+func TestStudentEventsHandler(t *testing.T) {
+	app := newTestApp(&mockStudentModel{})
+	app.streams = stream.NewHub(10)
+	app.config.Stream.Heartbeat = 20 * time.Millisecond
+	gin.SetMode(gin.TestMode)
+
+	for _, id := range []int64{1, 2, 3} {
+		_ = app.streams.Send(context.Background(), &data.OutboxEvent{ID: id, Type: data.EventStudentUpdated, AggregateID: 7})
+	}
+
+	router := gin.New()
+	router.GET("/v1/students/events", app.studentEventsHandler)
+
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/v1/students/events", nil)
+	req.Header.Set("Last-Event-ID", "1")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %q", ct)
+	}
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(res.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	next := func(prefix string) string {
+		t.Helper()
+		timeout := time.After(2 * time.Second)
+		for {
+			select {
+			case line, ok := <-lines:
+				if !ok {
+					t.Fatalf("stream ended while waiting for %q", prefix)
+				}
+				if strings.HasPrefix(line, prefix) {
+					return line
+				}
+			case <-timeout:
+				t.Fatalf("timed out waiting for %q", prefix)
+			}
+		}
+	}
+
+	if line := next("id:"); line != "id:2" {
+		t.Fatalf("expected replay to start at event 2, got %q", line)
+	}
+	if line := next("event:"); line != "event:student.updated" {
+		t.Fatalf("unexpected event line %q", line)
+	}
+	if line := next("id:"); line != "id:3" {
+		t.Fatalf("expected event 3, got %q", line)
+	}
+
+	_ = app.streams.Send(context.Background(), &data.OutboxEvent{ID: 4, Type: data.EventStudentDeleted, AggregateID: 7})
+
+	if line := next("id:"); line != "id:4" {
+		t.Fatalf("expected the live event 4, got %q", line)
+	}
+
+	next(": heartbeat")
+
+	// Shutdown closes the hub, which must end the stream.
+	app.streams.Close()
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case _, ok := <-lines:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("stream did not end after the hub was closed")
+		}
+	}
+}
+
+func TestStudentEventsHandler_Reset(t *testing.T) {
+	app := newTestApp(&mockStudentModel{})
+	app.streams = stream.NewHub(2)
+	app.config.Stream.Heartbeat = time.Minute
+	gin.SetMode(gin.TestMode)
+
+	// Events 1 and 2 have been pushed out of the buffer.
+	for id := int64(1); id <= 4; id++ {
+		_ = app.streams.Send(context.Background(), &data.OutboxEvent{ID: id, Type: data.EventStudentUpdated})
+	}
+
+	router := gin.New()
+	router.GET("/v1/students/events", app.studentEventsHandler)
+
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL + "/v1/students/events?last_event_id=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	line, err := bufio.NewReader(res.Body).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if line != "event:reset\n" {
+		t.Fatalf("expected a reset event, got %q", line)
+	}
+
+	app.streams.Close()
+}
+
+func TestStudentEventsHandler_InvalidLastEventID(t *testing.T) {
+	app := newTestApp(&mockStudentModel{})
+	app.streams = stream.NewHub(10)
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/v1/students/events", app.studentEventsHandler)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/students/events", nil)
+	req.Header.Set("Last-Event-ID", "abc")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
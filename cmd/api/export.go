@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/xlsx"
+)
+
+// studentColumns is the column order used by both export and import.
+var studentColumns = []string{"id", "name", "rollno", "email", "phone", "date_of_birth", "address", "status", "version"}
+
+func studentRow(s *data.Student) []any {
+	dob := ""
+	if s.DateOfBirth != nil {
+		dob = s.DateOfBirth.String()
+	}
+
+	return []any{s.ID, s.Name, s.RollNo, s.Email, s.Phone, dob, s.Address, s.Status, s.Version}
+}
+
+// formulaPrefixes are the leading characters that make a spreadsheet evaluate a cell
+// as a formula.
+const formulaPrefixes = "=+-@\t\r"
+
+// spreadsheetRow is studentRow for the formats that are opened in spreadsheets. Text
+// cells that would be evaluated as formulas are prefixed with a single quote so that
+// they are shown as typed; parseStudentCSV strips the quote again on import.
+func spreadsheetRow(s *data.Student) []any {
+	row := studentRow(s)
+	for i, v := range row {
+		if text, ok := v.(string); ok && text != "" && strings.ContainsRune(formulaPrefixes, rune(text[0])) {
+			row[i] = "'" + text
+		}
+	}
+	return row
+}
+
+// rowEncoder writes one exported student at a time in a particular format.
+type rowEncoder interface {
+	Encode(*data.Student) error
+	Close() error
+}
+
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func newCSVEncoder(w io.Writer) (rowEncoder, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(studentColumns); err != nil {
+		return nil, err
+	}
+	return &csvEncoder{w: cw}, nil
+}
+
+func (e *csvEncoder) Encode(s *data.Student) error {
+	row := spreadsheetRow(s)
+	record := make([]string, len(row))
+	for i, v := range row {
+		record[i] = fmt.Sprint(v)
+	}
+	return e.w.Write(record)
+}
+
+func (e *csvEncoder) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type ndjsonEncoder struct {
+	enc *json.Encoder
+}
+
+func newNDJSONEncoder(w io.Writer) (rowEncoder, error) {
+	return &ndjsonEncoder{enc: json.NewEncoder(w)}, nil
+}
+
+func (e *ndjsonEncoder) Encode(s *data.Student) error {
+	return e.enc.Encode(s)
+}
+
+func (e *ndjsonEncoder) Close() error {
+	return nil
+}
+
+type xlsxEncoder struct {
+	w *xlsx.Writer
+}
+
+func newXLSXEncoder(w io.Writer) (rowEncoder, error) {
+	xw, err := xlsx.NewWriter(w, "students")
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]any, len(studentColumns))
+	for i, col := range studentColumns {
+		header[i] = col
+	}
+
+	if err := xw.WriteRow(header...); err != nil {
+		return nil, err
+	}
+
+	return &xlsxEncoder{w: xw}, nil
+}
+
+func (e *xlsxEncoder) Encode(s *data.Student) error {
+	return e.w.WriteRow(spreadsheetRow(s)...)
+}
+
+func (e *xlsxEncoder) Close() error {
+	return e.w.Close()
+}
+
+var exportFormats = map[string]struct {
+	contentType string
+	extension   string
+	newEncoder  func(io.Writer) (rowEncoder, error)
+}{
+	"csv":    {"text/csv; charset=utf-8", "csv", newCSVEncoder},
+	"ndjson": {"application/x-ndjson", "ndjson", newNDJSONEncoder},
+	"xlsx":   {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "xlsx", newXLSXEncoder},
+}
+
+// exportStudentsHandler streams the whole roster in the requested format. Rows are
+// read from a database cursor and written as they arrive, so the response is never
+// buffered in memory. Headers are only sent once the first row (or the end of an empty
+// result) is reached, which lets query errors still produce a normal JSON error.
+func (app *application) exportStudentsHandler(c *gin.Context) {
+	formatName := c.DefaultQuery("format", "csv")
+
+	format, ok := exportFormats[formatName]
+	if !ok {
+		app.badRequestResponse(c, fmt.Errorf("unsupported export format %q (must be csv, ndjson or xlsx)", formatName))
+		return
+	}
+
+	var enc rowEncoder
+	rows := 0
+
+	start := func() error {
+		c.Header("Content-Type", format.contentType)
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="students.%s"`, format.extension))
+		c.Status(http.StatusOK)
+
+		var err error
+		enc, err = format.newEncoder(c.Writer)
+		return err
+	}
+
+	err := app.readModels(c).Students.ForEach(c.Request.Context(), func(s *data.Student) error {
+		if enc == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+
+		rows++
+		if rows%500 == 0 {
+			c.Writer.Flush()
+		}
+
+		return enc.Encode(s)
+	})
+	if err != nil {
+		if enc == nil {
+			app.serverErrorResponse(c, err)
+			return
+		}
+
+		// The status line has already gone out, so the best we can do is log the
+		// failure and cut the stream short.
+		app.logError(c, err)
+		c.Abort()
+		return
+	}
+
+	if enc == nil {
+		if err := start(); err != nil {
+			app.serverErrorResponse(c, err)
+			return
+		}
+	}
+
+	if err := enc.Close(); err != nil {
+		app.logError(c, err)
+	}
+}
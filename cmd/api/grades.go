@@ -0,0 +1,129 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/gpa"
+	"github.com/sai29/one2n_sre_bootcamp/internal/validator"
+)
+
+func (app *application) setGradeHandler(c *gin.Context) {
+	enrollmentID, err := app.readIDParam(c, "id")
+	if err != nil {
+		app.notFoundResponse(c)
+		return
+	}
+
+	var input struct {
+		Grade string `json:"grade"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		app.badRequestResponse(c, err)
+		return
+	}
+
+	input.Grade = strings.ToUpper(strings.TrimSpace(input.Grade))
+
+	scale := app.config.Grading.Scale
+
+	v := validator.New()
+	v.Check(input.Grade != "", "grade", "must be provided")
+	v.Check(input.Grade == "" || scale.Valid(input.Grade), "grade", "must be one of "+strings.Join(scale.Grades(), ", "))
+
+	if !v.Valid() {
+		app.failedValidationResponse(c, v.Errors)
+		return
+	}
+
+	grade, created, err := app.models.Grades.Set(enrollmentID, input.Grade)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(c)
+		case errors.Is(err, data.ErrNotEnrolled):
+			v.AddError("enrollment", "waitlisted enrollments can't be graded")
+			app.failedValidationResponse(c, v.Errors)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+
+	c.JSON(status, gin.H{"grade": grade})
+}
+
+type transcriptTerm struct {
+	gpa.TermSummary
+	Courses []*data.TranscriptEntry `json:"courses"`
+}
+
+func (app *application) showTranscriptHandler(c *gin.Context) {
+	studentID, err := app.readIDParam(c, "id")
+	if err != nil {
+		app.notFoundResponse(c)
+		return
+	}
+
+	student, err := app.models.Students.Get(studentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(c)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return
+	}
+
+	entries, err := app.models.Grades.TranscriptFor(studentID)
+	if err != nil {
+		app.serverErrorResponse(c, err)
+		return
+	}
+
+	gpaEntries := make([]gpa.Entry, len(entries))
+	for i, e := range entries {
+		gpaEntries[i] = gpa.Entry{Term: e.Term, Credits: e.Credits, Grade: e.Grade}
+	}
+
+	// Grades are validated against the scale when they are set, but the scale can be
+	// reconfigured later, leaving grades the current scale doesn't know about.
+	result, err := app.config.Grading.Scale.Compute(gpaEntries)
+	if err != nil {
+		app.serverErrorResponse(c, err)
+		return
+	}
+
+	terms := make([]transcriptTerm, len(result.Terms))
+	index := make(map[string]int, len(result.Terms))
+
+	for i, t := range result.Terms {
+		terms[i] = transcriptTerm{TermSummary: t, Courses: []*data.TranscriptEntry{}}
+		index[t.Term] = i
+	}
+
+	for _, e := range entries {
+		i := index[e.Term]
+		terms[i].Courses = append(terms[i].Courses, e)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"transcript": gin.H{
+			"student":           student,
+			"terms":             terms,
+			"credits_attempted": result.CreditsAttempted,
+			"credits_earned":    result.CreditsEarned,
+			"cumulative_gpa":    result.CumulativeGPA,
+		},
+	})
+}
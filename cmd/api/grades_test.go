@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+)
+
+type mockGradeModel struct {
+	setFn        func(enrollmentID int64, grade string) (*data.Grade, bool, error)
+	transcriptFn func(studentID int64) ([]*data.TranscriptEntry, error)
+}
+
+func (m *mockGradeModel) Set(enrollmentID int64, grade string) (*data.Grade, bool, error) {
+	return m.setFn(enrollmentID, grade)
+}
+
+func (m *mockGradeModel) TranscriptFor(studentID int64) ([]*data.TranscriptEntry, error) {
+	return m.transcriptFn(studentID)
+}
+
+func TestSetGradeHandler(t *testing.T) {
+	mock := &mockGradeModel{
+		setFn: func(enrollmentID int64, grade string) (*data.Grade, bool, error) {
+			if grade != "A-" {
+				t.Fatalf("expected normalised grade A-, got %q", grade)
+			}
+			return &data.Grade{ID: 1, EnrollmentID: enrollmentID, Grade: grade, Version: 1}, true, nil
+		},
+	}
+
+	app := newTestApp(&mockStudentModel{})
+	app.models.Grades = mock
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.PUT("/v1/enrollments/:id/grade", app.setGradeHandler)
+
+	w := performRequest(router, "PUT", "/v1/enrollments/4/grade", []byte(`{"grade":" a- "}`))
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected %d, got %d", http.StatusCreated, w.Code)
+	}
+}
+
+func TestSetGradeHandler_NotOnScale(t *testing.T) {
+	app := newTestApp(&mockStudentModel{})
+	app.models.Grades = &mockGradeModel{}
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.PUT("/v1/enrollments/:id/grade", app.setGradeHandler)
+
+	w := performRequest(router, "PUT", "/v1/enrollments/4/grade", []byte(`{"grade":"E"}`))
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected %d, got %d", http.StatusUnprocessableEntity, w.Code)
+	}
+}
+
+func TestShowTranscriptHandler(t *testing.T) {
+	mock := &mockGradeModel{
+		transcriptFn: func(studentID int64) ([]*data.TranscriptEntry, error) {
+			return []*data.TranscriptEntry{
+				{EnrollmentID: 1, CourseID: 1, Code: "CS101", Credits: 3, Term: "2024-fall", Grade: "A"},
+				{EnrollmentID: 2, CourseID: 2, Code: "MA101", Credits: 3, Term: "2024-fall", Grade: "B"},
+				{EnrollmentID: 3, CourseID: 3, Code: "CS201", Credits: 4, Term: "2025-spring"},
+			}, nil
+		},
+	}
+
+	app := newTestApp(&mockStudentModel{getFn: existingStudent})
+	app.models.Grades = mock
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/v1/students/:id/transcript", app.showTranscriptHandler)
+
+	w := performRequest(router, "GET", "/v1/students/1/transcript", nil)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var resp struct {
+		Transcript struct {
+			Terms []struct {
+				Term    string  `json:"term"`
+				GPA     float64 `json:"gpa"`
+				Courses []any   `json:"courses"`
+			} `json:"terms"`
+			CumulativeGPA float64 `json:"cumulative_gpa"`
+		} `json:"transcript"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	terms := resp.Transcript.Terms
+	if len(terms) != 2 || len(terms[0].Courses) != 2 || len(terms[1].Courses) != 1 {
+		t.Fatalf("unexpected terms: %+v", terms)
+	}
+
+	if terms[0].GPA != 3.5 || resp.Transcript.CumulativeGPA != 3.5 {
+		t.Fatalf("unexpected GPA: term %v, cumulative %v", terms[0].GPA, resp.Transcript.CumulativeGPA)
+	}
+}
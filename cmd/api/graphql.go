@@ -0,0 +1,28 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sai29/one2n_sre_bootcamp/internal/graph"
+)
+
+// graphqlHandler executes a GraphQL request. As is usual for GraphQL, errors raised while
+// resolving are reported in the "errors" array of a 200 response; only a body that is not
+// a GraphQL request gets a 400.
+func (app *application) graphqlHandler(c *gin.Context) {
+	var req graph.Request
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		app.badRequestResponse(c, err)
+		return
+	}
+
+	res, mutation := app.graph.Execute(c.Request.Context(), app.models, app.readModels(c), req)
+
+	if !mutation || len(res.Errors) > 0 {
+		skipReadPin(c)
+	}
+
+	c.JSON(http.StatusOK, res)
+}
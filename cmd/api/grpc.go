@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/grpcapi"
+	studentsv1 "github.com/sai29/one2n_sre_bootcamp/pkg/pb/students/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// grpcServer is the gRPC listener that runs next to the HTTP server.
+type grpcServer struct {
+	srv    *grpc.Server
+	health *health.Server
+	lis    net.Listener
+}
+
+// newGRPCServer listens on the gRPC port and registers the student service, health checking
+// and reflection. It listens before serve starts so that a port in use is reported at
+// startup rather than from a background goroutine.
+func (app *application) newGRPCServer() (*grpcServer, error) {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", app.config.GRPC.Port))
+	if err != nil {
+		return nil, err
+	}
+
+	unary, stream := grpcInterceptors(
+		app.grpcMetrics,
+		app.grpcLogger,
+		app.grpcRecoverPanic,
+		app.grpcAuthenticate,
+	)
+
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unary),
+		grpc.ChainStreamInterceptor(stream),
+	)
+
+	studentsv1.RegisterStudentServiceServer(srv, &grpcapi.StudentService{
+		Models:     app.models,
+		ReadModels: app.replicaOrPrimary,
+		Streams:    app.streams,
+		Logger:     app.logger,
+	})
+
+	hs := health.NewServer()
+	healthpb.RegisterHealthServer(srv, hs)
+
+	reflection.Register(srv)
+
+	return &grpcServer{srv: srv, health: hs, lis: lis}, nil
+}
+
+// grpcHealth reports NOT_SERVING while the schema check fails, like /v1/readiness. It keeps
+// re-checking after the check passes, so a schema that falls behind later is reported too.
+func (app *application) grpcHealth(hs *health.Server) {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		app.checkGRPCHealth(hs)
+
+		select {
+		case <-app.shutdown:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkGRPCHealth runs the schema check once and sets the serving status from it.
+func (app *application) checkGRPCHealth(hs *health.Server) {
+	serving := healthpb.HealthCheckResponse_SERVING
+
+	if app.schema != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), app.config.DB.QueryTimeout)
+		ok := app.schema.current(ctx).ok()
+		cancel()
+
+		if !ok {
+			serving = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+	}
+
+	hs.SetServingStatus("", serving)
+	hs.SetServingStatus(studentsv1.StudentService_ServiceDesc.ServiceName, serving)
+}
+
+// stop marks the server NOT_SERVING and waits for in-flight calls until ctx is done, after
+// which the remaining calls are cancelled.
+func (g *grpcServer) stop(ctx context.Context) {
+	g.health.Shutdown()
+
+	done := make(chan struct{})
+	go func() {
+		g.srv.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		g.srv.Stop()
+		<-done
+	}
+}
+
+// === Interceptors ===
+
+// grpcMiddleware wraps a unary call or a whole stream. The interceptors below are written
+// once as middleware and adapted to both kinds of call by grpcInterceptors.
+type grpcMiddleware func(ctx context.Context, method string, next func() error) error
+
+func grpcInterceptors(mws ...grpcMiddleware) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	wrap := func(ctx context.Context, method string, call func() error) error {
+		next := call
+		for i := len(mws) - 1; i >= 0; i-- {
+			mw, inner := mws[i], next
+			next = func() error { return mw(ctx, method, inner) }
+		}
+		return next()
+	}
+
+	unary := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var resp any
+		err := wrap(ctx, info.FullMethod, func() error {
+			var err error
+			resp, err = handler(ctx, req)
+			return err
+		})
+		return resp, err
+	}
+
+	stream := func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return wrap(ss.Context(), info.FullMethod, func() error {
+			return handler(srv, ss)
+		})
+	}
+
+	return unary, stream
+}
+
+func (app *application) grpcMetrics(_ context.Context, method string, next func() error) error {
+	start := time.Now()
+
+	err := next()
+
+	grpcRequestDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	grpcRequestsTotal.WithLabelValues(method, status.Code(err).String()).Inc()
+
+	return err
+}
+
+func (app *application) grpcLogger(_ context.Context, method string, next func() error) error {
+	start := time.Now()
+
+	err := next()
+
+	app.logger.PrintInfo("grpc request", map[string]string{
+		"method":  method,
+		"code":    status.Code(err).String(),
+		"latency": time.Since(start).String(),
+	})
+
+	return err
+}
+
+func (app *application) grpcRecoverPanic(_ context.Context, method string, next func() error) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			app.logger.PrintError(fmt.Errorf("%v", rec), map[string]string{"trace": "panic recovered", "method": method})
+			err = status.Error(codes.Internal, "the server encountered a problem and could not process your request")
+		}
+	}()
+
+	return next()
+}
+
+// grpcAuthenticate requires an "authorization: Bearer <token>" header matching one of the
+// -grpc-auth-tokens. Health checks and reflection are open so that probes and tools like
+// grpcurl work without a token. With no tokens configured, every call is allowed only if
+// -grpc-allow-unauthenticated is set; otherwise every call is refused.
+func (app *application) grpcAuthenticate(ctx context.Context, method string, next func() error) error {
+	tokens := app.config.GRPC.AuthTokens
+
+	if len(tokens) == 0 && app.config.GRPC.AllowUnauthenticated ||
+		strings.HasPrefix(method, "/grpc.health.v1.Health/") ||
+		strings.HasPrefix(method, "/grpc.reflection.") {
+		return next()
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		scheme, token, ok := strings.Cut(value, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			continue
+		}
+
+		for _, want := range tokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1 {
+				return next()
+			}
+		}
+	}
+
+	return status.Error(codes.Unauthenticated, "invalid or missing authentication token")
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	studentsv1 "github.com/sai29/one2n_sre_bootcamp/pkg/pb/students/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func startTestGRPC(t *testing.T, app *application) *grpc.ClientConn {
+	t.Helper()
+
+	app.shutdown = make(chan struct{})
+
+	g, err := app.newGRPCServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() { _ = g.srv.Serve(g.lis) }()
+	app.checkGRPCHealth(g.health)
+
+	conn, err := grpc.NewClient(g.lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		g.stop(ctx)
+	})
+
+	return conn
+}
+
+func TestGRPCAuthentication(t *testing.T) {
+	app := newTestApp(&mockStudentModel{
+		getFn: func(id int64) (*data.Student, error) {
+			return &data.Student{ID: id, Name: "Ada"}, nil
+		},
+	})
+	app.config.GRPC.AuthTokens = []string{"secret"}
+
+	conn := startTestGRPC(t, app)
+	client := studentsv1.NewStudentServiceClient(conn)
+
+	tests := []struct {
+		name string
+		auth string
+		code codes.Code
+	}{
+		{"no token", "", codes.Unauthenticated},
+		{"wrong token", "Bearer nope", codes.Unauthenticated},
+		{"wrong scheme", "Basic secret", codes.Unauthenticated},
+		{"valid token", "Bearer secret", codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.auth != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", tt.auth)
+			}
+
+			_, err := client.GetStudent(ctx, &studentsv1.GetStudentRequest{Id: 1})
+			if got := status.Code(err); got != tt.code {
+				t.Fatalf("expected %s, got %v", tt.code, err)
+			}
+		})
+	}
+
+	// Health checks don't need a token.
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{
+		Service: studentsv1.StudentService_ServiceDesc.ServiceName,
+	})
+	if err != nil || resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("expected SERVING, got %v, %v", resp, err)
+	}
+}
+
+func TestGRPCWithoutTokensFailsClosed(t *testing.T) {
+	app := newTestApp(&mockStudentModel{
+		getFn: func(id int64) (*data.Student, error) {
+			return &data.Student{ID: id, Name: "Ada"}, nil
+		},
+	})
+
+	client := studentsv1.NewStudentServiceClient(startTestGRPC(t, app))
+
+	_, err := client.GetStudent(context.Background(), &studentsv1.GetStudentRequest{Id: 1})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated, got %v", err)
+	}
+
+	app.config.GRPC.AllowUnauthenticated = true
+
+	if _, err := client.GetStudent(context.Background(), &studentsv1.GetStudentRequest{Id: 1}); err != nil {
+		t.Fatalf("expected the call to be allowed, got %v", err)
+	}
+}
+
+func TestGRPCRecoversFromPanics(t *testing.T) {
+	app := newTestApp(&mockStudentModel{
+		getFn: func(int64) (*data.Student, error) {
+			panic("boom")
+		},
+	})
+	app.config.GRPC.AllowUnauthenticated = true
+
+	conn := startTestGRPC(t, app)
+
+	_, err := studentsv1.NewStudentServiceClient(conn).GetStudent(context.Background(), &studentsv1.GetStudentRequest{Id: 1})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("expected Internal, got %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/validator"
+)
+
+func (app *application) createGuardianHandler(c *gin.Context) {
+	studentID, err := app.readIDParam(c, "id")
+	if err != nil {
+		app.notFoundResponse(c)
+		return
+	}
+
+	var input struct {
+		Name         string `json:"name"`
+		Relationship string `json:"relationship"`
+		Email        string `json:"email"`
+		Phone        string `json:"phone"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		app.badRequestResponse(c, err)
+		return
+	}
+
+	guardian := &data.Guardian{
+		StudentID:    studentID,
+		Name:         input.Name,
+		Relationship: input.Relationship,
+		Email:        input.Email,
+		Phone:        input.Phone,
+	}
+
+	v := validator.New()
+
+	if data.ValidateGuardian(v, guardian); !v.Valid() {
+		app.failedValidationResponse(c, v.Errors)
+		return
+	}
+
+	if err := app.models.Guardians.Insert(guardian); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(c)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return
+	}
+
+	location := fmt.Sprintf("/v1/students/%d/guardians/%d", studentID, guardian.ID)
+
+	c.Header("Location", location)
+	c.JSON(http.StatusCreated, gin.H{
+		"guardian": guardian,
+	})
+}
+
+func (app *application) listGuardiansHandler(c *gin.Context) {
+	studentID, err := app.readIDParam(c, "id")
+	if err != nil {
+		app.notFoundResponse(c)
+		return
+	}
+
+	if _, err := app.models.Students.Get(studentID); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(c)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return
+	}
+
+	guardians, err := app.models.Guardians.ListForStudent(studentID)
+	if err != nil {
+		app.serverErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"guardians": guardians,
+	})
+}
+
+func (app *application) showGuardianHandler(c *gin.Context) {
+	guardian, ok := app.loadGuardian(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"guardian": guardian,
+	})
+}
+
+func (app *application) updateGuardianHandler(c *gin.Context) {
+	guardian, ok := app.loadGuardian(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Name         *string `json:"name"`
+		Relationship *string `json:"relationship"`
+		Email        *string `json:"email"`
+		Phone        *string `json:"phone"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		app.badRequestResponse(c, err)
+		return
+	}
+
+	if input.Name != nil {
+		guardian.Name = *input.Name
+	}
+
+	if input.Relationship != nil {
+		guardian.Relationship = *input.Relationship
+	}
+
+	if input.Email != nil {
+		guardian.Email = *input.Email
+	}
+
+	if input.Phone != nil {
+		guardian.Phone = *input.Phone
+	}
+
+	v := validator.New()
+
+	if data.ValidateGuardian(v, guardian); !v.Valid() {
+		app.failedValidationResponse(c, v.Errors)
+		return
+	}
+
+	if err := app.models.Guardians.Update(guardian); err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(c)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"guardian": guardian})
+}
+
+func (app *application) deleteGuardianHandler(c *gin.Context) {
+	studentID, err := app.readIDParam(c, "id")
+	if err != nil {
+		app.notFoundResponse(c)
+		return
+	}
+
+	guardianID, err := app.readIDParam(c, "guardian_id")
+	if err != nil {
+		app.notFoundResponse(c)
+		return
+	}
+
+	err = app.models.Guardians.Delete(studentID, guardianID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(c)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "guardian deleted",
+	})
+}
+
+// loadGuardian fetches the guardian named by the route, writing a response and
+// returning false if it can't.
+func (app *application) loadGuardian(c *gin.Context) (*data.Guardian, bool) {
+	studentID, err := app.readIDParam(c, "id")
+	if err != nil {
+		app.notFoundResponse(c)
+		return nil, false
+	}
+
+	guardianID, err := app.readIDParam(c, "guardian_id")
+	if err != nil {
+		app.notFoundResponse(c)
+		return nil, false
+	}
+
+	guardian, err := app.models.Guardians.Get(studentID, guardianID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(c)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return nil, false
+	}
+
+	return guardian, true
+}
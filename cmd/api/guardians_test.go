@@ -0,0 +1,86 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+)
+
+type mockGuardianModel struct {
+	insertFn func(g *data.Guardian) error
+	getFn    func(studentID, id int64) (*data.Guardian, error)
+	listFn   func(studentID int64) ([]*data.Guardian, error)
+	updateFn func(g *data.Guardian) error
+	deleteFn func(studentID, id int64) error
+}
+
+func (m *mockGuardianModel) Insert(g *data.Guardian) error {
+	return m.insertFn(g)
+}
+
+func (m *mockGuardianModel) Get(studentID, id int64) (*data.Guardian, error) {
+	return m.getFn(studentID, id)
+}
+
+func (m *mockGuardianModel) ListForStudent(studentID int64) ([]*data.Guardian, error) {
+	return m.listFn(studentID)
+}
+
+func (m *mockGuardianModel) Update(g *data.Guardian) error {
+	return m.updateFn(g)
+}
+
+func (m *mockGuardianModel) Delete(studentID, id int64) error {
+	return m.deleteFn(studentID, id)
+}
+
+func TestCreateGuardianHandler(t *testing.T) {
+	mock := &mockGuardianModel{
+		insertFn: func(g *data.Guardian) error {
+			if g.StudentID != 7 {
+				t.Fatalf("expected student 7, got %d", g.StudentID)
+			}
+			g.ID = 1
+			return nil
+		},
+	}
+
+	app := newTestApp(&mockStudentModel{})
+	app.models.Guardians = mock
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/v1/students/:id/guardians", app.createGuardianHandler)
+
+	body := []byte(`{"name":"Mary Doe","relationship":"mother","phone":"+1 (555) 010-9999"}`)
+	w := performRequest(router, "POST", "/v1/students/7/guardians", body)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+}
+
+func TestCreateGuardianHandler_InvalidContact(t *testing.T) {
+	app := newTestApp(&mockStudentModel{})
+	app.models.Guardians = &mockGuardianModel{}
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/v1/students/:id/guardians", app.createGuardianHandler)
+
+	tests := []string{
+		`{"name":"Mary Doe","relationship":"mother"}`,
+		`{"name":"Mary Doe","relationship":"mother","email":"mary@"}`,
+		`{"name":"Mary Doe","relationship":"mother","phone":"12ab"}`,
+	}
+
+	for _, body := range tests {
+		w := performRequest(router, "POST", "/v1/students/7/guardians", []byte(body))
+
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("%s: expected %d, got %d", body, http.StatusUnprocessableEntity, w.Code)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readIDParam parses a positive integer ID from the named route parameter.
+func (app *application) readIDParam(c *gin.Context, name string) (int64, error) {
+	id, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil || id < 1 {
+		return 0, errors.New("invalid id parameter")
+	}
+
+	return id, nil
+}
+
+// background runs fn in a goroutine tracked by app.wg, so that graceful shutdown
+// waits for it to finish. Panics are recovered and logged rather than crashing the server.
+func (app *application) background(fn func()) {
+	app.wg.Add(1)
+
+	go func() {
+		defer app.wg.Done()
+
+		defer func() {
+			if rec := recover(); rec != nil {
+				app.logger.PrintError(fmt.Errorf("%v", rec), map[string]string{"trace": "panic recovered in background task"})
+			}
+		}()
+
+		fn()
+	}()
+}
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/validator"
+)
+
+const (
+	// maxImportBytes caps the size of an uploaded CSV file.
+	maxImportBytes = 10 << 20
+
+	// importSyncLimit is the largest import that is committed within the request.
+	// Anything bigger runs as a background job.
+	importSyncLimit = 500
+
+	// importBatchSize is the number of rows written per transaction by background jobs.
+	importBatchSize = 500
+)
+
+// importFields are the student fields that can be mapped from CSV columns. Only the
+// required ones must be present in the header.
+var (
+	importFields         = []string{"name", "rollno", "email", "phone", "date_of_birth", "address", "status"}
+	requiredImportFields = []string{"name", "rollno"}
+)
+
+type importLineError struct {
+	Line   int               `json:"line"`
+	Errors map[string]string `json:"errors"`
+}
+
+type importReport struct {
+	DryRun bool              `json:"dry_run"`
+	Total  int               `json:"total"`
+	Valid  int               `json:"valid"`
+	Errors []importLineError `json:"errors"`
+}
+
+// parseStudentCSV reads a CSV file whose first row is a header. mapping maps student
+// fields to header names; fields without an entry are looked up by their own name.
+// Header matching is case-insensitive and optional fields may be left out of the file.
+// Every data row is validated and problems are reported per line rather than stopping
+// at the first one.
+func parseStudentCSV(r io.Reader, mapping map[string]string) ([]*data.Student, []importLineError, error) {
+	for field := range mapping {
+		if !validator.PermittedValue(field, importFields...) {
+			return nil, nil, fmt.Errorf("cannot map unknown field %q", field)
+		}
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, errors.New("file is empty")
+		}
+		return nil, nil, err
+	}
+
+	columns := make(map[string]int, len(importFields))
+	for _, field := range importFields {
+		want := field
+		if name, ok := mapping[field]; ok {
+			want = name
+		}
+
+		for i, h := range header {
+			if strings.EqualFold(strings.TrimSpace(h), strings.TrimSpace(want)) {
+				columns[field] = i
+				break
+			}
+		}
+
+		_, found := columns[field]
+		if !found && (validator.PermittedValue(field, requiredImportFields...) || mapping[field] != "") {
+			return nil, nil, fmt.Errorf("header has no column %q for field %q", want, field)
+		}
+	}
+
+	var students []*data.Student
+	var lineErrors []importLineError
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		cell := func(field string) string {
+			if i, ok := columns[field]; ok && i < len(record) {
+				value := strings.TrimSpace(record[i])
+				if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(value[1])) {
+					value = value[1:]
+				}
+				return value
+			}
+			return ""
+		}
+
+		v := validator.New()
+
+		rollNo, err := strconv.ParseInt(cell("rollno"), 10, 32)
+		if err != nil {
+			v.AddError("rollno", "must be an integer")
+		}
+
+		field := func(name string) *string {
+			value := cell(name)
+			return &value
+		}
+		rollNo32 := int32(rollNo)
+
+		student := data.StudentInput{
+			Name:        field("name"),
+			RollNo:      &rollNo32,
+			Email:       field("email"),
+			Phone:       field("phone"),
+			DateOfBirth: field("date_of_birth"),
+			Address:     field("address"),
+			Status:      field("status"),
+		}.NewStudent(v)
+
+		if !v.Valid() {
+			lineErrors = append(lineErrors, importLineError{Line: line, Errors: v.Errors})
+			continue
+		}
+
+		students = append(students, student)
+	}
+
+	return students, lineErrors, nil
+}
+
+// importStudentsHandler accepts a CSV file, either as the raw request body or as the
+// "file" field of a multipart form. Columns are mapped with map[field]=header query
+// parameters. With dry_run=true the file is only validated. Otherwise a file with any
+// invalid line is rejected as a whole; small files are committed immediately and large
+// ones are handed to a background job.
+func (app *application) importStudentsHandler(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+
+	var body io.Reader = c.Request.Body
+
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		fh, err := c.FormFile("file")
+		if err != nil {
+			app.badRequestResponse(c, err)
+			return
+		}
+
+		f, err := fh.Open()
+		if err != nil {
+			app.serverErrorResponse(c, err)
+			return
+		}
+		defer func() { _ = f.Close() }()
+
+		body = f
+	}
+
+	dryRun := c.Query("dry_run") == "true"
+
+	students, lineErrors, err := parseStudentCSV(body, c.QueryMap("map"))
+	if err != nil {
+		app.badRequestResponse(c, err)
+		return
+	}
+
+	report := importReport{
+		DryRun: dryRun,
+		Total:  len(students) + len(lineErrors),
+		Valid:  len(students),
+		Errors: lineErrors,
+	}
+	if report.Errors == nil {
+		report.Errors = []importLineError{}
+	}
+
+	if dryRun {
+		c.JSON(http.StatusOK, gin.H{"report": report})
+		return
+	}
+
+	if len(lineErrors) > 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  "the file contains invalid lines, nothing was imported",
+			"report": report,
+		})
+		return
+	}
+
+	if len(students) <= importSyncLimit {
+		if err := app.models.Students.InsertMany(students); err != nil {
+			app.serverErrorResponse(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{"report": report, "imported": len(students)})
+		return
+	}
+
+	job := app.imports.create(len(students))
+
+	app.background(func() {
+		app.runImport(job.ID, students)
+	})
+
+	c.Header("Location", fmt.Sprintf("/v1/students/import/%s", job.ID))
+	c.JSON(http.StatusAccepted, gin.H{"report": report, "job": job})
+}
+
+func (app *application) showImportJobHandler(c *gin.Context) {
+	job, ok := app.imports.get(c.Param("job_id"))
+	if !ok {
+		app.notFoundResponse(c)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"job": job})
+}
+
+// runImport writes students in batches so progress can be reported while the job runs.
+// Batches that were committed before a failure stay committed.
+func (app *application) runImport(id string, students []*data.Student) {
+	app.imports.update(id, func(j *importJob) { j.Status = importRunning })
+
+	for start := 0; start < len(students); start += importBatchSize {
+		end := min(start+importBatchSize, len(students))
+
+		if err := app.models.Students.InsertMany(students[start:end]); err != nil {
+			app.logger.PrintError(err, map[string]string{"import_job": id})
+			app.imports.finish(id, importFailed, err.Error())
+			return
+		}
+
+		app.imports.update(id, func(j *importJob) { j.Imported = end })
+	}
+
+	app.imports.finish(id, importCompleted, "")
+
+	app.logger.PrintInfo("student import completed", map[string]string{
+		"import_job": id,
+		"rows":       strconv.Itoa(len(students)),
+	})
+}
+
+const (
+	importPending   = "pending"
+	importRunning   = "running"
+	importCompleted = "completed"
+	importFailed    = "failed"
+)
+
+type importJob struct {
+	ID         string     `json:"id"`
+	Status     string     `json:"status"`
+	Total      int        `json:"total"`
+	Imported   int        `json:"imported"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// importJobs is an in-memory registry of background imports. Finished jobs are kept
+// for importJobRetention so clients can poll for the outcome.
+type importJobs struct {
+	mu   sync.Mutex
+	jobs map[string]*importJob
+}
+
+const importJobRetention = time.Hour
+
+func newImportJobs() *importJobs {
+	return &importJobs{jobs: make(map[string]*importJob)}
+}
+
+func (r *importJobs) create(total int) importJob {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+
+	job := &importJob{
+		ID:        hex.EncodeToString(b),
+		Status:    importPending,
+		Total:     total,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, j := range r.jobs {
+		if j.FinishedAt != nil && time.Since(*j.FinishedAt) > importJobRetention {
+			delete(r.jobs, id)
+		}
+	}
+
+	r.jobs[job.ID] = job
+	return *job
+}
+
+func (r *importJobs) get(id string) (importJob, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, ok := r.jobs[id]
+	if !ok {
+		return importJob{}, false
+	}
+	return *job, true
+}
+
+func (r *importJobs) update(id string, fn func(*importJob)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if job, ok := r.jobs[id]; ok {
+		fn(job)
+	}
+}
+
+func (r *importJobs) finish(id, status, errMsg string) {
+	r.update(id, func(j *importJob) {
+		now := time.Now().UTC()
+		j.Status = status
+		j.Error = errMsg
+		j.FinishedAt = &now
+	})
+}
@@ -3,40 +3,63 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"flag"
 	"log"
 	"os"
-	"strconv"
-	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/graph"
 	"github.com/sai29/one2n_sre_bootcamp/internal/jsonlog"
+	"github.com/sai29/one2n_sre_bootcamp/internal/openapi"
+	"github.com/sai29/one2n_sre_bootcamp/internal/replica"
+	"github.com/sai29/one2n_sre_bootcamp/internal/settings"
+	"github.com/sai29/one2n_sre_bootcamp/internal/stream"
 
 	_ "github.com/lib/pq"
 )
 
-type config struct {
-	port int
-	env  string
-	db   struct {
-		dsn          string
-		maxOpenConns int
-		maxIdleConns int
-		maxIdleTime  string
-	}
-	cors struct {
-		trustedOrigins []string
-	}
-}
-
 type application struct {
-	config config
-	logger *jsonlog.Logger
-	models data.Models
-	wg     sync.WaitGroup
+	config  config
+	logger  *jsonlog.Logger
+	models  data.Models
+	imports *importJobs
+	schema  *schemaCheck
+	wg      sync.WaitGroup
+
+	// args is the command line the server was started with; a SIGHUP reloads the
+	// configuration from it, the config file and the environment.
+	args []string
+
+	// live holds the configuration as updated by reloads. Only the reloadable settings
+	// ever differ from config, so code reading anything else can keep using config.
+	live atomic.Pointer[config]
+
+	// pools are the Postgres connection pools, primary first, resized on reload.
+	pools []*sql.DB
+
+	limiter *rateLimiter
+
+	replicas      *replica.Pool
+	replicaModels []data.Models
+
+	graph *graph.Server
+
+	// contract checks requests and responses against the OpenAPI spec while
+	// contract.mode is log or strict.
+	contract *openapi.Validator
+
+	// streams feeds GET /v1/students/events from the outbox tail; nil when the backend
+	// has no outbox.
+	streams *stream.Hub
+
+	// shutdown is closed by serve once the HTTP server has stopped, telling long-running
+	// background loops to return so that wg.Wait can complete.
+	shutdown chan struct{}
 }
 
 func main() {
@@ -45,70 +68,125 @@ func main() {
 		log.Println("error loading .env file")
 	}
 
-	var cfg config
+	logger := jsonlog.NewLogger(os.Stdout, jsonlog.LevelInfo)
+
+	if len(os.Args) > 1 && (os.Args[1] == "migrate" || os.Args[1] == "seed") {
+		run := runMigrate
+		if os.Args[1] == "seed" {
+			run = runSeed
+		}
 
-	port := mustGetIntEnv("SERVER_PORT")
-	flag.IntVar(&cfg.port, "port", port, "API server port")
+		err := run(os.Args[2:])
+		if err != nil && !errors.Is(err, flag.ErrHelp) {
+			logger.PrintFatal(err, map[string]string{"command": os.Args[1]})
+		}
+		return
+	}
 
-	flag.StringVar(&cfg.env, "env", getEnv("ENV", "development"), "Environment (dev|stage|prod)")
+	cfg, opts, err := loadConfig(os.Args[1:], os.LookupEnv, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		logger.PrintFatal(err, nil)
+	}
 
-	flag.StringVar(&cfg.db.dsn, "db-dsn", mustGetEnv("DATABASE_URL"), "PostgreSQL DSN")
+	if opts.print {
+		out, err := settings.Marshal(cfg)
+		if err != nil {
+			logger.PrintFatal(err, nil)
+		}
+		os.Stdout.Write(out)
+	}
 
-	flag.IntVar(&cfg.db.maxOpenConns, "db-max-open-conns", 25, "Postgres max open conns")
-	flag.IntVar(&cfg.db.maxIdleConns, "db-max-idle-conns", 25, "Postgres max idle conns")
-	flag.StringVar(&cfg.db.maxIdleTime, "db-max-idle-time", "15m", "Postgres max conn idle time")
+	if problems := cfg.validate(); len(problems) > 0 {
+		logger.PrintFatal(errors.New("invalid configuration"), problems)
+	}
 
-	flag.Func("cors-trusted-origins", "Trusted CORS origins (space separated)", func(val string) error {
-		cfg.cors.trustedOrigins = strings.Fields(val)
-		return nil
-	})
+	if opts.print {
+		return
+	}
 
-	flag.Parse()
+	level, _ := jsonlog.ParseLevel(cfg.Log.Level)
+	logger.SetLevel(level)
 
-	logger := jsonlog.NewLogger(os.Stdout, jsonlog.LevelInfo)
+	data.QueryTimeout = cfg.DB.QueryTimeout
 
-	db, err := openDB(cfg)
+	store, err := openStorage(cfg, logger)
 	if err != nil {
 		logger.PrintFatal(err, nil)
 	}
 
 	defer func() {
-		if err := db.Close(); err != nil {
+		if err := store.close(); err != nil {
 			logger.PrintFatal(err, nil)
 		}
 	}()
 
-	logger.PrintInfo("database connection pool established", nil)
-
 	app := &application{
-		config: cfg,
-		logger: logger,
-		models: data.NewModels(db),
+		config:  cfg,
+		logger:  logger,
+		args:    os.Args[1:],
+		pools:   store.pools,
+		limiter: newRateLimiter(),
+		models:  store.models,
+		imports: newImportJobs(),
+		schema:  store.schema,
+
+		replicas:      store.replicas,
+		replicaModels: store.replicaModels,
+		shutdown:      make(chan struct{}),
 	}
 
-	if err := app.serve(); err != nil {
+	app.graph, err = graph.New(graph.Options{
+		MaxDepth:      cfg.GraphQL.MaxDepth,
+		MaxComplexity: cfg.GraphQL.MaxComplexity,
+		Logger:        logger,
+	})
+	if err != nil {
 		logger.PrintFatal(err, nil)
 	}
 
-}
+	live := cfg
+	app.live.Store(&live)
 
-func openDB(cfg config) (*sql.DB, error) {
-	db, err := sql.Open("postgres", cfg.db.dsn)
+	// The validator is built even when contract validation is off so that a reload can
+	// turn it on.
+	app.contract, err = openapi.NewValidator(openAPISpec())
 	if err != nil {
-		return nil, err
+		logger.PrintFatal(err, nil)
+	}
+
+	app.background(app.sweepRateLimiter)
+
+	if app.replicas != nil {
+		app.background(app.monitorReplicas)
 	}
 
-	db.SetMaxOpenConns(cfg.db.maxOpenConns)
+	if app.models.Outbox != nil {
+		app.streams = stream.NewHub(cfg.Stream.Buffer)
+	}
+
+	if err := app.startOutboxRelay(); err != nil {
+		logger.PrintFatal(err, nil)
+	}
 
-	db.SetMaxIdleConns(cfg.db.maxIdleConns)
+	app.startStreamTail()
 
-	duration, err := time.ParseDuration(cfg.db.maxIdleTime)
+	app.startWebhookDispatcher()
+
+	if err := app.serve(); err != nil {
+		logger.PrintFatal(err, nil)
+	}
+
+}
+
+func openDB(cfg config) (*sql.DB, error) {
+	db, err := newDBPool(cfg, cfg.DB.DSN)
 	if err != nil {
 		return nil, err
 	}
 
-	db.SetConnMaxIdleTime(duration)
-
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -120,26 +198,19 @@ func openDB(cfg config) (*sql.DB, error) {
 	return db, nil
 }
 
-func mustGetEnv(key string) string {
-	val := os.Getenv(key)
-	if val == "" {
-		log.Fatalf("%s must be set", key)
-	}
-	return val
-}
-
-func mustGetIntEnv(key string) int {
-	val := mustGetEnv(key)
-	i, err := strconv.Atoi(val)
+// newDBPool opens a Postgres pool for dsn with the configured limits but does not check
+// that the server is reachable.
+func newDBPool(cfg config, dsn string) (*sql.DB, error) {
+	db, err := sql.Open("postgres", dsn)
 	if err != nil {
-		log.Fatalf("%s must be a valid integer", key)
+		return nil, err
 	}
-	return i
-}
 
-func getEnv(key, fallback string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
-	}
-	return fallback
+	db.SetMaxOpenConns(cfg.DB.MaxOpenConns)
+
+	db.SetMaxIdleConns(cfg.DB.MaxIdleConns)
+
+	db.SetConnMaxIdleTime(cfg.DB.MaxIdleTime)
+
+	return db, nil
 }
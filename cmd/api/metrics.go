@@ -22,4 +22,122 @@ var (
 		},
 		[]string{"method", "endpoint"},
 	)
+
+	attendanceAlertsTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "attendance_threshold_alerts_total",
+			Help: "Total number of times a student's course attendance fell below the policy threshold",
+		},
+	)
+
+	schemaVersion = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "schema_version",
+			Help: "Database schema version recorded in schema_migrations",
+		},
+	)
+
+	dbReadsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "db_reads_total",
+			Help: "Total number of routable reads by the database that served them (primary or replica)",
+		},
+		[]string{"target"},
+	)
+
+	dbReplicaHealthy = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "db_replica_healthy",
+			Help: "Whether a read replica passed its last health check (1) or not (0)",
+		},
+		[]string{"replica"},
+	)
+
+	cacheHitsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_hits_total",
+			Help: "Total number of cache lookups answered from the cache",
+		},
+		[]string{"cache"},
+	)
+
+	cacheMissesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_misses_total",
+			Help: "Total number of cache lookups that went to the database, including refused stale entries",
+		},
+		[]string{"cache"},
+	)
+
+	cacheEvictionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_evictions_total",
+			Help: "Total number of entries evicted from an in-memory cache to make room",
+		},
+		[]string{"cache"},
+	)
+
+	outboxPublishedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_events_published_total",
+			Help: "Total number of outbox events delivered to a sink",
+		},
+		[]string{"sink"},
+	)
+
+	outboxFailuresTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_publish_failures_total",
+			Help: "Total number of failed outbox deliveries to a sink; the batch is retried on the next poll",
+		},
+		[]string{"sink"},
+	)
+
+	webhookDeliveriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "webhook_delivery_attempts_total",
+			Help: "Total number of webhook delivery attempts by resulting status (succeeded, pending for a retry, dead)",
+		},
+		[]string{"status"},
+	)
+
+	grpcRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_requests_total",
+			Help: "Total number of gRPC calls by method and status code",
+		},
+		[]string{"method", "code"},
+	)
+
+	grpcRequestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "grpc_request_duration_seconds",
+			Help:    "gRPC call duration in seconds; for streams, how long the stream was open",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"method"},
+	)
+
+	contractViolationsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "contract_violations_total",
+			Help: "Total number of requests and responses that did not match the OpenAPI spec, by operation and direction",
+		},
+		[]string{"operation", "direction"},
+	)
+
+	configReloadsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "config_reloads_total",
+			Help: "Total number of configuration reloads triggered by SIGHUP, by result (success or failure)",
+		},
+		[]string{"result"},
+	)
 )
+
+// studentCacheRecorder reports student cache events to Prometheus.
+type studentCacheRecorder struct{}
+
+func (studentCacheRecorder) Hit()   { cacheHitsTotal.WithLabelValues("students").Inc() }
+func (studentCacheRecorder) Miss()  { cacheMissesTotal.WithLabelValues("students").Inc() }
+func (studentCacheRecorder) Evict() { cacheEvictionsTotal.WithLabelValues("students").Inc() }
@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"net/http"
+	"slices"
 	"strconv"
 	"time"
 
@@ -34,3 +36,27 @@ func (app *application) recoverPanic() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// enableCORS allows cross-origin requests from cors.trusted_origins and answers their
+// preflight requests. Origins are read on each request, so a reload applies at once.
+func (app *application) enableCORS() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("Vary", "Origin")
+		c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+
+		origin := c.GetHeader("Origin")
+
+		if origin != "" && slices.Contains(app.liveConfig().CORS.TrustedOrigins, origin) {
+			c.Header("Access-Control-Allow-Origin", origin)
+
+			if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+				c.Header("Access-Control-Allow-Methods", "OPTIONS, PUT, PATCH, DELETE")
+				c.Header("Access-Control-Allow-Headers", "Authorization, Content-Type")
+				c.AbortWithStatus(http.StatusOK)
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestEnableCORS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	app := newTestApp(&mockStudentModel{})
+	app.config.CORS.TrustedOrigins = []string{"https://example.com"}
+
+	r := app.routes()
+
+	tests := []struct {
+		origin    string
+		wantAllow string
+		wantCode  int
+	}{
+		{"https://example.com", "https://example.com", http.StatusOK},
+		{"https://evil.example", "", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodOptions, "/v1/students/1", nil)
+		req.Header.Set("Origin", tt.origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodPatch)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantAllow {
+			t.Errorf("%s: Access-Control-Allow-Origin is %q, want %q", tt.origin, got, tt.wantAllow)
+		}
+		if w.Code != tt.wantCode {
+			t.Errorf("%s: got status %d, want %d", tt.origin, w.Code, tt.wantCode)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/jsonlog"
+	"github.com/sai29/one2n_sre_bootcamp/internal/migrate"
+	"github.com/sai29/one2n_sre_bootcamp/migrations"
+)
+
+const migrateUsage = `Usage: students_api migrate [flags] <command>
+
+Commands:
+  up          apply all pending migrations
+  down [N]    roll back N migrations (default 1)
+  goto N      migrate up or down to version N
+  force N     set the version to N and clear the dirty flag without running SQL
+  status      show the current version and pending migrations
+
+Flags:
+`
+
+// runMigrate implements the "migrate" subcommand. It only needs the database DSN, from
+// -db-dsn, the -config file or the environment, and checks none of the other settings, so
+// it can run as a Helm hook or init container before the rest of the API configuration
+// exists.
+func runMigrate(args []string) error {
+	var usage func()
+
+	cfg, rest, err := parseConfig("migrate", args, os.LookupEnv, os.Stderr, func(fs *flag.FlagSet, cfg *config) {
+		fs.StringVar(&cfg.DB.DSN, "db-dsn", cfg.DB.DSN, "PostgreSQL DSN (also DATABASE_URL)")
+		fs.Usage = func() {
+			fmt.Fprint(fs.Output(), migrateUsage)
+			fs.PrintDefaults()
+		}
+		usage = fs.Usage
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(rest) == 0 {
+		usage()
+		return errors.New("missing migrate command")
+	}
+
+	if cfg.DB.DSN == "" {
+		return errors.New("DATABASE_URL or -db-dsn must be set")
+	}
+
+	cfg.DB.MaxOpenConns = 2
+	cfg.DB.MaxIdleConns = 2
+	cfg.DB.MaxIdleTime = time.Minute
+
+	db, err := openDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+
+	m, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		return err
+	}
+	m.Logger = jsonlog.NewLogger(os.Stdout, jsonlog.LevelInfo)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	switch cmd := rest[0]; cmd {
+	case "up":
+		return m.Up(ctx)
+
+	case "down":
+		steps := 1
+		if len(rest) > 1 {
+			steps, err = strconv.Atoi(rest[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps %q", rest[1])
+			}
+		}
+		return m.Down(ctx, steps)
+
+	case "goto", "force":
+		if len(rest) < 2 {
+			return fmt.Errorf("%s requires a version", cmd)
+		}
+		version, err := strconv.ParseInt(rest[1], 10, 64)
+		if err != nil || version < 0 {
+			return fmt.Errorf("invalid version %q", rest[1])
+		}
+		if cmd == "force" {
+			return m.Force(ctx, version)
+		}
+		return m.Goto(ctx, version)
+
+	case "status":
+		status, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return printMigrateStatus(status)
+
+	default:
+		usage()
+		return fmt.Errorf("unknown migrate command %q", cmd)
+	}
+}
+
+func printMigrateStatus(status *migrate.Status) error {
+	fmt.Printf("version: %d (latest %d)", status.Version, status.Latest)
+	if status.Dirty {
+		fmt.Print(" dirty")
+	}
+	fmt.Print("\n\n")
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tNAME\tSTATE")
+
+	for _, mig := range status.Migrations {
+		state := "pending"
+		if mig.Applied {
+			state = "applied"
+		}
+		fmt.Fprintf(tw, "%06d\t%s\t%s\n", mig.Version, mig.Name, state)
+	}
+
+	return tw.Flush()
+}
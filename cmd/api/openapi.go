@@ -0,0 +1,852 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/graph"
+	"github.com/sai29/one2n_sre_bootcamp/internal/openapi"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+// openAPISpec is the API's contract. Response bodies are generated from the structs the
+// handlers encode; request bodies, parameters and status codes are declared here and must
+// be kept in step with the handlers. TestOpenAPICoversRoutes fails when a route is missing.
+var openAPISpec = sync.OnceValue(buildOpenAPISpec)
+
+var openAPIJSON = sync.OnceValues(func() ([]byte, error) {
+	return json.MarshalIndent(openAPISpec(), "", "  ")
+})
+
+func (app *application) openAPIHandler(c *gin.Context) {
+	b, err := openAPIJSON()
+	if err != nil {
+		app.serverErrorResponse(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json", b)
+}
+
+// docsInitializer replaces Swagger UI's swagger-initializer.js, which points at the petstore
+// example, with one that loads this API's spec.
+const docsInitializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: "/v1/openapi.json",
+    dom_id: "#swagger-ui",
+    deepLinking: true,
+    presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+    plugins: [SwaggerUIBundle.plugins.DownloadUrl],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+var docsFileServer = http.StripPrefix("/docs", http.FileServer(http.FS(swaggerFiles.FS)))
+
+// docsHandler serves Swagger UI, which is embedded in the binary, at /docs/.
+func (app *application) docsHandler(c *gin.Context) {
+	switch strings.TrimPrefix(c.Param("filepath"), "/") {
+	case "swagger-initializer.js":
+		c.Data(http.StatusOK, "text/javascript; charset=utf-8", []byte(docsInitializer))
+	default:
+		docsFileServer.ServeHTTP(c.Writer, c.Request)
+	}
+}
+
+// === Spec ===
+
+var specReflector = &openapi.Reflector{
+	Types: map[reflect.Type]*openapi.Schema{
+		reflect.TypeOf(data.Date{}): {Type: "string", Format: "date"},
+	},
+}
+
+func buildOpenAPISpec() *openapi.Document {
+	doc := &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title:   "Student API",
+			Version: "v1",
+			Description: "Manage students, their guardians, courses, enrollments, grades and attendance. " +
+				"Routes other than /v1/students, /v1/graphql and the operational endpoints are only " +
+				"served when the API runs on Postgres.",
+		},
+		Components: openapi.Components{
+			Schemas:   specSchemas(),
+			Responses: specErrorResponses(),
+		},
+	}
+
+	addOperationalSpec(doc)
+	addStudentSpec(doc)
+	addGuardianSpec(doc)
+	addCourseSpec(doc)
+	addEnrollmentSpec(doc)
+	addGradeSpec(doc)
+	addAttendanceSpec(doc)
+	addWebhookSpec(doc)
+
+	return doc
+}
+
+func specSchemas() map[string]*openapi.Schema {
+	studentInput := object(map[string]*openapi.Schema{
+		"name":          {Type: "string", MaxLength: ptr(500)},
+		"rollno":        {Type: "integer", Format: "int32", Minimum: ptr(1.0)},
+		"email":         {Type: "string"},
+		"phone":         {Type: "string"},
+		"date_of_birth": openapi.Nullable(&openapi.Schema{Type: "string", Format: "date"}),
+		"address":       {Type: "string"},
+		"status":        enum(data.StudentStatuses...),
+	}, "name", "rollno")
+	studentInput.Description = "status defaults to applicant."
+
+	studentUpdate := object(map[string]*openapi.Schema{
+		"name":          {Type: "string", MaxLength: ptr(500)},
+		"rollno":        {Type: "integer", Format: "int32", Minimum: ptr(1.0)},
+		"email":         {Type: "string"},
+		"phone":         {Type: "string"},
+		"date_of_birth": {Type: "string", Format: "date"},
+		"address":       {Type: "string"},
+	})
+	studentUpdate.Description = "Only the fields present are changed. Status changes go through POST /v1/students/{id}/transitions."
+
+	courseFields := map[string]*openapi.Schema{
+		"code":     {Type: "string", MaxLength: ptr(20)},
+		"title":    {Type: "string", MaxLength: ptr(500)},
+		"credits":  {Type: "integer", Format: "int32"},
+		"capacity": {Type: "integer", Format: "int32"},
+		"term":     {Type: "string"},
+	}
+
+	guardianFields := map[string]*openapi.Schema{
+		"name":         {Type: "string", MaxLength: ptr(500)},
+		"relationship": {Type: "string"},
+		"email":        {Type: "string"},
+		"phone":        {Type: "string"},
+	}
+
+	webhookFields := map[string]*openapi.Schema{
+		"url":         {Type: "string", Format: "uri", MaxLength: ptr(2000)},
+		"event_types": {Type: "array", Items: enum(data.StudentEventTypes...), MinItems: ptr(1), UniqueItems: true},
+		"active":      {Type: "boolean"},
+	}
+
+	transcript := object(map[string]*openapi.Schema{
+		"student":           openapi.Ref("Student"),
+		"terms":             {Type: "array", Items: specReflector.Schema(transcriptTerm{})},
+		"credits_attempted": {Type: "integer", Format: "int32"},
+		"credits_earned":    {Type: "integer", Format: "int32"},
+		"cumulative_gpa":    {Type: "number"},
+	}, "student", "terms", "credits_attempted", "credits_earned", "cumulative_gpa")
+
+	return map[string]*openapi.Schema{
+		"Student":           specReflector.Schema(data.Student{}),
+		"StudentInput":      studentInput,
+		"StudentUpdate":     studentUpdate,
+		"StudentTransition": specReflector.Schema(data.StudentTransition{}),
+		"Guardian":          specReflector.Schema(data.Guardian{}),
+		"GuardianInput":     object(guardianFields, "name", "relationship"),
+		"GuardianUpdate":    object(guardianFields),
+		"Course":            specReflector.Schema(data.Course{}),
+		"CourseInput":       object(courseFields, "code", "title", "credits", "capacity", "term"),
+		"CourseUpdate":      object(courseFields),
+		"Enrollment":        specReflector.Schema(data.Enrollment{}),
+		"StudentCourse":     specReflector.Schema(data.StudentCourse{}),
+		"Grade":             specReflector.Schema(data.Grade{}),
+		"Transcript":        transcript,
+		"AttendanceRecord":  specReflector.Schema(data.AttendanceRecord{}),
+		"AttendanceSummary": specReflector.Schema(data.AttendanceSummary{}),
+		"AttendanceAlert":   specReflector.Schema(attendanceAlert{}),
+		"CourseAttendance":  specReflector.Schema(courseAttendance{}),
+		"ImportReport":      specReflector.Schema(importReport{}),
+		"ImportJob":         specReflector.Schema(importJob{}),
+		"Webhook":           specReflector.Schema(data.Webhook{}),
+		"WebhookInput":      object(webhookFields, "url", "event_types"),
+		"WebhookUpdate":     object(webhookFields),
+		"WebhookDelivery":   specReflector.Schema(data.WebhookDelivery{}),
+		"GraphQLRequest":    specReflector.Schema(graph.Request{}),
+		"SchemaStatus":      specReflector.Schema(schemaStatus{}),
+		"Message":           object(map[string]*openapi.Schema{"message": {Type: "string"}}, "message"),
+		"Error": object(map[string]*openapi.Schema{
+			"error": {
+				Type:        []string{"string", "object"},
+				Description: "A message, or for failed validation an object keyed by the invalid fields.",
+			},
+		}, "error"),
+	}
+}
+
+// specErrorResponses are the error responses shared by the operations. Every error body is
+// the Error envelope.
+func specErrorResponses() map[string]*openapi.Response {
+	errorBody := func(desc string) *openapi.Response {
+		return jsonResponse(desc, openapi.Ref("Error"))
+	}
+
+	return map[string]*openapi.Response{
+		"BadRequest":       errorBody("The request body or a parameter could not be parsed."),
+		"NotFound":         errorBody("The resource does not exist."),
+		"EditConflict":     errorBody("The resource was changed by someone else; fetch it and try again."),
+		"ValidationFailed": errorBody("The request is well-formed but invalid. error maps each invalid field to a message."),
+		"ServerError":      errorBody("The server could not process the request."),
+	}
+}
+
+var errorResponseNames = map[int]string{
+	http.StatusBadRequest:          "BadRequest",
+	http.StatusNotFound:            "NotFound",
+	http.StatusConflict:            "EditConflict",
+	http.StatusUnprocessableEntity: "ValidationFailed",
+	http.StatusInternalServerError: "ServerError",
+}
+
+// === Operations ===
+
+func addOperationalSpec(doc *openapi.Document) {
+	doc.Add(http.MethodGet, "/metrics", &openapi.Operation{
+		OperationID: "metrics",
+		Summary:     "Prometheus metrics",
+		Tags:        []string{"operations"},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Metrics in the Prometheus text format.", Content: textContent("text/plain")},
+		},
+	})
+
+	doc.Add(http.MethodGet, "/v1/healthcheck", &openapi.Operation{
+		OperationID: "healthcheck",
+		Summary:     "Liveness check",
+		Tags:        []string{"operations"},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The server is up.", object(map[string]*openapi.Schema{
+				"status": {Type: "string"},
+				"env":    {Type: "string"},
+			}, "status", "env")),
+		},
+	})
+
+	readiness := object(map[string]*openapi.Schema{
+		"status": enum("ready", "degraded"),
+		"schema": openapi.Ref("SchemaStatus"),
+	}, "status")
+
+	doc.Add(http.MethodGet, "/v1/readiness", &openapi.Operation{
+		OperationID: "readiness",
+		Summary:     "Readiness check",
+		Description: "Reports whether the database schema matches the migrations this build expects.",
+		Tags:        []string{"operations"},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("Ready to serve traffic.", readiness),
+			"503": jsonResponse("The schema is behind, dirty or could not be read.", readiness),
+		},
+	})
+
+	doc.Add(http.MethodGet, "/v1/openapi.json", &openapi.Operation{
+		OperationID: "openapi",
+		Summary:     "This OpenAPI document",
+		Tags:        []string{"operations"},
+		Responses: map[string]*openapi.Response{
+			"200": jsonResponse("The OpenAPI 3.1 document.", &openapi.Schema{Type: "object"}),
+		},
+	})
+
+	doc.Add(http.MethodPost, "/v1/graphql", &openapi.Operation{
+		OperationID: "graphql",
+		Summary:     "Execute a GraphQL request",
+		Description: "Errors raised while resolving are reported in the errors array of a 200 response.",
+		Tags:        []string{"graphql"},
+		RequestBody: jsonBody(openapi.Ref("GraphQLRequest")),
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": jsonResponse("The GraphQL response.", object(map[string]*openapi.Schema{
+				"data":   {},
+				"errors": {Type: "array", Items: &openapi.Schema{Type: "object"}},
+			})),
+		}, http.StatusBadRequest),
+	})
+}
+
+func addStudentSpec(doc *openapi.Document) {
+	student := openapi.Ref("Student")
+	id := idParam("id", "Student ID")
+
+	doc.Add(http.MethodPost, "/v1/students", &openapi.Operation{
+		OperationID: "createStudent",
+		Summary:     "Create a student",
+		Tags:        []string{"students"},
+		RequestBody: jsonBody(openapi.Ref("StudentInput")),
+		Responses: withErrors(map[string]*openapi.Response{
+			"201": created("The new student.", envelope("student", student)),
+		}, http.StatusBadRequest, http.StatusUnprocessableEntity),
+	})
+
+	doc.Add(http.MethodGet, "/v1/students", &openapi.Operation{
+		OperationID: "listStudents",
+		Summary:     "List students",
+		Tags:        []string{"students"},
+		Parameters: []*openapi.Parameter{
+			{Name: "limit", In: "query", Schema: &openapi.Schema{Type: "integer", Minimum: ptr(1.0), Maximum: ptr(1000.0)},
+				Description: "Page size. Without it every student is returned."},
+			{Name: "offset", In: "query", Schema: &openapi.Schema{Type: "integer", Minimum: ptr(0.0)},
+				Description: "Students to skip before the page starts."},
+		},
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": jsonResponse("The students, in ID order.", envelope("students", arrayOf(student))),
+		}, http.StatusUnprocessableEntity),
+	})
+
+	formats := make([]string, 0, len(exportFormats))
+	content := map[string]*openapi.MediaType{}
+	for name, f := range exportFormats {
+		formats = append(formats, name)
+		mediaType, _, _ := strings.Cut(f.contentType, ";")
+		content[mediaType] = &openapi.MediaType{Schema: &openapi.Schema{Type: "string", Format: "binary"}}
+	}
+	sort.Strings(formats)
+
+	doc.Add(http.MethodGet, "/v1/students/export", &openapi.Operation{
+		OperationID: "exportStudents",
+		Summary:     "Export every student as a file",
+		Tags:        []string{"students"},
+		Parameters: []*openapi.Parameter{
+			{Name: "format", In: "query", Schema: enum(formats...), Description: "Defaults to csv."},
+		},
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": {Description: "The roster, streamed as an attachment.", Content: content},
+		}, http.StatusBadRequest),
+	})
+
+	doc.Add(http.MethodPost, "/v1/students/import", &openapi.Operation{
+		OperationID: "importStudents",
+		Summary:     "Import students from a CSV file",
+		Description: "Every line is validated first, and nothing is imported if any line is invalid. Files of up to " +
+			"500 students are imported within the request; larger ones run as a background job.",
+		Tags: []string{"students"},
+		Parameters: []*openapi.Parameter{
+			{Name: "dry_run", In: "query", Schema: &openapi.Schema{Type: "boolean"}, Description: "Only validate the file."},
+			{Name: "map[field]", In: "query", Schema: &openapi.Schema{Type: "string"},
+				Description: "Reads a student field from a differently named column, e.g. map[name]=Full Name."},
+		},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content: map[string]*openapi.MediaType{
+				"text/csv": {Schema: &openapi.Schema{Type: "string"}},
+				"multipart/form-data": {Schema: object(map[string]*openapi.Schema{
+					"file": {Type: "string", Format: "binary"},
+				}, "file")},
+			},
+		},
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": jsonResponse("The dry run report.", envelope("report", openapi.Ref("ImportReport"))),
+			"201": jsonResponse("The students were imported.", object(map[string]*openapi.Schema{
+				"report":   openapi.Ref("ImportReport"),
+				"imported": {Type: "integer"},
+			}, "report", "imported")),
+			"202": {
+				Description: "The import is running as a background job.",
+				Headers:     locationHeader(),
+				Content: jsonContent(object(map[string]*openapi.Schema{
+					"report": openapi.Ref("ImportReport"),
+					"job":    openapi.Ref("ImportJob"),
+				}, "report", "job")),
+			},
+			"422": jsonResponse("The file has invalid lines.", object(map[string]*openapi.Schema{
+				"error":  {Type: "string"},
+				"report": openapi.Ref("ImportReport"),
+			}, "error", "report")),
+		}, http.StatusBadRequest),
+	})
+
+	doc.Add(http.MethodGet, "/v1/students/import/{job_id}", &openapi.Operation{
+		OperationID: "showImportJob",
+		Summary:     "Show a background import",
+		Tags:        []string{"students"},
+		Parameters: []*openapi.Parameter{
+			{Name: "job_id", In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}},
+		},
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": jsonResponse("The job.", envelope("job", openapi.Ref("ImportJob"))),
+		}, http.StatusNotFound),
+	})
+
+	doc.Add(http.MethodGet, "/v1/students/events", &openapi.Operation{
+		OperationID: "studentEvents",
+		Summary:     "Stream student changes as Server-Sent Events",
+		Description: "Each event's id is the outbox event id and its name is the event type. A reset event " +
+			"means the events after Last-Event-ID can no longer be replayed.",
+		Tags: []string{"students"},
+		Parameters: []*openapi.Parameter{
+			{Name: "Last-Event-ID", In: "header", Schema: &openapi.Schema{Type: "integer", Format: "int64", Minimum: ptr(0.0)}},
+			{Name: "last_event_id", In: "query", Schema: &openapi.Schema{Type: "integer", Format: "int64", Minimum: ptr(0.0)},
+				Description: "Used when the Last-Event-ID header is absent."},
+		},
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": {Description: "An endless event stream.", Content: textContent("text/event-stream")},
+		}, http.StatusBadRequest),
+	})
+
+	doc.Add(http.MethodGet, "/v1/students/{id}", &openapi.Operation{
+		OperationID: "showStudent",
+		Summary:     "Show a student",
+		Tags:        []string{"students"},
+		Parameters:  []*openapi.Parameter{id},
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": jsonResponse("The student.", envelope("student", student)),
+		}, http.StatusNotFound),
+	})
+
+	doc.Add(http.MethodPatch, "/v1/students/{id}", &openapi.Operation{
+		OperationID: "updateStudent",
+		Summary:     "Update a student",
+		Tags:        []string{"students"},
+		Parameters:  []*openapi.Parameter{id},
+		RequestBody: jsonBody(openapi.Ref("StudentUpdate")),
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": jsonResponse("The updated student.", envelope("student", student)),
+		}, http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity),
+	})
+
+	doc.Add(http.MethodDelete, "/v1/students/{id}", &openapi.Operation{
+		OperationID: "deleteStudent",
+		Summary:     "Delete a student",
+		Tags:        []string{"students"},
+		Parameters:  []*openapi.Parameter{id},
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": jsonResponse("The student was deleted.", openapi.Ref("Message")),
+		}, http.StatusNotFound),
+	})
+
+	doc.Add(http.MethodPost, "/v1/students/{id}/transitions", &openapi.Operation{
+		OperationID: "createTransition",
+		Summary:     "Change a student's status",
+		Description: "Fails with 422 when the status can't move to the requested one; the error lists the allowed transitions.",
+		Tags:        []string{"students"},
+		Parameters:  []*openapi.Parameter{id},
+		RequestBody: jsonBody(object(map[string]*openapi.Schema{
+			"to":      enum(data.StudentStatuses...),
+			"reason":  {Type: "string", MaxLength: ptr(1000)},
+			"actor":   {Type: "string"},
+			"version": {Type: "integer", Format: "int32", Description: "When set, must match the student's version."},
+		}, "to", "reason")),
+		Responses: withErrors(map[string]*openapi.Response{
+			"201": jsonResponse("The transition was applied.", object(map[string]*openapi.Schema{
+				"student":    student,
+				"transition": openapi.Ref("StudentTransition"),
+			}, "student", "transition")),
+		}, http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity),
+	})
+
+	doc.Add(http.MethodGet, "/v1/students/{id}/transitions", &openapi.Operation{
+		OperationID: "listTransitions",
+		Summary:     "Show a student's status history",
+		Tags:        []string{"students"},
+		Parameters:  []*openapi.Parameter{id},
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": jsonResponse("The current status, where it can move next, and the history.", object(map[string]*openapi.Schema{
+				"status":              enum(data.StudentStatuses...),
+				"allowed_transitions": arrayOf(&openapi.Schema{Type: "string"}),
+				"transitions":         arrayOf(openapi.Ref("StudentTransition")),
+			}, "status", "allowed_transitions", "transitions")),
+		}, http.StatusNotFound),
+	})
+}
+
+func addGuardianSpec(doc *openapi.Document) {
+	guardian := openapi.Ref("Guardian")
+	params := []*openapi.Parameter{idParam("id", "Student ID"), idParam("guardian_id", "Guardian ID")}
+
+	doc.Add(http.MethodPost, "/v1/students/{id}/guardians", &openapi.Operation{
+		OperationID: "createGuardian",
+		Summary:     "Add a guardian to a student",
+		Tags:        []string{"guardians"},
+		Parameters:  params[:1],
+		RequestBody: jsonBody(openapi.Ref("GuardianInput")),
+		Responses: withErrors(map[string]*openapi.Response{
+			"201": created("The new guardian.", envelope("guardian", guardian)),
+		}, http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity),
+	})
+
+	doc.Add(http.MethodGet, "/v1/students/{id}/guardians", &openapi.Operation{
+		OperationID: "listGuardians",
+		Summary:     "List a student's guardians",
+		Tags:        []string{"guardians"},
+		Parameters:  params[:1],
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": jsonResponse("The guardians.", envelope("guardians", arrayOf(guardian))),
+		}, http.StatusNotFound),
+	})
+
+	doc.Add(http.MethodGet, "/v1/students/{id}/guardians/{guardian_id}", &openapi.Operation{
+		OperationID: "showGuardian",
+		Summary:     "Show a guardian",
+		Tags:        []string{"guardians"},
+		Parameters:  params,
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": jsonResponse("The guardian.", envelope("guardian", guardian)),
+		}, http.StatusNotFound),
+	})
+
+	doc.Add(http.MethodPatch, "/v1/students/{id}/guardians/{guardian_id}", &openapi.Operation{
+		OperationID: "updateGuardian",
+		Summary:     "Update a guardian",
+		Tags:        []string{"guardians"},
+		Parameters:  params,
+		RequestBody: jsonBody(openapi.Ref("GuardianUpdate")),
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": jsonResponse("The updated guardian.", envelope("guardian", guardian)),
+		}, http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity),
+	})
+
+	doc.Add(http.MethodDelete, "/v1/students/{id}/guardians/{guardian_id}", &openapi.Operation{
+		OperationID: "deleteGuardian",
+		Summary:     "Remove a guardian",
+		Tags:        []string{"guardians"},
+		Parameters:  params,
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": jsonResponse("The guardian was removed.", openapi.Ref("Message")),
+		}, http.StatusNotFound),
+	})
+}
+
+func addCourseSpec(doc *openapi.Document) {
+	course := openapi.Ref("Course")
+	id := idParam("id", "Course ID")
+
+	doc.Add(http.MethodPost, "/v1/courses", &openapi.Operation{
+		OperationID: "createCourse",
+		Summary:     "Create a course",
+		Tags:        []string{"courses"},
+		RequestBody: jsonBody(openapi.Ref("CourseInput")),
+		Responses: withErrors(map[string]*openapi.Response{
+			"201": created("The new course.", envelope("course", course)),
+		}, http.StatusBadRequest, http.StatusUnprocessableEntity),
+	})
+
+	doc.Add(http.MethodGet, "/v1/courses", &openapi.Operation{
+		OperationID: "listCourses",
+		Summary:     "List courses",
+		Tags:        []string{"courses"},
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": jsonResponse("Every course.", envelope("courses", arrayOf(course))),
+		}),
+	})
+
+	doc.Add(http.MethodGet, "/v1/courses/{id}", &openapi.Operation{
+		OperationID: "showCourse",
+		Summary:     "Show a course",
+		Tags:        []string{"courses"},
+		Parameters:  []*openapi.Parameter{id},
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": jsonResponse("The course.", envelope("course", course)),
+		}, http.StatusNotFound),
+	})
+
+	doc.Add(http.MethodPatch, "/v1/courses/{id}", &openapi.Operation{
+		OperationID: "updateCourse",
+		Summary:     "Update a course",
+		Tags:        []string{"courses"},
+		Parameters:  []*openapi.Parameter{id},
+		RequestBody: jsonBody(openapi.Ref("CourseUpdate")),
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": jsonResponse("The updated course.", envelope("course", course)),
+		}, http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity),
+	})
+
+	doc.Add(http.MethodDelete, "/v1/courses/{id}", &openapi.Operation{
+		OperationID: "deleteCourse",
+		Summary:     "Delete a course",
+		Tags:        []string{"courses"},
+		Parameters:  []*openapi.Parameter{id},
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": jsonResponse("The course was deleted.", openapi.Ref("Message")),
+		}, http.StatusNotFound),
+	})
+}
+
+func addEnrollmentSpec(doc *openapi.Document) {
+	enrollment := openapi.Ref("Enrollment")
+
+	doc.Add(http.MethodGet, "/v1/students/{id}/courses", &openapi.Operation{
+		OperationID: "listStudentCourses",
+		Summary:     "List a student's courses",
+		Tags:        []string{"enrollments"},
+		Parameters:  []*openapi.Parameter{idParam("id", "Student ID")},
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": jsonResponse("The courses the student is enrolled or waitlisted in.", envelope("courses", arrayOf(openapi.Ref("StudentCourse")))),
+		}, http.StatusNotFound),
+	})
+
+	doc.Add(http.MethodPost, "/v1/courses/{id}/enrollments", &openapi.Operation{
+		OperationID: "createEnrollment",
+		Summary:     "Enroll a student in a course",
+		Description: "A student is waitlisted once the course is at capacity.",
+		Tags:        []string{"enrollments"},
+		Parameters:  []*openapi.Parameter{idParam("id", "Course ID")},
+		RequestBody: jsonBody(object(map[string]*openapi.Schema{
+			"student_id": {Type: "integer", Format: "int64", Minimum: ptr(1.0)},
+		}, "student_id")),
+		Responses: withErrors(map[string]*openapi.Response{
+			"201": jsonResponse("The enrollment.", envelope("enrollment", enrollment)),
+		}, http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity),
+	})
+
+	doc.Add(http.MethodGet, "/v1/courses/{id}/enrollments", &openapi.Operation{
+		OperationID: "listCourseEnrollments",
+		Summary:     "List a course's enrollments",
+		Tags:        []string{"enrollments"},
+		Parameters:  []*openapi.Parameter{idParam("id", "Course ID")},
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": jsonResponse("The enrollments, waitlist included.", envelope("enrollments", arrayOf(enrollment))),
+		}, http.StatusNotFound),
+	})
+
+	doc.Add(http.MethodDelete, "/v1/courses/{id}/enrollments/{student_id}", &openapi.Operation{
+		OperationID: "deleteEnrollment",
+		Summary:     "Drop a student from a course",
+		Tags:        []string{"enrollments"},
+		Parameters:  []*openapi.Parameter{idParam("id", "Course ID"), idParam("student_id", "Student ID")},
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": jsonResponse("The enrollment was dropped; promoted lists waitlisted students who took the seat.", object(map[string]*openapi.Schema{
+				"message":  {Type: "string"},
+				"promoted": arrayOf(enrollment),
+			}, "message", "promoted")),
+		}, http.StatusNotFound),
+	})
+}
+
+func addGradeSpec(doc *openapi.Document) {
+	doc.Add(http.MethodGet, "/v1/students/{id}/transcript", &openapi.Operation{
+		OperationID: "showTranscript",
+		Summary:     "Show a student's transcript and GPA",
+		Tags:        []string{"grades"},
+		Parameters:  []*openapi.Parameter{idParam("id", "Student ID")},
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": jsonResponse("The transcript, by term.", envelope("transcript", openapi.Ref("Transcript"))),
+		}, http.StatusNotFound),
+	})
+
+	grade := envelope("grade", openapi.Ref("Grade"))
+
+	doc.Add(http.MethodPut, "/v1/enrollments/{id}/grade", &openapi.Operation{
+		OperationID: "setGrade",
+		Summary:     "Set the grade for an enrollment",
+		Tags:        []string{"grades"},
+		Parameters:  []*openapi.Parameter{idParam("id", "Enrollment ID")},
+		RequestBody: jsonBody(object(map[string]*openapi.Schema{
+			"grade": {Type: "string", Description: "A grade on the configured grading scale."},
+		}, "grade")),
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": jsonResponse("The grade was replaced.", grade),
+			"201": jsonResponse("The grade was recorded.", grade),
+		}, http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity),
+	})
+}
+
+func addAttendanceSpec(doc *openapi.Document) {
+	date := &openapi.Schema{Type: "string", Format: "date"}
+
+	doc.Add(http.MethodGet, "/v1/students/{id}/attendance", &openapi.Operation{
+		OperationID: "listStudentAttendance",
+		Summary:     "Show a student's attendance",
+		Tags:        []string{"attendance"},
+		Parameters: []*openapi.Parameter{
+			idParam("id", "Student ID"),
+			{Name: "from", In: "query", Schema: date, Description: "First date to include."},
+			{Name: "to", In: "query", Schema: date, Description: "Last date to include."},
+		},
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": jsonResponse("The records with an overall and a per-course summary.", envelope("attendance", object(map[string]*openapi.Schema{
+				"records": arrayOf(openapi.Ref("AttendanceRecord")),
+				"summary": openapi.Ref("AttendanceSummary"),
+				"courses": arrayOf(openapi.Ref("CourseAttendance")),
+			}, "records", "summary", "courses"))),
+		}, http.StatusNotFound, http.StatusUnprocessableEntity),
+	})
+
+	doc.Add(http.MethodPost, "/v1/courses/{id}/attendance/{date}", &openapi.Operation{
+		OperationID: "markAttendance",
+		Summary:     "Mark attendance for a session",
+		Description: "Students are identified by roll number. alerts lists the students whose attendance fell below the threshold.",
+		Tags:        []string{"attendance"},
+		Parameters: []*openapi.Parameter{
+			idParam("id", "Course ID"),
+			{Name: "date", In: "path", Required: true, Schema: date},
+		},
+		RequestBody: jsonBody(object(map[string]*openapi.Schema{
+			"records": {Type: "array", MinItems: ptr(1), Items: object(map[string]*openapi.Schema{
+				"rollno": {Type: "integer", Format: "int32"},
+				"status": enum(data.AttendanceStatuses...),
+			}, "rollno", "status")},
+		}, "records")),
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": jsonResponse("The attendance was recorded.", object(map[string]*openapi.Schema{
+				"attendance": object(map[string]*openapi.Schema{
+					"course_id": {Type: "integer", Format: "int64"},
+					"date":      date,
+					"marked":    {Type: "integer"},
+				}, "course_id", "date", "marked"),
+				"alerts": arrayOf(openapi.Ref("AttendanceAlert")),
+			}, "attendance", "alerts")),
+		}, http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity),
+	})
+}
+
+func addWebhookSpec(doc *openapi.Document) {
+	webhook := openapi.Ref("Webhook")
+	id := idParam("id", "Webhook ID")
+
+	doc.Add(http.MethodPost, "/v1/webhooks", &openapi.Operation{
+		OperationID: "createWebhook",
+		Summary:     "Subscribe to student events",
+		Description: "The response is the only one that includes the webhook's signing secret.",
+		Tags:        []string{"webhooks"},
+		RequestBody: jsonBody(openapi.Ref("WebhookInput")),
+		Responses: withErrors(map[string]*openapi.Response{
+			"201": created("The new webhook.", envelope("webhook", webhook)),
+		}, http.StatusBadRequest, http.StatusUnprocessableEntity),
+	})
+
+	doc.Add(http.MethodGet, "/v1/webhooks", &openapi.Operation{
+		OperationID: "listWebhooks",
+		Summary:     "List webhooks",
+		Tags:        []string{"webhooks"},
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": jsonResponse("Every webhook.", envelope("webhooks", arrayOf(webhook))),
+		}),
+	})
+
+	doc.Add(http.MethodGet, "/v1/webhooks/{id}", &openapi.Operation{
+		OperationID: "showWebhook",
+		Summary:     "Show a webhook",
+		Tags:        []string{"webhooks"},
+		Parameters:  []*openapi.Parameter{id},
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": jsonResponse("The webhook.", envelope("webhook", webhook)),
+		}, http.StatusNotFound),
+	})
+
+	doc.Add(http.MethodPatch, "/v1/webhooks/{id}", &openapi.Operation{
+		OperationID: "updateWebhook",
+		Summary:     "Update a webhook",
+		Tags:        []string{"webhooks"},
+		Parameters:  []*openapi.Parameter{id},
+		RequestBody: jsonBody(openapi.Ref("WebhookUpdate")),
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": jsonResponse("The updated webhook.", envelope("webhook", webhook)),
+		}, http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity),
+	})
+
+	doc.Add(http.MethodDelete, "/v1/webhooks/{id}", &openapi.Operation{
+		OperationID: "deleteWebhook",
+		Summary:     "Delete a webhook",
+		Tags:        []string{"webhooks"},
+		Parameters:  []*openapi.Parameter{id},
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": jsonResponse("The webhook was deleted.", openapi.Ref("Message")),
+		}, http.StatusNotFound),
+	})
+
+	doc.Add(http.MethodGet, "/v1/webhooks/{id}/deliveries", &openapi.Operation{
+		OperationID: "listWebhookDeliveries",
+		Summary:     "Show a webhook's delivery log",
+		Tags:        []string{"webhooks"},
+		Parameters: []*openapi.Parameter{
+			id,
+			{Name: "limit", In: "query", Schema: &openapi.Schema{Type: "integer", Minimum: ptr(1.0), Maximum: ptr(1000.0)},
+				Description: "Maximum deliveries to return, newest first. Defaults to 100."},
+		},
+		Responses: withErrors(map[string]*openapi.Response{
+			"200": jsonResponse("The deliveries.", envelope("deliveries", arrayOf(openapi.Ref("WebhookDelivery")))),
+		}, http.StatusNotFound, http.StatusUnprocessableEntity),
+	})
+
+	doc.Add(http.MethodPost, "/v1/webhooks/{id}/deliveries/{delivery_id}/redeliver", &openapi.Operation{
+		OperationID: "redeliverWebhook",
+		Summary:     "Queue a delivery again",
+		Tags:        []string{"webhooks"},
+		Parameters:  []*openapi.Parameter{id, idParam("delivery_id", "Delivery ID")},
+		Responses: withErrors(map[string]*openapi.Response{
+			"202": jsonResponse("The delivery was queued with a fresh set of attempts.", envelope("delivery", openapi.Ref("WebhookDelivery"))),
+		}, http.StatusNotFound),
+	})
+}
+
+// === Helpers ===
+
+func ptr[T any](v T) *T { return &v }
+
+func object(props map[string]*openapi.Schema, required ...string) *openapi.Schema {
+	return &openapi.Schema{Type: "object", Properties: props, Required: required}
+}
+
+// envelope is the {"key": value} object most responses are wrapped in.
+func envelope(key string, s *openapi.Schema) *openapi.Schema {
+	return object(map[string]*openapi.Schema{key: s}, key)
+}
+
+// arrayOf is a list in a response body, which encoding/json writes as null when it is nil.
+func arrayOf(items *openapi.Schema) *openapi.Schema {
+	return &openapi.Schema{Type: []string{"array", "null"}, Items: items}
+}
+
+func enum(values ...string) *openapi.Schema {
+	s := &openapi.Schema{Type: "string"}
+	for _, v := range values {
+		s.Enum = append(s.Enum, v)
+	}
+	return s
+}
+
+func idParam(name, desc string) *openapi.Parameter {
+	return &openapi.Parameter{
+		Name:        name,
+		In:          "path",
+		Required:    true,
+		Description: desc,
+		Schema:      &openapi.Schema{Type: "integer", Format: "int64", Minimum: ptr(1.0)},
+	}
+}
+
+func jsonContent(s *openapi.Schema) map[string]*openapi.MediaType {
+	return map[string]*openapi.MediaType{"application/json": {Schema: s}}
+}
+
+func textContent(mediaType string) map[string]*openapi.MediaType {
+	return map[string]*openapi.MediaType{mediaType: {Schema: &openapi.Schema{Type: "string"}}}
+}
+
+func jsonBody(s *openapi.Schema) *openapi.RequestBody {
+	return &openapi.RequestBody{Required: true, Content: jsonContent(s)}
+}
+
+func jsonResponse(desc string, s *openapi.Schema) *openapi.Response {
+	return &openapi.Response{Description: desc, Content: jsonContent(s)}
+}
+
+func locationHeader() map[string]*openapi.Header {
+	return map[string]*openapi.Header{
+		"Location": {Description: "The URL of the new resource.", Schema: &openapi.Schema{Type: "string"}},
+	}
+}
+
+func created(desc string, s *openapi.Schema) *openapi.Response {
+	r := jsonResponse(desc, s)
+	r.Headers = locationHeader()
+	return r
+}
+
+// withErrors adds the shared error responses for the given status codes, plus 500, which
+// any operation may return.
+func withErrors(responses map[string]*openapi.Response, statuses ...int) map[string]*openapi.Response {
+	for _, status := range append(statuses, http.StatusInternalServerError) {
+		responses[strconv.Itoa(status)] = &openapi.Response{Ref: "#/components/responses/" + errorResponseNames[status]}
+	}
+	return responses
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sai29/one2n_sre_bootcamp/internal/openapi"
+)
+
+func TestOpenAPICoversRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := newFullTestApp(&mockStudentModel{}).routes().(*gin.Engine)
+	spec := openAPISpec()
+
+	documented := 0
+	for _, route := range router.Routes() {
+		// Swagger UI's files are not part of the API.
+		if strings.HasPrefix(route.Path, "/docs/") {
+			continue
+		}
+
+		path := openapi.PathFromGin(route.Path)
+		if spec.Operation(route.Method, path) == nil {
+			t.Errorf("%s %s is routed but missing from the OpenAPI spec", route.Method, path)
+		}
+		documented++
+	}
+
+	operations := 0
+	for _, item := range spec.Paths {
+		operations += len(item)
+	}
+
+	if operations != documented {
+		t.Errorf("spec has %d operations but the router has %d routes", operations, documented)
+	}
+}
+
+func TestOpenAPIOperations(t *testing.T) {
+	spec := openAPISpec()
+	ids := map[string]string{}
+
+	for path, item := range spec.Paths {
+		for method, op := range item {
+			where := strings.ToUpper(method) + " " + path
+
+			if op.OperationID == "" {
+				t.Errorf("%s has no operationId", where)
+			} else if other, ok := ids[op.OperationID]; ok {
+				t.Errorf("%s and %s share the operationId %q", where, other, op.OperationID)
+			}
+			ids[op.OperationID] = where
+
+			for _, p := range op.Parameters {
+				if p.In == "path" && !strings.Contains(path, "{"+p.Name+"}") {
+					t.Errorf("%s declares path parameter %q that is not in the path", where, p.Name)
+				}
+			}
+
+			for code, r := range op.Responses {
+				name, ok := strings.CutPrefix(r.Ref, "#/components/responses/")
+				if r.Ref != "" && (!ok || spec.Components.Responses[name] == nil) {
+					t.Errorf("%s response %s refers to unknown %q", where, code, r.Ref)
+				}
+			}
+		}
+	}
+}
+
+func TestOpenAPIHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := newFullTestApp(&mockStudentModel{}).routes()
+
+	w := performRequest(router, http.MethodGet, "/v1/openapi.json", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	var doc struct {
+		OpenAPI    string `json:"openapi"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.OpenAPI != "3.1.0" {
+		t.Errorf("expected openapi 3.1.0, got %q", doc.OpenAPI)
+	}
+
+	for _, name := range []string{"Student", "Error"} {
+		if doc.Components.Schemas[name] == nil {
+			t.Errorf("expected a %s schema", name)
+		}
+	}
+
+	w = performRequest(router, http.MethodGet, "/docs/swagger-initializer.js", nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "/v1/openapi.json") {
+		t.Errorf("expected the docs to load /v1/openapi.json, got %d %q", w.Code, w.Body.String())
+	}
+
+	w = performRequest(router, http.MethodGet, "/docs/", nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "swagger-ui") {
+		t.Errorf("expected the Swagger UI page, got %d", w.Code)
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/outbox"
+	"github.com/sai29/one2n_sre_bootcamp/internal/webhook"
+)
+
+const (
+	outboxSinkLog     = "log"
+	outboxSinkWebhook = "webhook"
+	outboxSinkNATS    = "nats"
+)
+
+// newOutboxRelay builds the relay for the configured sinks. The returned close function
+// releases sink connections and must only be called once the relay has stopped.
+func (app *application) newOutboxRelay() (*outbox.Relay, func(), error) {
+	var (
+		sinks   []outbox.Sink
+		closers []func()
+	)
+
+	closeAll := func() {
+		for _, fn := range closers {
+			fn()
+		}
+	}
+
+	for _, name := range app.config.Outbox.Sinks {
+		var sink outbox.Sink
+
+		switch name {
+		case outboxSinkLog:
+			sink = outbox.LogSink{Logger: app.logger}
+
+		case outboxSinkWebhook:
+			if app.config.Outbox.WebhookURL == "" {
+				closeAll()
+				return nil, nil, errors.New("-outbox-webhook-url must be set for the webhook sink")
+			}
+
+			sink = outbox.WebhookSink{
+				URL:    app.config.Outbox.WebhookURL,
+				Client: &http.Client{Timeout: 5 * time.Second},
+			}
+
+		case outboxSinkNATS:
+			if app.config.Outbox.NATSURL == "" {
+				closeAll()
+				return nil, nil, errors.New("NATS_URL or -outbox-nats-url must be set for the nats sink")
+			}
+
+			nc, err := nats.Connect(app.config.Outbox.NATSURL, nats.Name("students_api outbox"))
+			if err != nil {
+				closeAll()
+				return nil, nil, err
+			}
+			closers = append(closers, func() { _ = nc.Drain() })
+
+			sink = outbox.BrokerSink{Broker: nc, Prefix: "students_api."}
+
+		default:
+			closeAll()
+			return nil, nil, fmt.Errorf("invalid outbox sink %q", name)
+		}
+
+		sinks = append(sinks, meteredSink{sink})
+	}
+
+	// Registered webhooks are fed from the outbox whatever -outbox-sinks says. Event
+	// streams follow the outbox on their own; see startStreamTail.
+	if app.models.Webhooks != nil {
+		sinks = append(sinks, meteredSink{webhook.Sink{Queue: app.models.Webhooks}})
+	}
+
+	relay := &outbox.Relay{
+		Store:     app.models.Outbox,
+		Sinks:     sinks,
+		BatchSize: app.config.Outbox.BatchSize,
+		Interval:  app.config.Outbox.Interval,
+		Retention: app.config.Outbox.Retention,
+		Logger:    app.logger,
+	}
+
+	return relay, closeAll, nil
+}
+
+// startOutboxRelay runs the relay as a background task so that shutdown waits for it to
+// drain the events written by the last requests. Backends without an outbox skip it.
+func (app *application) startOutboxRelay() error {
+	if app.models.Outbox == nil {
+		return nil
+	}
+
+	relay, closeSinks, err := app.newOutboxRelay()
+	if err != nil {
+		return err
+	}
+
+	if len(relay.Sinks) == 0 {
+		closeSinks()
+		return nil
+	}
+
+	names := make([]string, len(relay.Sinks))
+	for i, sink := range relay.Sinks {
+		names[i] = sink.Name()
+	}
+
+	app.background(func() {
+		defer closeSinks()
+		relay.Run(app.shutdown, 5*time.Second)
+	})
+
+	app.logger.PrintInfo("outbox relay started", map[string]string{
+		"sinks":    strings.Join(names, ","),
+		"interval": app.config.Outbox.Interval.String(),
+	})
+
+	return nil
+}
+
+// startStreamTail feeds app.streams from committed outbox events with a cursor of its
+// own, so a relay sink that keeps failing doesn't stall the streams or make the relay
+// resend to them. Each instance follows the whole outbox, so every instance streams every
+// change, whichever instance relays it.
+func (app *application) startStreamTail() {
+	if app.streams == nil || app.models.Outbox == nil {
+		return
+	}
+
+	tail := &outbox.Tail{
+		Store:     app.models.Outbox,
+		Sink:      meteredSink{app.streams},
+		BatchSize: app.config.Outbox.BatchSize,
+		Interval:  app.config.Outbox.Interval,
+		Logger:    app.logger,
+	}
+
+	app.background(func() {
+		tail.Run(app.shutdown)
+	})
+}
+
+// meteredSink counts deliveries and failures per sink.
+type meteredSink struct {
+	outbox.Sink
+}
+
+func (s meteredSink) Send(ctx context.Context, event *data.OutboxEvent) error {
+	if err := s.Sink.Send(ctx, event); err != nil {
+		outboxFailuresTotal.WithLabelValues(s.Name()).Inc()
+		return err
+	}
+
+	outboxPublishedTotal.WithLabelValues(s.Name()).Inc()
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimiter keeps a token bucket per client IP. The rate and burst are passed in on
+// every call rather than stored, so a reload applies to existing clients straight away.
+type rateLimiter struct {
+	mu      sync.Mutex
+	clients map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{clients: make(map[string]*bucket)}
+}
+
+// allow reports whether the client may make a request at now, taking a token if so.
+func (l *rateLimiter) allow(client string, rps float64, burst int, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.clients[client]
+	if !ok {
+		b = &bucket{tokens: float64(burst), last: now}
+		l.clients[client] = b
+	}
+
+	b.tokens = min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*rps)
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
+
+// forget drops the clients that have not made a request since before.
+func (l *rateLimiter) forget(before time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for client, b := range l.clients {
+		if b.last.Before(before) {
+			delete(l.clients, client)
+		}
+	}
+}
+
+// unlimitedRoutes are the probe and metrics routes. Kubelet and Prometheus call them on
+// a schedule of their own, often from one address, and must never be turned away.
+var unlimitedRoutes = map[string]bool{
+	"/metrics":        true,
+	"/v1/healthcheck": true,
+	"/v1/readiness":   true,
+}
+
+// rateLimit rejects requests with a 429 once a client IP exceeds limiter.rps, allowing
+// bursts of up to limiter.burst. It does nothing while limiter.enabled is false, and
+// nothing for unlimitedRoutes. Clients are told apart by the connection's remote address:
+// X-Forwarded-For can be set by anyone, so behind a proxy every request shares the
+// proxy's limit.
+func (app *application) rateLimit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limits := app.liveConfig().Limiter
+
+		if limits.Enabled && !unlimitedRoutes[c.FullPath()] && !app.limiter.allow(c.RemoteIP(), limits.RPS, limits.Burst, time.Now()) {
+			app.rateLimitExceededResponse(c)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// sweepRateLimiter forgets idle clients every minute until the server shuts down.
+func (app *application) sweepRateLimiter() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-app.shutdown:
+			return
+		case now := <-ticker.C:
+			app.limiter.forget(now.Add(-3 * time.Minute))
+		}
+	}
+}
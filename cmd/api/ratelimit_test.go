@@ -0,0 +1,79 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	app := newTestApp(&mockStudentModel{getFn: func(id int64) (*data.Student, error) {
+		return &data.Student{ID: id, Name: "Ada"}, nil
+	}})
+	app.limiter = newRateLimiter()
+	app.config.Limiter.Enabled = true
+	app.config.Limiter.RPS = 1
+	app.config.Limiter.Burst = 2
+
+	r := app.routes()
+
+	for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		if w := performRequest(r, http.MethodGet, "/v1/students/1", nil); w.Code != want {
+			t.Errorf("request %d: got status %d, want %d", i+1, w.Code, want)
+		}
+	}
+
+	// A forwarded-for header does not give the same connection a new limit.
+	req := httptest.NewRequest(http.MethodGet, "/v1/students/1", nil)
+	req.Header.Set("X-Forwarded-For", "203.0.113.9")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("got status %d with X-Forwarded-For, want %d", w.Code, http.StatusTooManyRequests)
+	}
+
+	// Probes and metrics scrapes from the same address are never limited.
+	for _, path := range []string{"/v1/healthcheck", "/v1/readiness", "/metrics"} {
+		if w := performRequest(r, http.MethodGet, path, nil); w.Code != http.StatusOK {
+			t.Errorf("%s: got status %d over the limit, want %d", path, w.Code, http.StatusOK)
+		}
+	}
+
+	// Turning the limiter off applies to the next request.
+	cfg := app.config
+	cfg.Limiter.Enabled = false
+	app.live.Store(&cfg)
+
+	if w := performRequest(r, http.MethodGet, "/v1/students/1", nil); w.Code != http.StatusOK {
+		t.Errorf("got status %d with the limiter disabled", w.Code)
+	}
+}
+
+func TestRateLimiterRefills(t *testing.T) {
+	l := newRateLimiter()
+	now := time.Now()
+
+	if !l.allow("a", 2, 1, now) || l.allow("a", 2, 1, now) {
+		t.Fatal("a burst of 1 should allow exactly one request")
+	}
+	if !l.allow("b", 2, 1, now) {
+		t.Fatal("clients should have separate buckets")
+	}
+	if !l.allow("a", 2, 1, now.Add(500*time.Millisecond)) {
+		t.Fatal("the bucket should refill at rps")
+	}
+
+	l.forget(now.Add(time.Millisecond))
+	if _, ok := l.clients["b"]; ok {
+		t.Error("idle client was not forgotten")
+	}
+	if _, ok := l.clients["a"]; !ok {
+		t.Error("active client was forgotten")
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/jsonlog"
+	"github.com/sai29/one2n_sre_bootcamp/internal/settings"
+)
+
+// reloadable lists the settings a SIGHUP applies to the running server; a key ending in
+// "." covers its whole group. Everything else is only read at startup, so changing it
+// needs a restart. The server has no feature flags; contract.mode is the only runtime
+// switch.
+var reloadable = []string{
+	"log.level",
+	"cors.",
+	"limiter.",
+	"db.max_open_conns",
+	"db.max_idle_conns",
+	"contract.mode",
+}
+
+func isReloadable(key string) bool {
+	for _, r := range reloadable {
+		if key == r || (strings.HasSuffix(r, ".") && strings.HasPrefix(key, r)) {
+			return true
+		}
+	}
+	return false
+}
+
+// liveConfig returns the configuration as of the last reload.
+func (app *application) liveConfig() *config {
+	if cfg := app.live.Load(); cfg != nil {
+		return cfg
+	}
+	return &app.config
+}
+
+// reloadConfig loads the configuration again from the same command line, config file and
+// environment as at startup, and applies the reloadable settings. The new configuration
+// is validated as a whole first, and nothing is applied if it is invalid. Changes to
+// settings that need a restart are rejected: they are logged and ignored, and the reload
+// is counted as a failure even though the reloadable settings were applied. Every attempt
+// is counted in config_reloads_total.
+func (app *application) reloadConfig() error {
+	ignored, err := app.applyReload()
+
+	result := "success"
+	if err != nil || len(ignored) > 0 {
+		result = "failure"
+	}
+	configReloadsTotal.WithLabelValues(result).Inc()
+
+	return err
+}
+
+// applyReload applies the reloadable settings and returns the changed settings it
+// ignored because they need a restart.
+func (app *application) applyReload() ([]string, error) {
+	next, _, err := loadConfig(app.args, os.LookupEnv, io.Discard)
+	if err != nil {
+		err = fmt.Errorf("reloading configuration: %w", err)
+		app.logger.PrintError(err, nil)
+		return nil, err
+	}
+
+	if problems := next.validate(); len(problems) > 0 {
+		err := errors.New("reloading configuration: invalid configuration")
+		app.logger.PrintError(err, problems)
+		return nil, err
+	}
+
+	current := app.liveConfig()
+
+	var applied, ignored []string
+	for _, key := range settings.Changed(current, &next) {
+		if isReloadable(key) {
+			applied = append(applied, key)
+		} else {
+			ignored = append(ignored, key)
+		}
+	}
+
+	updated := *current
+	updated.Log = next.Log
+	updated.CORS = next.CORS
+	updated.Limiter = next.Limiter
+	updated.DB.MaxOpenConns = next.DB.MaxOpenConns
+	updated.DB.MaxIdleConns = next.DB.MaxIdleConns
+	updated.Contract = next.Contract
+
+	// validate has already checked the level.
+	level, _ := jsonlog.ParseLevel(updated.Log.Level)
+	app.logger.SetLevel(level)
+
+	for _, db := range app.pools {
+		db.SetMaxOpenConns(updated.DB.MaxOpenConns)
+		db.SetMaxIdleConns(updated.DB.MaxIdleConns)
+	}
+
+	app.live.Store(&updated)
+
+	message := "configuration reloaded"
+	properties := map[string]string{"changed": strings.Join(applied, ", ")}
+	if len(ignored) > 0 {
+		message = "configuration reloaded; ignoring changes that need a restart"
+		properties["ignored"] = strings.Join(ignored, ", ")
+	}
+
+	app.logger.PrintInfo(message, properties)
+
+	return ignored, nil
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"database/sql"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sai29/one2n_sre_bootcamp/internal/jsonlog"
+)
+
+// newReloadTestApp is newTestApp configured from the config file at path, the way main
+// does, with an unopened Postgres pool for reloads to resize.
+func newReloadTestApp(t *testing.T, path string) *application {
+	t.Helper()
+
+	args := []string{"-config", path}
+
+	cfg, _, err := loadConfig(args, os.LookupEnv, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := sql.Open("postgres", "postgres://localhost/students")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	app := newTestApp(&mockStudentModel{})
+	app.config = cfg
+	app.args = args
+	app.pools = []*sql.DB{db}
+
+	live := cfg
+	app.live.Store(&live)
+
+	return app
+}
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, `
+port: 4000
+db:
+  driver: memory
+  max_open_conns: 10
+`)
+
+	app := newReloadTestApp(t, path)
+
+	writeConfig(t, path, `
+port: 5000
+log:
+  level: error
+db:
+  driver: memory
+  max_open_conns: 40
+  query_timeout: 1s
+cors:
+  trusted_origins: [https://example.com]
+limiter:
+  enabled: true
+  rps: 5
+contract:
+  mode: log
+`)
+
+	// The port and query timeout need a restart, so the reload counts as a failure.
+	before := testutil.ToFloat64(configReloadsTotal.WithLabelValues("failure"))
+
+	if err := app.reloadConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := testutil.ToFloat64(configReloadsTotal.WithLabelValues("failure")); got != before+1 {
+		t.Errorf("config_reloads_total{result=failure} = %v, want %v", got, before+1)
+	}
+
+	live := app.liveConfig()
+
+	if app.logger.Level() != jsonlog.LevelError {
+		t.Errorf("log level is %s, want ERROR", app.logger.Level())
+	}
+	if live.DB.MaxOpenConns != 40 || app.pools[0].Stats().MaxOpenConnections != 40 {
+		t.Errorf("max open conns: config %d, pool %d, want 40", live.DB.MaxOpenConns, app.pools[0].Stats().MaxOpenConnections)
+	}
+	if !slices.Equal(live.CORS.TrustedOrigins, []string{"https://example.com"}) {
+		t.Errorf("trusted origins are %q", live.CORS.TrustedOrigins)
+	}
+	if !live.Limiter.Enabled || live.Limiter.RPS != 5 {
+		t.Errorf("limiter is %+v", live.Limiter)
+	}
+	if live.Contract.Mode != contractLog {
+		t.Errorf("contract mode is %q", live.Contract.Mode)
+	}
+
+	// Settings that need a restart keep their startup values.
+	if live.Port != 4000 || live.DB.QueryTimeout != 3*time.Second {
+		t.Errorf("restart-only settings changed: port %d, query timeout %s", live.Port, live.DB.QueryTimeout)
+	}
+}
+
+func TestReloadConfigOnlyReloadable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, "db:\n  driver: memory\n")
+
+	app := newReloadTestApp(t, path)
+
+	writeConfig(t, path, "db:\n  driver: memory\nlog:\n  level: error\n")
+
+	before := testutil.ToFloat64(configReloadsTotal.WithLabelValues("success"))
+
+	if err := app.reloadConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := testutil.ToFloat64(configReloadsTotal.WithLabelValues("success")); got != before+1 {
+		t.Errorf("config_reloads_total{result=success} = %v, want %v", got, before+1)
+	}
+}
+
+func TestReloadConfigRejectsInvalidConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, "db:\n  driver: memory\n")
+
+	app := newReloadTestApp(t, path)
+	previous := app.liveConfig()
+
+	for _, content := range []string{
+		"db:\n  driver: memory\n  max_open_conns: -1\n",
+		"db:\n  driver: memory\nlimiter:\n  rps: 0\n",
+		"db:\n  driver: memory\nlog:\n  level: loud\n",
+		"db: [\n",
+	} {
+		writeConfig(t, path, content)
+
+		before := testutil.ToFloat64(configReloadsTotal.WithLabelValues("failure"))
+
+		if err := app.reloadConfig(); err == nil {
+			t.Errorf("reload of %q succeeded", content)
+		}
+
+		if got := testutil.ToFloat64(configReloadsTotal.WithLabelValues("failure")); got != before+1 {
+			t.Errorf("config_reloads_total{result=failure} = %v, want %v", got, before+1)
+		}
+
+		if app.liveConfig() != previous {
+			t.Errorf("reload of %q changed the live configuration", content)
+		}
+	}
+}
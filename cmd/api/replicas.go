@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+)
+
+// readPinCookie holds the Unix time in milliseconds until which a client's reads are served
+// by the primary, so it sees its own writes despite replication lag.
+const readPinCookie = "read_primary_until"
+
+// readModels returns the models a read-only handler should query. Reads go to a healthy
+// replica in round-robin order and fall back to the primary when no replica is configured
+// or healthy, or when the client has written recently.
+func (app *application) readModels(c *gin.Context) data.Models {
+	if app.replicas == nil {
+		return app.models
+	}
+
+	if app.pinnedToPrimary(c) {
+		dbReadsTotal.WithLabelValues("primary").Inc()
+		return app.models
+	}
+
+	return app.replicaOrPrimary()
+}
+
+// replicaOrPrimary picks a healthy replica, or the primary when there is none. gRPC reads
+// use it directly since they carry no read pin.
+func (app *application) replicaOrPrimary() data.Models {
+	if app.replicas == nil {
+		return app.models
+	}
+
+	i, ok := app.replicas.Pick()
+	if !ok {
+		dbReadsTotal.WithLabelValues("primary").Inc()
+		return app.models
+	}
+
+	dbReadsTotal.WithLabelValues(app.replicas.Name(i)).Inc()
+	return app.replicaModels[i]
+}
+
+func (app *application) pinnedToPrimary(c *gin.Context) bool {
+	value, err := c.Cookie(readPinCookie)
+	if err != nil {
+		return false
+	}
+
+	until, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	return time.Now().UnixMilli() < until
+}
+
+// skipReadPinKey marks a request that changed nothing even though its method may modify
+// data, such as a GraphQL query sent with POST.
+const skipReadPinKey = "skip_read_pin"
+
+// skipReadPin stops readYourWrites from pinning the client for this request.
+func skipReadPin(c *gin.Context) {
+	c.Set(skipReadPinKey, true)
+}
+
+// readYourWrites pins the client to the primary after a successful request that may
+// modify data. Headers can't be changed once the body is written, so the cookie is added
+// by pinWriter just before they go out, when the status is known.
+func (app *application) readYourWrites() gin.HandlerFunc {
+	window := app.config.DB.Replicas.ReadYourWrites
+
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		if window <= 0 {
+			c.Next()
+			return
+		}
+
+		w := &pinWriter{ResponseWriter: c.Writer, c: c, window: window}
+		c.Writer = w
+
+		c.Next()
+
+		// Nothing was written, so gin sends the headers after this returns.
+		if !w.Written() {
+			w.pin()
+		}
+	}
+}
+
+// pinWriter sets the pin cookie on a successful response the first time the headers are
+// about to be sent.
+type pinWriter struct {
+	gin.ResponseWriter
+	c      *gin.Context
+	window time.Duration
+	done   bool
+}
+
+func (w *pinWriter) pin() {
+	if w.done {
+		return
+	}
+	w.done = true
+
+	if w.Status() >= http.StatusBadRequest || w.c.GetBool(skipReadPinKey) {
+		return
+	}
+
+	http.SetCookie(w.ResponseWriter, &http.Cookie{
+		Name:     readPinCookie,
+		Value:    strconv.FormatInt(time.Now().Add(w.window).UnixMilli(), 10),
+		Path:     "/",
+		MaxAge:   int(math.Ceil(w.window.Seconds())),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (w *pinWriter) WriteHeaderNow() {
+	w.pin()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *pinWriter) Write(b []byte) (int, error) {
+	w.pin()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *pinWriter) WriteString(s string) (int, error) {
+	w.pin()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *pinWriter) Flush() {
+	w.pin()
+	w.ResponseWriter.Flush()
+}
+
+// monitorReplicas health checks the replicas until the server shuts down.
+func (app *application) monitorReplicas() {
+	ticker := time.NewTicker(app.config.DB.Replicas.CheckInterval)
+	defer ticker.Stop()
+
+	check := func() {
+		app.replicas.Check(context.Background(), 2*time.Second)
+
+		for i := 0; i < app.replicas.Len(); i++ {
+			healthy := 0.0
+			if app.replicas.Healthy(i) {
+				healthy = 1
+			}
+			dbReplicaHealthy.WithLabelValues(app.replicas.Name(i)).Set(healthy)
+		}
+	}
+
+	check()
+
+	for {
+		select {
+		case <-app.shutdown:
+			return
+		case <-ticker.C:
+			check()
+		}
+	}
+}
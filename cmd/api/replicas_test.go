@@ -0,0 +1,99 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/graph"
+	"github.com/sai29/one2n_sre_bootcamp/internal/replica"
+)
+
+func TestReadYourWrites(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// The replica store never receives writes, standing in for a replica that lags.
+	app := newTestApp(nil)
+	app.models = data.Models{Students: data.NewMemoryStudentModel()}
+	app.replicas = replica.New([]string{"replica-1"}, []*sql.DB{nil})
+	app.replicaModels = []data.Models{{Students: data.NewMemoryStudentModel()}}
+	app.config.DB.Replicas.ReadYourWrites = time.Minute
+
+	router := app.routes()
+
+	w := performRequest(router, "POST", "/v1/students", []byte(`{"name":"Asha","rollno":7}`))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected %d, got %d", http.StatusCreated, w.Code)
+	}
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != readPinCookie {
+		t.Fatalf("expected a %s cookie, got %v", readPinCookie, cookies)
+	}
+
+	w = performRequest(router, "GET", "/v1/students/1", nil)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected an unpinned read to go to the replica (404), got %d", w.Code)
+	}
+
+	req := httptest.NewRequest("GET", "/v1/students/1", nil)
+	req.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected a pinned read to go to the primary (200), got %d", w.Code)
+	}
+
+	expired := &http.Cookie{Name: readPinCookie, Value: "1"}
+	req = httptest.NewRequest("GET", "/v1/students/1", nil)
+	req.AddCookie(expired)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected an expired pin to read from the replica (404), got %d", w.Code)
+	}
+}
+
+func TestReadYourWrites_OnlyPinsSuccessfulMutations(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	app := newTestApp(nil)
+	app.models = data.Models{Students: data.NewMemoryStudentModel()}
+	app.replicas = replica.New([]string{"replica-1"}, []*sql.DB{nil})
+	app.replicaModels = []data.Models{{Students: data.NewMemoryStudentModel()}}
+	app.config.DB.Replicas.ReadYourWrites = time.Minute
+
+	var err error
+	app.graph, err = graph.New(graph.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := app.routes()
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		pinned bool
+	}{
+		{"failed create", "/v1/students", `{"name":"","rollno":7}`, false},
+		{"graphql query", "/v1/graphql", `{"query":"{ students { id } }"}`, false},
+		{"graphql mutation", "/v1/graphql", `{"query":"mutation { createStudent(input: {name: \"Asha\", rollno: 7}) { id } }"}`, true},
+		{"create", "/v1/students", `{"name":"Asha","rollno":8}`, true},
+	}
+
+	for _, tt := range tests {
+		w := performRequest(router, "POST", tt.path, []byte(tt.body))
+
+		if pinned := len(w.Result().Cookies()) > 0; pinned != tt.pinned {
+			t.Errorf("%s: got pinned %v, want %v (status %d: %s)", tt.name, pinned, tt.pinned, w.Code, w.Body.String())
+		}
+	}
+}
@@ -13,6 +13,19 @@ func (app *application) routes() http.Handler {
 	r.Use(app.recoverPanic())
 	r.Use(app.requestLogger())
 	r.Use(prometheusMiddleware())
+	r.Use(app.enableCORS())
+
+	if app.limiter != nil {
+		r.Use(app.rateLimit())
+	}
+
+	if app.contract != nil {
+		r.Use(app.validateContract())
+	}
+
+	if app.replicas != nil {
+		r.Use(app.readYourWrites())
+	}
 
 	r.NoRoute(func(c *gin.Context) {
 		app.notFoundResponse(c)
@@ -24,14 +37,76 @@ func (app *application) routes() http.Handler {
 
 	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
 	r.GET("/v1/healthcheck", app.healthCheckHandler)
+	r.GET("/v1/readiness", app.readinessHandler)
+	r.GET("/v1/openapi.json", app.openAPIHandler)
+	r.GET("/docs/*filepath", app.docsHandler)
 
 	v1 := r.Group("/v1")
 	{
 		v1.POST("/students", app.createStudentHandler)
 		v1.GET("/students", app.listStudentsHandler)
+		v1.GET("/students/export", app.exportStudentsHandler)
+		v1.POST("/students/import", app.importStudentsHandler)
+		v1.GET("/students/import/:job_id", app.showImportJobHandler)
 		v1.GET("/students/:id", app.showStudentHandler)
 		v1.PATCH("/students/:id", app.updateStudentHandler)
 		v1.DELETE("/students/:id", app.deleteStudentHandler)
+
+		v1.POST("/graphql", app.graphqlHandler)
+	}
+
+	// Only the Postgres backend provides the stores below, so their routes are left out
+	// when the API runs on the SQLite or in-memory student store.
+	if app.streams != nil {
+		v1.GET("/students/events", app.studentEventsHandler)
+	}
+
+	if app.models.Transitions != nil {
+		v1.POST("/students/:id/transitions", app.createTransitionHandler)
+		v1.GET("/students/:id/transitions", app.listTransitionsHandler)
+	}
+
+	if app.models.Guardians != nil {
+		v1.POST("/students/:id/guardians", app.createGuardianHandler)
+		v1.GET("/students/:id/guardians", app.listGuardiansHandler)
+		v1.GET("/students/:id/guardians/:guardian_id", app.showGuardianHandler)
+		v1.PATCH("/students/:id/guardians/:guardian_id", app.updateGuardianHandler)
+		v1.DELETE("/students/:id/guardians/:guardian_id", app.deleteGuardianHandler)
+	}
+
+	if app.models.Courses != nil {
+		v1.POST("/courses", app.createCourseHandler)
+		v1.GET("/courses", app.listCoursesHandler)
+		v1.GET("/courses/:id", app.showCourseHandler)
+		v1.PATCH("/courses/:id", app.updateCourseHandler)
+		v1.DELETE("/courses/:id", app.deleteCourseHandler)
+	}
+
+	if app.models.Enrollments != nil {
+		v1.GET("/students/:id/courses", app.listStudentCoursesHandler)
+		v1.POST("/courses/:id/enrollments", app.createEnrollmentHandler)
+		v1.GET("/courses/:id/enrollments", app.listCourseEnrollmentsHandler)
+		v1.DELETE("/courses/:id/enrollments/:student_id", app.deleteEnrollmentHandler)
+	}
+
+	if app.models.Grades != nil {
+		v1.GET("/students/:id/transcript", app.showTranscriptHandler)
+		v1.PUT("/enrollments/:id/grade", app.setGradeHandler)
+	}
+
+	if app.models.Attendance != nil {
+		v1.GET("/students/:id/attendance", app.listStudentAttendanceHandler)
+		v1.POST("/courses/:id/attendance/:date", app.markAttendanceHandler)
+	}
+
+	if app.models.Webhooks != nil {
+		v1.POST("/webhooks", app.createWebhookHandler)
+		v1.GET("/webhooks", app.listWebhooksHandler)
+		v1.GET("/webhooks/:id", app.showWebhookHandler)
+		v1.PATCH("/webhooks/:id", app.updateWebhookHandler)
+		v1.DELETE("/webhooks/:id", app.deleteWebhookHandler)
+		v1.GET("/webhooks/:id/deliveries", app.listWebhookDeliveriesHandler)
+		v1.POST("/webhooks/:id/deliveries/:delivery_id/redeliver", app.redeliverWebhookHandler)
 	}
 
 	return r
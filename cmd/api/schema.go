@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	schemaModeStrict   = "strict"
+	schemaModeDegraded = "degraded"
+)
+
+// schemaRecheckInterval is how long a good schema status is reused by the readiness
+// endpoint before the version is read again.
+const schemaRecheckInterval = 10 * time.Second
+
+// schemaCheck compares the version recorded in schema_migrations with the newest migration
+// embedded in the binary. A database that is ahead of the binary is accepted so an older
+// build can keep serving during a rollback.
+type schemaCheck struct {
+	version  func(ctx context.Context) (int64, bool, error)
+	expected int64
+
+	mu      sync.Mutex
+	status  schemaStatus
+	checked time.Time
+}
+
+type schemaStatus struct {
+	Version  int64  `json:"version"`
+	Expected int64  `json:"expected"`
+	Dirty    bool   `json:"dirty"`
+	State    string `json:"state"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ok reports whether the schema is safe to serve requests against.
+func (s schemaStatus) ok() bool {
+	return s.State == "current" || s.State == "ahead"
+}
+
+func (s schemaStatus) err() error {
+	switch s.State {
+	case "dirty":
+		return fmt.Errorf("database schema is dirty at version %d", s.Version)
+	case "behind":
+		return fmt.Errorf("database schema is at version %d, this build expects %d", s.Version, s.Expected)
+	case "unknown":
+		return fmt.Errorf("reading database schema version: %s", s.Error)
+	}
+	return nil
+}
+
+// refresh reads the current version, records the result and updates the schema_version gauge.
+func (sc *schemaCheck) refresh(ctx context.Context) schemaStatus {
+	version, dirty, err := sc.version(ctx)
+
+	status := schemaStatus{Version: version, Expected: sc.expected, Dirty: dirty}
+
+	switch {
+	case err != nil:
+		status.State = "unknown"
+		status.Error = err.Error()
+	case dirty:
+		status.State = "dirty"
+	case version < sc.expected:
+		status.State = "behind"
+	case version > sc.expected:
+		status.State = "ahead"
+	default:
+		status.State = "current"
+	}
+
+	if err == nil {
+		schemaVersion.Set(float64(version))
+	}
+
+	sc.mu.Lock()
+	sc.status = status
+	sc.checked = time.Now()
+	sc.mu.Unlock()
+
+	return status
+}
+
+// current returns the last recorded status. A good status is reused for
+// schemaRecheckInterval, so probes don't query the database every time but a schema that
+// goes dirty or is rolled back is still noticed. Until the schema is good every call
+// re-reads it, so a degraded instance becomes ready as soon as the migration job has run.
+func (sc *schemaCheck) current(ctx context.Context) schemaStatus {
+	sc.mu.Lock()
+	status, checked := sc.status, sc.checked
+	sc.mu.Unlock()
+
+	if status.ok() && time.Since(checked) < schemaRecheckInterval {
+		return status
+	}
+
+	return sc.refresh(ctx)
+}
+
+func (app *application) readinessHandler(c *gin.Context) {
+	if app.schema == nil {
+		c.JSON(http.StatusOK, gin.H{"status": "ready"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), app.config.DB.QueryTimeout)
+	defer cancel()
+
+	schema := app.schema.current(ctx)
+
+	if !schema.ok() {
+		c.Header("Retry-After", "5")
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status": "degraded",
+			"schema": schema,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": "ready",
+		"schema": schema,
+	})
+}
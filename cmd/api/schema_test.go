@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSchemaCheckStates(t *testing.T) {
+	tests := []struct {
+		name    string
+		version int64
+		dirty   bool
+		err     error
+		state   string
+		ok      bool
+	}{
+		{name: "current", version: 8, state: "current", ok: true},
+		{name: "ahead", version: 9, state: "ahead", ok: true},
+		{name: "behind", version: 7, state: "behind"},
+		{name: "dirty", version: 8, dirty: true, state: "dirty"},
+		{name: "unreachable", err: errors.New("connection refused"), state: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := &schemaCheck{
+				expected: 8,
+				version: func(context.Context) (int64, bool, error) {
+					return tt.version, tt.dirty, tt.err
+				},
+			}
+
+			status := sc.refresh(context.Background())
+
+			if status.State != tt.state {
+				t.Errorf("expected state %q, got %q", tt.state, status.State)
+			}
+			if status.ok() != tt.ok {
+				t.Errorf("expected ok %v, got %v", tt.ok, status.ok())
+			}
+			if tt.ok == (status.err() != nil) {
+				t.Errorf("unexpected err() result: %v", status.err())
+			}
+		})
+	}
+}
+
+func TestReadinessHandler_RecoversAfterMigration(t *testing.T) {
+	version := int64(7)
+
+	app := newTestApp(&mockStudentModel{})
+	app.schema = &schemaCheck{
+		expected: 8,
+		version: func(context.Context) (int64, bool, error) {
+			return version, false, nil
+		},
+	}
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/v1/readiness", app.readinessHandler)
+
+	w := performRequest(router, "GET", "/v1/readiness", nil)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	version = 8
+
+	w = performRequest(router, "GET", "/v1/readiness", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var resp struct {
+		Status string       `json:"status"`
+		Schema schemaStatus `json:"schema"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.Status != "ready" || resp.Schema.Version != 8 || resp.Schema.State != "current" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+}
+
+func TestSchemaCheckRechecksGoodStatus(t *testing.T) {
+	dirty := false
+
+	sc := &schemaCheck{
+		expected: 8,
+		version: func(context.Context) (int64, bool, error) {
+			return 8, dirty, nil
+		},
+	}
+
+	ctx := context.Background()
+
+	if status := sc.current(ctx); status.State != "current" {
+		t.Fatalf("expected state current, got %q", status.State)
+	}
+
+	dirty = true
+
+	// Within the interval the cached result is served.
+	if status := sc.current(ctx); status.State != "current" {
+		t.Fatalf("expected the cached state, got %q", status.State)
+	}
+
+	sc.mu.Lock()
+	sc.checked = time.Now().Add(-schemaRecheckInterval)
+	sc.mu.Unlock()
+
+	if status := sc.current(ctx); status.State != "dirty" {
+		t.Fatalf("expected the schema to be read again, got %q", status.State)
+	}
+}
@@ -0,0 +1,433 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/jsonlog"
+	"github.com/sai29/one2n_sre_bootcamp/internal/validator"
+)
+
+const seedUsage = `Usage: students_api seed [flags]
+
+Fills the database with fake students, courses, enrollments and grades. The same
+-seed and sizes always generate the same data. Rows are written through the same
+models as the API, so each student also gets a student.created outbox event, and each
+enrolled student an applicant to enrolled transition.
+
+The database must not have any students or courses yet, since the generated course
+codes would collide with those already seeded. Use -reset to replace them.
+
+Profiles:
+  small     200 students, 20 courses, 3 courses per student
+  medium    10000 students, 100 courses, 4 courses per student
+  large     100000 students, 400 courses, 5 courses per student
+
+Flags:
+`
+
+type seedProfile struct {
+	students   int
+	courses    int
+	perStudent int
+}
+
+var seedProfiles = map[string]seedProfile{
+	"small":  {students: 200, courses: 20, perStudent: 3},
+	"medium": {students: 10000, courses: 100, perStudent: 4},
+	"large":  {students: 100000, courses: 400, perStudent: 5},
+}
+
+// runSeed implements the "seed" subcommand.
+func runSeed(args []string) error {
+	var (
+		profile string
+		sizes   seedProfile
+		seed    uint64
+		reset   bool
+		workers int
+	)
+
+	cfg, rest, err := parseConfig("seed", args, os.LookupEnv, os.Stderr, func(fs *flag.FlagSet, cfg *config) {
+		fs.StringVar(&cfg.DB.DSN, "db-dsn", cfg.DB.DSN, "PostgreSQL DSN (also DATABASE_URL)")
+		fs.StringVar(&cfg.Env, "env", cfg.Env, "Environment (also ENV); -reset is refused in prod")
+		fs.StringVar(&profile, "profile", "small", "Data set size (small|medium|large)")
+		fs.IntVar(&sizes.students, "count", 0, "Number of students, overriding the profile's")
+		fs.IntVar(&sizes.courses, "courses", 0, "Number of courses, overriding the profile's")
+		fs.IntVar(&sizes.perStudent, "per-student", 0, "Courses each student enrolls in, overriding the profile's")
+		fs.Uint64Var(&seed, "seed", 1, "Random seed")
+		fs.BoolVar(&reset, "reset", false, "Delete all students and courses, everything that belongs to them, and all outbox events and webhook deliveries, first")
+		fs.IntVar(&workers, "workers", 1, "Concurrent enrollment writers; above 1, IDs and waitlists are no longer deterministic")
+		fs.Usage = func() {
+			fmt.Fprint(fs.Output(), seedUsage)
+			fs.PrintDefaults()
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(rest) > 0 {
+		return fmt.Errorf("unexpected argument %q", rest[0])
+	}
+
+	base, ok := seedProfiles[profile]
+	if !ok {
+		return fmt.Errorf("invalid -profile %q (must be small, medium or large)", profile)
+	}
+
+	if sizes.students == 0 {
+		sizes.students = base.students
+	}
+	if sizes.courses == 0 {
+		sizes.courses = base.courses
+	}
+	if sizes.perStudent == 0 {
+		sizes.perStudent = base.perStudent
+	}
+
+	v := validator.New()
+	v.Check(sizes.students > 0, "count", "must be a positive integer")
+	v.Check(sizes.courses > 0, "courses", "must be a positive integer")
+	v.Check(sizes.perStudent >= 0, "per-student", "must not be negative")
+	v.Check(sizes.perStudent <= sizes.courses, "per-student", "must not be more than -courses")
+	v.Check(workers > 0, "workers", "must be a positive integer")
+	v.Check(!reset || !validator.PermittedValue(cfg.Env, "prod", "production"), "reset", "is not allowed in prod")
+
+	if !v.Valid() {
+		var problems []string
+		for field, msg := range v.Errors {
+			problems = append(problems, "-"+field+" "+msg)
+		}
+		sort.Strings(problems)
+		return errors.New(strings.Join(problems, "; "))
+	}
+
+	if cfg.DB.DSN == "" {
+		return errors.New("DATABASE_URL or -db-dsn must be set")
+	}
+
+	cfg.DB.MaxOpenConns = workers + 1
+	cfg.DB.MaxIdleConns = workers + 1
+	cfg.DB.MaxIdleTime = time.Minute
+
+	db, err := openDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+
+	logger := jsonlog.NewLogger(os.Stdout, jsonlog.LevelInfo)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if reset {
+		if err := resetSeedData(ctx, db); err != nil {
+			return fmt.Errorf("resetting: %w", err)
+		}
+		logger.PrintInfo("seed reset", nil)
+	} else {
+		seeded, err := hasSeedData(ctx, db)
+		if err != nil {
+			return err
+		}
+		if seeded {
+			return errors.New("the database already has students or courses; run with -reset to replace them")
+		}
+	}
+
+	seeder := &seeder{
+		models:  data.NewModels(db),
+		logger:  logger,
+		workers: workers,
+	}
+
+	return seeder.run(ctx, newSeedPlan(sizes, seed))
+}
+
+// resetSeedData deletes every student and course, with everything that belongs to them,
+// and the outbox events and webhook deliveries about them. Webhook subscriptions are kept.
+//
+// TRUNCATE bypasses the models on purpose: deleting row by row would take as long as
+// seeding and queue a student.deleted event for every student. Identities are not
+// restarted, so an id never names two students or events: a cached student, an event
+// stream's Last-Event-ID or a webhook receiver's records can't be mistaken for new data.
+// Cached copies of the deleted students expire within the API's -cache-ttl.
+func resetSeedData(ctx context.Context, db *sql.DB) error {
+	_, err := db.ExecContext(ctx, `TRUNCATE students, courses, outbox, webhook_deliveries CASCADE`)
+	return err
+}
+
+// hasSeedData reports whether there are any students or courses. Seeding on top of them
+// would fail partway through on the first course code that is already taken.
+func hasSeedData(ctx context.Context, db *sql.DB) (bool, error) {
+	var seeded bool
+
+	err := db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM students) OR EXISTS (SELECT 1 FROM courses)`).Scan(&seeded)
+	return seeded, err
+}
+
+// seedPlan is the data to seed, generated up front so that it depends only on the seed
+// and sizes, not on the order writes complete in.
+type seedPlan struct {
+	students []*data.Student
+	courses  []*data.Course
+
+	// enrollments[i] holds the indexes into courses that students[i] enrolls in, and
+	// grades[i] the matching grades, "" where the course hasn't been graded yet.
+	enrollments [][]int
+	grades      [][]string
+}
+
+// seedTerms are the terms courses run in. Courses in the last one are in progress and
+// have no grades.
+var seedTerms = []string{"2023-fall", "2024-spring", "2024-fall", "2025-spring"}
+
+var seedDepartments = []struct {
+	code   string
+	name   string
+	topics []string
+}{
+	{"CS", "Computer Science", []string{"Programming", "Data Structures", "Algorithms", "Operating Systems", "Networks", "Databases", "Distributed Systems", "Compilers"}},
+	{"MATH", "Mathematics", []string{"Calculus", "Linear Algebra", "Probability", "Statistics", "Discrete Mathematics", "Number Theory"}},
+	{"PHYS", "Physics", []string{"Mechanics", "Electromagnetism", "Thermodynamics", "Quantum Physics", "Optics"}},
+	{"CHEM", "Chemistry", []string{"General Chemistry", "Organic Chemistry", "Biochemistry", "Physical Chemistry"}},
+	{"ECON", "Economics", []string{"Microeconomics", "Macroeconomics", "Econometrics", "Game Theory"}},
+	{"HIST", "History", []string{"World History", "Ancient Civilizations", "Modern Europe", "History of Science"}},
+	{"ENG", "English", []string{"Composition", "Technical Writing", "Shakespeare", "Modern Fiction"}},
+	{"BIO", "Biology", []string{"Cell Biology", "Genetics", "Ecology", "Microbiology", "Evolution"}},
+}
+
+var seedFirstNames = []string{
+	"Aarav", "Aisha", "Alejandro", "Amara", "Ananya", "Arjun", "Chen", "Chloe", "Daniel", "Diya",
+	"Elena", "Emeka", "Fatima", "Gabriel", "Hana", "Ibrahim", "Isabella", "Ishaan", "James", "Kavya",
+	"Kenji", "Leila", "Liam", "Lucia", "Mateo", "Maya", "Mei", "Mohammed", "Nadia", "Noah",
+	"Olivia", "Omar", "Priya", "Rahul", "Sara", "Sofia", "Tariq", "Wei", "Yusuf", "Zara",
+}
+
+var seedLastNames = []string{
+	"Adeyemi", "Ahmed", "Ali", "Chen", "Costa", "Das", "Fernandez", "Garcia", "Gupta", "Hassan",
+	"Ito", "Iyer", "Johnson", "Kim", "Kowalski", "Kumar", "Lee", "Martin", "Mehta", "Mueller",
+	"Nair", "Nguyen", "Novak", "Okafor", "Patel", "Perez", "Reddy", "Rossi", "Sato", "Schmidt",
+	"Shah", "Silva", "Singh", "Smith", "Tanaka", "Wang", "Williams", "Yamamoto", "Yilmaz", "Zhang",
+}
+
+var seedStreets = []string{"Oak Street", "Maple Avenue", "Park Road", "Station Road", "Lake View", "Hill Street", "Church Lane", "Market Street"}
+
+var seedCities = []string{"Pune", "Bengaluru", "Chennai", "Hyderabad", "Mumbai", "Delhi", "Kolkata", "Jaipur"}
+
+// seedGrades are the letter grades handed out, each repeated by how common it is.
+var seedGrades = strings.Fields(strings.Repeat("A ", 12) + strings.Repeat("A- ", 10) +
+	strings.Repeat("B+ ", 12) + strings.Repeat("B ", 14) + strings.Repeat("B- ", 9) +
+	strings.Repeat("C+ ", 7) + strings.Repeat("C ", 7) + strings.Repeat("C- ", 4) +
+	strings.Repeat("D ", 3) + strings.Repeat("F ", 4) + strings.Repeat("W ", 3) + "A+ A+ I P")
+
+func newSeedPlan(sizes seedProfile, seed uint64) *seedPlan {
+	rng := rand.New(rand.NewPCG(seed, 0x5eed))
+	pick := func(s []string) string { return s[rng.IntN(len(s))] }
+
+	plan := &seedPlan{
+		students:    make([]*data.Student, sizes.students),
+		courses:     make([]*data.Course, sizes.courses),
+		enrollments: make([][]int, sizes.students),
+		grades:      make([][]string, sizes.students),
+	}
+
+	// Size courses so that most have room for everyone, and some have waitlists.
+	load := max(sizes.students*sizes.perStudent/sizes.courses, 10)
+
+	for i := range plan.courses {
+		dept := seedDepartments[i%len(seedDepartments)]
+
+		plan.courses[i] = &data.Course{
+			Code:     fmt.Sprintf("%s%d", dept.code, 101+i/len(seedDepartments)),
+			Title:    dept.name + ": " + pick(dept.topics),
+			Credits:  int32(1 + rng.IntN(5)),
+			Capacity: int32(load * (90 + rng.IntN(40)) / 100),
+			Term:     pick(seedTerms),
+		}
+	}
+
+	for i := range plan.students {
+		first, last := pick(seedFirstNames), pick(seedLastNames)
+
+		dob := time.Date(1995+rng.IntN(12), time.Month(1+rng.IntN(12)), 1+rng.IntN(28), 0, 0, 0, 0, time.UTC)
+
+		status := data.StudentEnrolled
+		if rng.IntN(10) == 0 {
+			status = data.StudentApplicant
+		}
+
+		plan.students[i] = &data.Student{
+			Name:        first + " " + last,
+			RollNo:      int32(i + 1),
+			Email:       strings.ToLower(fmt.Sprintf("%s.%s.%d@example.edu", first, last, i+1)),
+			Phone:       fmt.Sprintf("+91%d%09d", 7+rng.IntN(3), rng.IntN(1_000_000_000)),
+			DateOfBirth: &data.Date{Time: dob},
+			Address:     fmt.Sprintf("%d %s, %s", 1+rng.IntN(500), pick(seedStreets), pick(seedCities)),
+			Status:      status,
+		}
+
+		if status == data.StudentApplicant {
+			continue
+		}
+
+		for _, c := range rng.Perm(sizes.courses)[:sizes.perStudent] {
+			grade := ""
+			if plan.courses[c].Term != seedTerms[len(seedTerms)-1] {
+				grade = pick(seedGrades)
+			}
+
+			plan.enrollments[i] = append(plan.enrollments[i], c)
+			plan.grades[i] = append(plan.grades[i], grade)
+		}
+	}
+
+	return plan
+}
+
+// seeder writes a seedPlan through the models.
+type seeder struct {
+	models  data.Models
+	logger  *jsonlog.Logger
+	workers int
+}
+
+// seedBatchSize is the number of students inserted per transaction.
+const seedBatchSize = 1000
+
+func (s *seeder) run(ctx context.Context, plan *seedPlan) error {
+	start := time.Now()
+
+	for _, c := range plan.courses {
+		if err := s.models.Courses.Insert(c); err != nil {
+			return fmt.Errorf("inserting course %s %s: %w", c.Code, c.Term, err)
+		}
+	}
+	s.logger.PrintInfo("seeded courses", map[string]string{"count": fmt.Sprint(len(plan.courses))})
+
+	for i := 0; i < len(plan.students); i += seedBatchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		batch := plan.students[i:min(i+seedBatchSize, len(plan.students))]
+		if err := s.models.Students.InsertMany(batch); err != nil {
+			return fmt.Errorf("inserting students: %w", err)
+		}
+	}
+	s.logger.PrintInfo("seeded students", map[string]string{"count": fmt.Sprint(len(plan.students))})
+
+	enrolled, waitlisted, graded, err := s.enroll(ctx, plan)
+	if err != nil {
+		return err
+	}
+
+	s.logger.PrintInfo("seed complete", map[string]string{
+		"students":    fmt.Sprint(len(plan.students)),
+		"courses":     fmt.Sprint(len(plan.courses)),
+		"enrollments": fmt.Sprint(enrolled),
+		"waitlisted":  fmt.Sprint(waitlisted),
+		"grades":      fmt.Sprint(graded),
+		"duration":    time.Since(start).Round(time.Millisecond).String(),
+	})
+
+	return nil
+}
+
+// enroll enrolls every student in their planned courses and records their grades. Only
+// seated enrollments are graded; students who end up waitlisted are not.
+func (s *seeder) enroll(ctx context.Context, plan *seedPlan) (enrolled, waitlisted, graded int, err error) {
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+
+	next := make(chan int)
+
+	for range s.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range next {
+				e, w, g, err := s.enrollStudent(plan, i)
+
+				mu.Lock()
+				enrolled, waitlisted, graded = enrolled+e, waitlisted+w, graded+g
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := range plan.students {
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+
+		if failed || ctx.Err() != nil {
+			break
+		}
+
+		if i > 0 && i%seedBatchSize == 0 {
+			s.logger.PrintInfo("seeding enrollments", map[string]string{"students": fmt.Sprint(i)})
+		}
+
+		next <- i
+	}
+
+	close(next)
+	wg.Wait()
+
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+
+	return enrolled, waitlisted, graded, firstErr
+}
+
+func (s *seeder) enrollStudent(plan *seedPlan, i int) (enrolled, waitlisted, graded int, err error) {
+	student := plan.students[i]
+
+	for j, c := range plan.enrollments[i] {
+		course := plan.courses[c]
+
+		e, err := s.models.Enrollments.Enroll(course.ID, student.ID)
+		if err != nil {
+			return enrolled, waitlisted, graded, fmt.Errorf("enrolling student %d in course %d: %w", student.ID, course.ID, err)
+		}
+
+		if e.Status != data.EnrollmentEnrolled {
+			waitlisted++
+			continue
+		}
+		enrolled++
+
+		if grade := plan.grades[i][j]; grade != "" {
+			if _, _, err := s.models.Grades.Set(e.ID, grade); err != nil {
+				return enrolled, waitlisted, graded, fmt.Errorf("grading enrollment %d: %w", e.ID, err)
+			}
+			graded++
+		}
+	}
+
+	return enrolled, waitlisted, graded, nil
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/gpa"
+	"github.com/sai29/one2n_sre_bootcamp/internal/jsonlog"
+	"github.com/sai29/one2n_sre_bootcamp/internal/validator"
+)
+
+func TestSeedPlanIsDeterministic(t *testing.T) {
+	sizes := seedProfile{students: 300, courses: 12, perStudent: 3}
+
+	a, b := newSeedPlan(sizes, 42), newSeedPlan(sizes, 42)
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("the same seed generated different plans")
+	}
+
+	if c := newSeedPlan(sizes, 43); reflect.DeepEqual(a, c) {
+		t.Fatal("different seeds generated the same plan")
+	}
+}
+
+func TestSeedPlanIsValid(t *testing.T) {
+	sizes := seedProfile{students: 500, courses: 30, perStudent: 4}
+	plan := newSeedPlan(sizes, 7)
+
+	if len(plan.students) != 500 || len(plan.courses) != 30 {
+		t.Fatalf("got %d students and %d courses", len(plan.students), len(plan.courses))
+	}
+
+	courseKeys := map[string]bool{}
+	for _, c := range plan.courses {
+		v := validator.New()
+		if data.ValidateCourse(v, c); !v.Valid() {
+			t.Fatalf("invalid course %+v: %v", c, v.Errors)
+		}
+
+		key := c.Code + " " + c.Term
+		if courseKeys[key] {
+			t.Fatalf("course %s is generated twice", key)
+		}
+		courseKeys[key] = true
+	}
+
+	scale := gpa.DefaultScale()
+	current := seedTerms[len(seedTerms)-1]
+
+	for i, s := range plan.students {
+		v := validator.New()
+		if data.ValidateNewStudent(v, s); !v.Valid() {
+			t.Fatalf("invalid student %+v: %v", s, v.Errors)
+		}
+
+		if s.Status == data.StudentApplicant && len(plan.enrollments[i]) != 0 {
+			t.Errorf("applicant %d is enrolled in courses", i)
+		}
+		if s.Status == data.StudentEnrolled && len(plan.enrollments[i]) != sizes.perStudent {
+			t.Errorf("student %d is enrolled in %d courses, want %d", i, len(plan.enrollments[i]), sizes.perStudent)
+		}
+
+		seen := map[int]bool{}
+		for j, c := range plan.enrollments[i] {
+			if seen[c] {
+				t.Errorf("student %d is enrolled in course %d twice", i, c)
+			}
+			seen[c] = true
+
+			grade := plan.grades[i][j]
+			switch {
+			case plan.courses[c].Term == current && grade != "":
+				t.Errorf("student %d has grade %s in an in-progress course", i, grade)
+			case plan.courses[c].Term != current && !scale.Valid(grade):
+				t.Errorf("student %d has grade %q, which is not on the default scale", i, grade)
+			}
+		}
+	}
+}
+
+// seedTestModels records what the seeder writes, waitlisting enrollments once a course
+// is full like the Postgres model does.
+func seedTestModels(t *testing.T) (data.Models, *[]*data.Student, map[int64]string) {
+	t.Helper()
+
+	var (
+		mu       sync.Mutex
+		nextID   int64
+		students []*data.Student
+		courses  = map[int64]*data.Course{}
+		seats    = map[int64]int32{}
+		grades   = map[int64]string{}
+	)
+
+	id := func() int64 {
+		nextID++
+		return nextID
+	}
+
+	models := data.Models{
+		Courses: &mockCourseModel{insertFn: func(c *data.Course) error {
+			mu.Lock()
+			defer mu.Unlock()
+			c.ID = id()
+			courses[c.ID] = c
+			return nil
+		}},
+		Students: &mockStudentModel{manyFn: func(batch []*data.Student) error {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, s := range batch {
+				s.ID = id()
+			}
+			students = append(students, batch...)
+			return nil
+		}},
+		Enrollments: &mockEnrollmentModel{enrollFn: func(courseID, studentID int64) (*data.Enrollment, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			e := &data.Enrollment{ID: id(), CourseID: courseID, StudentID: studentID, Status: data.EnrollmentEnrolled}
+			if seats[courseID] >= courses[courseID].Capacity {
+				e.Status = data.EnrollmentWaitlisted
+			} else {
+				seats[courseID]++
+			}
+			return e, nil
+		}},
+		Grades: &mockGradeModel{setFn: func(enrollmentID int64, grade string) (*data.Grade, bool, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			grades[enrollmentID] = grade
+			return &data.Grade{EnrollmentID: enrollmentID, Grade: grade}, true, nil
+		}},
+	}
+
+	return models, &students, grades
+}
+
+func TestSeederRun(t *testing.T) {
+	models, students, grades := seedTestModels(t)
+
+	s := &seeder{
+		models:  models,
+		logger:  jsonlog.NewLogger(io.Discard, jsonlog.LevelOff),
+		workers: 4,
+	}
+
+	plan := newSeedPlan(seedProfile{students: 2500, courses: 20, perStudent: 3}, 42)
+
+	if err := s.run(context.Background(), plan); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(*students) != 2500 {
+		t.Errorf("inserted %d students, want 2500", len(*students))
+	}
+
+	want := 0
+	for i := range plan.grades {
+		for _, g := range plan.grades[i] {
+			if g != "" {
+				want++
+			}
+		}
+	}
+
+	// Some graded enrollments end up waitlisted, and waitlisted students aren't graded.
+	if len(grades) == 0 || len(grades) > want {
+		t.Errorf("recorded %d grades, want between 1 and %d", len(grades), want)
+	}
+}
+
+func TestSeederStopsOnError(t *testing.T) {
+	models, _, _ := seedTestModels(t)
+	models.Enrollments = &mockEnrollmentModel{enrollFn: func(courseID, studentID int64) (*data.Enrollment, error) {
+		return nil, data.ErrRecordNotFound
+	}}
+
+	s := &seeder{
+		models:  models,
+		logger:  jsonlog.NewLogger(io.Discard, jsonlog.LevelOff),
+		workers: 2,
+	}
+
+	err := s.run(context.Background(), newSeedPlan(seedProfile{students: 100, courses: 5, perStudent: 2}, 1))
+	if !errors.Is(err, data.ErrRecordNotFound) {
+		t.Fatalf("got %v, want the enrollment error", err)
+	}
+}
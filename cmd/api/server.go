@@ -9,17 +9,51 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 )
 
 func (app *application) serve() error {
 	srv := &http.Server{
-		Addr:         fmt.Sprintf(":%d", app.config.port),
+		Addr:         fmt.Sprintf(":%d", app.config.Port),
 		Handler:      app.routes(),
 		ErrorLog:     log.New(app.logger, "", 0),
-		IdleTimeout:  time.Minute,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 30 * time.Second,
+		IdleTimeout:  app.config.Server.IdleTimeout,
+		ReadTimeout:  app.config.Server.ReadTimeout,
+		WriteTimeout: app.config.Server.WriteTimeout,
+	}
+
+	// Event streams never go idle, so they are closed as soon as shutdown starts rather
+	// than holding srv.Shutdown until its deadline.
+	if app.streams != nil {
+		srv.RegisterOnShutdown(app.streams.Close)
+	}
+
+	var grpcSrv *grpcServer
+
+	if app.config.GRPC.Port != 0 {
+		var err error
+		grpcSrv, err = app.newGRPCServer()
+		if err != nil {
+			return err
+		}
+
+		if len(app.config.GRPC.AuthTokens) == 0 {
+			app.logger.PrintError(errors.New("grpc authentication is disabled"), map[string]string{
+				"addr": grpcSrv.lis.Addr().String(),
+				"hint": "set -grpc-auth-tokens outside local development",
+			})
+		}
+
+		app.background(func() { app.grpcHealth(grpcSrv.health) })
+
+		go func() {
+			app.logger.PrintInfo("starting grpc server", map[string]string{
+				"addr": grpcSrv.lis.Addr().String(),
+			})
+
+			if err := grpcSrv.srv.Serve(grpcSrv.lis); err != nil {
+				app.logger.PrintError(err, map[string]string{"addr": grpcSrv.lis.Addr().String()})
+			}
+		}()
 	}
 
 	shutdownError := make(chan error)
@@ -28,22 +62,46 @@ func (app *application) serve() error {
 
 		quit := make(chan os.Signal, 1)
 
-		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 
 		s := <-quit
 
+		// SIGHUP reloads the configuration; the server keeps running until it gets one
+		// of the other signals.
+		for s == syscall.SIGHUP {
+			app.logger.PrintInfo("caught signal, reloading configuration", map[string]string{
+				"signal": s.String(),
+			})
+
+			_ = app.reloadConfig()
+
+			s = <-quit
+		}
+
 		app.logger.PrintInfo("caught signal", map[string]string{
 			"signal": s.String(),
 		})
 
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), app.config.Server.ShutdownTimeout)
 		defer cancel()
 
+		// Both servers drain in-flight requests under the same deadline.
+		grpcStopped := make(chan struct{})
+		go func() {
+			if grpcSrv != nil {
+				grpcSrv.stop(ctx)
+			}
+			close(grpcStopped)
+		}()
+
 		err := srv.Shutdown(ctx)
+		<-grpcStopped
 		if err != nil {
 			shutdownError <- err
 		}
 
+		close(app.shutdown)
+
 		app.logger.PrintInfo("completing background tasks", map[string]string{
 			"addr": srv.Addr,
 		})
@@ -54,7 +112,7 @@ func (app *application) serve() error {
 
 	app.logger.PrintInfo("starting server", map[string]string{
 		"addr": srv.Addr,
-		"env":  app.config.env,
+		"env":  app.config.Env,
 	})
 
 	err := srv.ListenAndServe()
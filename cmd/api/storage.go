@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/cache"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/jsonlog"
+	"github.com/sai29/one2n_sre_bootcamp/internal/migrate"
+	"github.com/sai29/one2n_sre_bootcamp/internal/replica"
+	"github.com/sai29/one2n_sre_bootcamp/migrations"
+)
+
+const (
+	dbDriverPostgres = "postgres"
+	dbDriverSQLite   = "sqlite"
+	dbDriverMemory   = "memory"
+
+	cacheBackendNone   = "none"
+	cacheBackendMemory = "memory"
+	cacheBackendRedis  = "redis"
+)
+
+// storage is what openStorage hands back to main: the models to serve from, the schema
+// check for readiness (nil when the backend has no migrations) and a close function.
+type storage struct {
+	models data.Models
+	schema *schemaCheck
+	close  func() error
+
+	// pools are the Postgres pools, primary first; empty for the other drivers.
+	pools []*sql.DB
+
+	replicas      *replica.Pool
+	replicaModels []data.Models
+}
+
+// openStorage builds the models for the configured driver. Only Postgres provides every
+// store; the SQLite and memory backends implement StudentStore alone, and routes for the
+// other resources are not registered when they run.
+func openStorage(cfg config, logger *jsonlog.Logger) (*storage, error) {
+	store, err := openBackend(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Cache.Backend != cacheBackendNone {
+		if err := enableCache(cfg, logger, store); err != nil {
+			_ = store.close()
+			return nil, err
+		}
+	}
+
+	return store, nil
+}
+
+func openBackend(cfg config, logger *jsonlog.Logger) (*storage, error) {
+	switch cfg.DB.Driver {
+	case dbDriverMemory:
+		logger.PrintInfo("using in-memory storage", nil)
+
+		return &storage{
+			models: data.Models{Students: data.NewMemoryStudentModel(), ErrorLog: log.New(logger, "", 0)},
+			close:  func() error { return nil },
+		}, nil
+
+	case dbDriverSQLite:
+		db, err := data.OpenSQLite(cfg.DB.DSN)
+		if err != nil {
+			return nil, err
+		}
+
+		logger.PrintInfo("sqlite database opened", map[string]string{
+			"path": cfg.DB.DSN,
+		})
+
+		students := data.NewSQLiteStudentModel(db)
+
+		return &storage{
+			models: data.Models{Students: students, ErrorLog: students.ErrorLog},
+			close:  db.Close,
+		}, nil
+	}
+
+	db, err := openDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.PrintInfo("database connection pool established", nil)
+
+	schema, err := checkSchema(cfg, db, logger)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	store := &storage{
+		models: data.NewModels(db),
+		schema: schema,
+		close:  db.Close,
+		pools:  []*sql.DB{db},
+	}
+
+	if len(cfg.DB.Replicas.DSNs) > 0 {
+		if err := openReplicas(cfg, logger, store); err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+	}
+
+	return store, nil
+}
+
+// openReplicas opens a pool per replica DSN. Replicas are not pinged here: one that is down
+// at startup is marked unhealthy by the first health check and reads fall back to the primary.
+func openReplicas(cfg config, logger *jsonlog.Logger, store *storage) error {
+	var (
+		names []string
+		dbs   []*sql.DB
+	)
+
+	for i, dsn := range cfg.DB.Replicas.DSNs {
+		db, err := newDBPool(cfg, dsn)
+		if err != nil {
+			for _, opened := range dbs {
+				_ = opened.Close()
+			}
+			return err
+		}
+
+		names = append(names, fmt.Sprintf("replica-%d", i+1))
+		dbs = append(dbs, db)
+		store.replicaModels = append(store.replicaModels, data.NewModels(db))
+	}
+
+	store.pools = append(store.pools, dbs...)
+	store.replicas = replica.New(names, dbs)
+	store.replicas.Logger = logger
+
+	primaryClose := store.close
+	store.close = func() error {
+		return errors.Join(store.replicas.Close(), primaryClose())
+	}
+
+	logger.PrintInfo("read replicas configured", map[string]string{
+		"replicas": strconv.Itoa(len(dbs)),
+	})
+
+	return nil
+}
+
+// checkSchema optionally applies pending migrations and then compares the schema with the
+// embedded migrations. In strict mode a schema that is behind or dirty is an error; in
+// degraded mode it is logged and left for the readiness endpoint to report.
+func checkSchema(cfg config, db *sql.DB, logger *jsonlog.Logger) (*schemaCheck, error) {
+	m, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+	m.Logger = logger
+
+	if cfg.DB.AutoMigrate {
+		if err := m.Up(context.Background()); err != nil {
+			return nil, err
+		}
+
+		logger.PrintInfo("database migrations applied", map[string]string{
+			"version": strconv.FormatInt(m.Latest(), 10),
+		})
+	}
+
+	schema := &schemaCheck{version: m.Version, expected: m.Latest()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if status := schema.refresh(ctx); !status.ok() {
+		if cfg.DB.SchemaMode == schemaModeStrict {
+			return nil, status.err()
+		}
+
+		logger.PrintError(status.err(), map[string]string{
+			"schema_mode": cfg.DB.SchemaMode,
+		})
+	}
+
+	return schema, nil
+}
+
+// enableCache puts a read-through cache in front of the student stores. The replica models
+// share the primary's cache, so the version watermarks set by writes on the primary also
+// stop a lagging replica from filling the cache with an old row.
+func enableCache(cfg config, logger *jsonlog.Logger, store *storage) error {
+	var backend cache.Backend
+
+	switch cfg.Cache.Backend {
+	case cacheBackendMemory:
+		lru := cache.NewLRU(cfg.Cache.Size, studentCacheRecorder{})
+		lru.NeverEvict(data.IsCacheWatermark)
+		backend = lru
+
+	case cacheBackendRedis:
+		if cfg.Cache.RedisURL == "" {
+			return errors.New("REDIS_URL or -cache-redis-url must be set for the redis cache backend")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		r, err := cache.OpenRedis(ctx, cfg.Cache.RedisURL, cache.StudentsPrefix)
+		if err != nil {
+			return err
+		}
+
+		backend = r
+
+		closeStore := store.close
+		store.close = func() error {
+			return errors.Join(r.Close(), closeStore())
+		}
+	}
+
+	store.models = data.WithStudentCache(store.models, backend, cfg.Cache.TTL, studentCacheRecorder{})
+
+	for i := range store.replicaModels {
+		store.replicaModels[i] = data.WithStudentCache(store.replicaModels[i], backend, cfg.Cache.TTL, studentCacheRecorder{})
+	}
+
+	logger.PrintInfo("student cache enabled", map[string]string{
+		"backend": cfg.Cache.Backend,
+		"ttl":     cfg.Cache.TTL.String(),
+	})
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+)
+
+func TestRoutes_MemoryStore(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	app := newTestApp(nil)
+	app.models = data.Models{Students: data.NewMemoryStudentModel()}
+	router := app.routes()
+
+	w := performRequest(router, "POST", "/v1/students", []byte(`{"name":"Asha","rollno":7}`))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected %d, got %d: %s", http.StatusCreated, w.Code, w.Body)
+	}
+
+	var resp struct {
+		Student data.Student `json:"student"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Student.ID != 1 || resp.Student.Status != data.StudentApplicant {
+		t.Fatalf("unexpected student: %+v", resp.Student)
+	}
+
+	w = performRequest(router, "GET", "/v1/students/1", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, w.Code)
+	}
+
+	w = performRequest(router, "GET", "/v1/courses", nil)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("courses routes should not be registered without a CourseStore, got %d", w.Code)
+	}
+
+	w = performRequest(router, "GET", "/v1/readiness", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d from readiness, got %d", http.StatusOK, w.Code)
+	}
+}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"database/sql"
 	"errors"
 	"fmt"
 	"net/http"
@@ -9,17 +8,37 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/validator"
 )
 
+// studentInput is the JSON body of the create and update requests.
+type studentInput struct {
+	Name        *string `json:"name"`
+	RollNo      *int32  `json:"rollno"`
+	Email       *string `json:"email"`
+	Phone       *string `json:"phone"`
+	DateOfBirth *string `json:"date_of_birth"`
+	Address     *string `json:"address"`
+	Status      *string `json:"status"`
+}
+
 func (app *application) createStudentHandler(c *gin.Context) {
-	var student data.Student
+	var input studentInput
 
-	if err := c.ShouldBindJSON(&student); err != nil {
+	if err := c.ShouldBindJSON(&input); err != nil {
 		app.badRequestResponse(c, err)
 		return
 	}
 
-	if err := app.models.Students.Insert(&student); err != nil {
+	v := validator.New()
+
+	student := data.StudentInput(input).NewStudent(v)
+	if !v.Valid() {
+		app.failedValidationResponse(c, v.Errors)
+		return
+	}
+
+	if err := app.models.Students.Insert(student); err != nil {
 		app.serverErrorResponse(c, err)
 		return
 	}
@@ -39,10 +58,10 @@ func (app *application) showStudentHandler(c *gin.Context) {
 		return
 	}
 
-	student, err := app.models.Students.Get(id)
+	student, err := app.readModels(c).Students.Get(id)
 	if err != nil {
 		switch {
-		case errors.Is(err, sql.ErrNoRows):
+		case errors.Is(err, data.ErrRecordNotFound):
 			app.notFoundResponse(c)
 		default:
 			app.serverErrorResponse(c, err)
@@ -55,8 +74,39 @@ func (app *application) showStudentHandler(c *gin.Context) {
 	})
 }
 
+// listStudentsHandler returns students in ID order. ?limit= (at most 1000) and ?offset=
+// return one page of them; without a limit every student is returned.
 func (app *application) listStudentsHandler(c *gin.Context) {
-	students, err := app.models.Students.ListAll()
+	v := validator.New()
+
+	limit, offset := 0, 0
+
+	if s := c.Query("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		v.Check(err == nil && n >= 1 && n <= 1000, "limit", "must be an integer between 1 and 1000")
+		limit = n
+	}
+
+	if s := c.Query("offset"); s != "" {
+		n, err := strconv.Atoi(s)
+		v.Check(err == nil && n >= 0, "offset", "must be a non-negative integer")
+		v.Check(c.Query("limit") != "", "offset", "must be used with limit")
+		offset = n
+	}
+
+	if !v.Valid() {
+		app.failedValidationResponse(c, v.Errors)
+		return
+	}
+
+	var students []*data.Student
+	var err error
+
+	if limit > 0 {
+		students, err = app.readModels(c).Students.List(c.Request.Context(), limit, offset)
+	} else {
+		students, err = app.readModels(c).Students.ListAll()
+	}
 	if err != nil {
 		app.serverErrorResponse(c, err)
 		return
@@ -85,22 +135,18 @@ func (app *application) updateStudentHandler(c *gin.Context) {
 		return
 	}
 
-	var input struct {
-		Name   *string `json:"name"`
-		RollNo *int32  `json:"rollno"`
-	}
+	var input studentInput
 
 	if err := c.ShouldBindJSON(&input); err != nil {
 		app.badRequestResponse(c, err)
 		return
 	}
 
-	if input.Name != nil {
-		studentRecord.Name = *input.Name
-	}
+	v := validator.New()
 
-	if input.RollNo != nil {
-		studentRecord.RollNo = *input.RollNo
+	if data.StudentInput(input).ApplyTo(v, studentRecord); !v.Valid() {
+		app.failedValidationResponse(c, v.Errors)
+		return
 	}
 
 	if err := app.models.Students.Update(studentRecord); err != nil {
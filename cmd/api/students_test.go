@@ -1,19 +1,29 @@
 package main
 
 import (
+	"archive/zip"
 	"bytes"
+	"context"
+	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/gpa"
+	"github.com/sai29/one2n_sre_bootcamp/internal/jsonlog"
+	"github.com/sai29/one2n_sre_bootcamp/internal/stream"
 )
 
 type mockStudentModel struct {
 	insertFn func(s *data.Student) error
 	getFn    func(id int64) (*data.Student, error)
 	listFn   func() ([]*data.Student, error)
+	pageFn   func(limit, offset int) ([]*data.Student, error)
+	eachFn   func(fn func(*data.Student) error) error
+	manyFn   func(s []*data.Student) error
 	updateFn func(s *data.Student) error
 	deleteFn func(id int64) error
 }
@@ -30,6 +40,18 @@ func (m *mockStudentModel) ListAll() ([]*data.Student, error) {
 	return m.listFn()
 }
 
+func (m *mockStudentModel) List(_ context.Context, limit, offset int) ([]*data.Student, error) {
+	return m.pageFn(limit, offset)
+}
+
+func (m *mockStudentModel) ForEach(_ context.Context, fn func(*data.Student) error) error {
+	return m.eachFn(fn)
+}
+
+func (m *mockStudentModel) InsertMany(s []*data.Student) error {
+	return m.manyFn(s)
+}
+
 func (m *mockStudentModel) Update(s *data.Student) error {
 	return m.updateFn(s)
 }
@@ -39,9 +61,29 @@ func (m *mockStudentModel) Delete(id int64) error {
 }
 
 func newTestApp(mock *mockStudentModel) *application {
-	return &application{
+	app := &application{
+		logger: jsonlog.NewLogger(io.Discard, jsonlog.LevelOff),
 		models: data.Models{Students: mock},
 	}
+	app.config.Grading.Scale = gpa.DefaultScale()
+
+	return app
+}
+
+// newFullTestApp is newTestApp with every optional store and the stream hub set, so that
+// routes() registers every route.
+func newFullTestApp(mock *mockStudentModel) *application {
+	app := newTestApp(mock)
+	app.models.Transitions = &mockTransitionModel{}
+	app.models.Guardians = &mockGuardianModel{}
+	app.models.Courses = &mockCourseModel{}
+	app.models.Enrollments = &mockEnrollmentModel{}
+	app.models.Grades = &mockGradeModel{}
+	app.models.Attendance = &mockAttendanceModel{}
+	app.models.Webhooks = &mockWebhookModel{}
+	app.streams = stream.NewHub(10)
+
+	return app
 }
 
 func performRequest(r http.Handler, method, path string, body []byte) *httptest.ResponseRecorder {
@@ -117,11 +159,51 @@ func TestListStudenthandler(t *testing.T) {
 	}
 }
 
+func TestListStudentsHandler_Paged(t *testing.T) {
+	var gotLimit, gotOffset int
+
+	mock := &mockStudentModel{
+		pageFn: func(limit, offset int) ([]*data.Student, error) {
+			gotLimit, gotOffset = limit, offset
+			return []*data.Student{{ID: 3, Name: "Jo"}}, nil
+		},
+	}
+
+	app := newTestApp(mock)
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/v1/students", app.listStudentsHandler)
+
+	w := performRequest(router, "GET", "/v1/students?limit=1&offset=2", nil)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, w.Code)
+	}
+	if gotLimit != 1 || gotOffset != 2 {
+		t.Fatalf("expected the page to be read with limit 1 and offset 2, got %d and %d", gotLimit, gotOffset)
+	}
+}
+
+func TestListStudentsHandler_OffsetWithoutLimit(t *testing.T) {
+	app := newTestApp(&mockStudentModel{})
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/v1/students", app.listStudentsHandler)
+
+	w := performRequest(router, "GET", "/v1/students?offset=2", nil)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected %d, got %d", http.StatusUnprocessableEntity, w.Code)
+	}
+}
+
 func TestUpdateStudentHandler(t *testing.T) {
 	mock := &mockStudentModel{
 		getFn: func(id int64) (*data.Student, error) {
 			return &data.Student{
-				ID: 1, Name: "Old Name", RollNo: 4,
+				ID: 1, Name: "Old Name", RollNo: 4, Status: data.StudentEnrolled,
 			}, nil
 		},
 		updateFn: func(s *data.Student) error { return nil },
@@ -176,3 +258,248 @@ func TestDeleteStudentHandler_NotFound(t *testing.T) {
 		t.Fatalf("expected %d, got %d", http.StatusNotFound, w.Code)
 	}
 }
+
+func TestExportStudentsHandler_CSV(t *testing.T) {
+	mock := &mockStudentModel{
+		eachFn: func(fn func(*data.Student) error) error {
+			for _, s := range []*data.Student{
+				{ID: 1, Name: "John", RollNo: 10, Status: "enrolled", Version: 1},
+				{ID: 2, Name: "Jane, Jr.", RollNo: 11, Email: "jane@example.com", Status: "graduated", Version: 3},
+			} {
+				if err := fn(s); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+
+	app := newTestApp(mock)
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/v1/students/export", app.exportStudentsHandler)
+
+	w := performRequest(router, "GET", "/v1/students/export?format=csv", nil)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, w.Code)
+	}
+
+	want := "id,name,rollno,email,phone,date_of_birth,address,status,version\n" +
+		"1,John,10,,,,,enrolled,1\n" +
+		"2,\"Jane, Jr.\",11,jane@example.com,,,,graduated,3\n"
+	if got := w.Body.String(); got != want {
+		t.Fatalf("expected body %q, got %q", want, got)
+	}
+}
+
+func TestExportStudentsHandler_NDJSON(t *testing.T) {
+	mock := &mockStudentModel{
+		eachFn: func(fn func(*data.Student) error) error {
+			return fn(&data.Student{ID: 1, Name: "=John", RollNo: 10, Status: "enrolled", Version: 1})
+		},
+	}
+
+	app := newTestApp(mock)
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/v1/students/export", app.exportStudentsHandler)
+
+	w := performRequest(router, "GET", "/v1/students/export?format=ndjson", nil)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, w.Code)
+	}
+
+	if got := w.Header().Get("Content-Type"); got != "application/x-ndjson" {
+		t.Fatalf("unexpected content type %q", got)
+	}
+
+	var student data.Student
+	if err := json.Unmarshal(w.Body.Bytes(), &student); err != nil {
+		t.Fatal(err)
+	}
+
+	// NDJSON is not opened in spreadsheets, so values are exported unchanged.
+	if student.ID != 1 || student.Name != "=John" || student.Status != "enrolled" {
+		t.Fatalf("unexpected student %+v", student)
+	}
+}
+
+func TestExportStudentsHandler_XLSX(t *testing.T) {
+	mock := &mockStudentModel{
+		eachFn: func(fn func(*data.Student) error) error {
+			return fn(&data.Student{ID: 1, Name: "John", RollNo: 10, Address: "@SUM(A1)", Status: "enrolled", Version: 1})
+		},
+	}
+
+	app := newTestApp(mock)
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/v1/students/export", app.exportStudentsHandler)
+
+	w := performRequest(router, "GET", "/v1/students/export?format=xlsx", nil)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, w.Code)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := zr.Open("xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	sheet, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`<c r="A2"><v>1</v></c>`,
+		`<t xml:space="preserve">John</t>`,
+		`<t xml:space="preserve">&#39;@SUM(A1)</t>`,
+	} {
+		if !bytes.Contains(sheet, []byte(want)) {
+			t.Errorf("sheet is missing %s:\n%s", want, sheet)
+		}
+	}
+}
+
+func TestExportStudentsHandler_EscapesFormulas(t *testing.T) {
+	mock := &mockStudentModel{
+		eachFn: func(fn func(*data.Student) error) error {
+			return fn(&data.Student{ID: 1, Name: "=HYPERLINK(\"x\")", RollNo: 10, Phone: "+15550100", Address: "-1", Status: "enrolled", Version: 1})
+		},
+	}
+
+	app := newTestApp(mock)
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/v1/students/export", app.exportStudentsHandler)
+
+	w := performRequest(router, "GET", "/v1/students/export?format=csv", nil)
+
+	want := "id,name,rollno,email,phone,date_of_birth,address,status,version\n" +
+		"1,\"'=HYPERLINK(\"\"x\"\")\",10,,'+15550100,,'-1,enrolled,1\n"
+	if got := w.Body.String(); got != want {
+		t.Fatalf("expected body %q, got %q", want, got)
+	}
+
+	// Importing the export gives back the original values.
+	var inserted []*data.Student
+	mock.manyFn = func(s []*data.Student) error {
+		inserted = s
+		return nil
+	}
+
+	router.POST("/v1/students/import", app.importStudentsHandler)
+
+	w = performRequest(router, "POST", "/v1/students/import", w.Body.Bytes())
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected %d, got %d: %s", http.StatusCreated, w.Code, w.Body)
+	}
+
+	if len(inserted) != 1 || inserted[0].Name != "=HYPERLINK(\"x\")" || inserted[0].Phone != "+15550100" || inserted[0].Address != "-1" {
+		t.Fatalf("unexpected inserted students: %+v", inserted)
+	}
+}
+
+func TestImportStudentsHandler_DryRunReportsLineErrors(t *testing.T) {
+	mock := &mockStudentModel{
+		manyFn: func(s []*data.Student) error {
+			t.Fatal("dry run must not insert")
+			return nil
+		},
+	}
+
+	app := newTestApp(mock)
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/v1/students/import", app.importStudentsHandler)
+
+	body := []byte("Full Name,Roll,Email\nJohn,10,john@example.com\n,11,\nJane,12,not-an-email\n")
+	w := performRequest(router, "POST", "/v1/students/import?dry_run=true&map[name]=Full%20Name&map[rollno]=Roll", body)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var resp struct {
+		Report importReport `json:"report"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.Report.Total != 3 || resp.Report.Valid != 1 || len(resp.Report.Errors) != 2 {
+		t.Fatalf("unexpected report: %+v", resp.Report)
+	}
+
+	if resp.Report.Errors[0].Line != 3 || resp.Report.Errors[1].Line != 4 {
+		t.Fatalf("unexpected error lines: %+v", resp.Report.Errors)
+	}
+}
+
+func TestImportStudentsHandler_Commit(t *testing.T) {
+	var inserted []*data.Student
+
+	mock := &mockStudentModel{
+		manyFn: func(s []*data.Student) error {
+			inserted = s
+			return nil
+		},
+	}
+
+	app := newTestApp(mock)
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/v1/students/import", app.importStudentsHandler)
+
+	body := []byte("name,rollno\nJohn,10\nJane,11\n")
+	w := performRequest(router, "POST", "/v1/students/import", body)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected %d, got %d", http.StatusCreated, w.Code)
+	}
+
+	if len(inserted) != 2 || inserted[1].Name != "Jane" || inserted[1].RollNo != 11 {
+		t.Fatalf("unexpected inserted students: %+v", inserted)
+	}
+}
+
+func TestCreateStudentHandler_InvalidProfile(t *testing.T) {
+	app := newTestApp(&mockStudentModel{})
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/v1/students", app.createStudentHandler)
+
+	tests := []string{
+		`{"name":"John Doe","rollno":10,"email":"john.example.com"}`,
+		`{"name":"John Doe","rollno":10,"phone":"call me"}`,
+		`{"name":"John Doe","rollno":10,"date_of_birth":"2999-01-01"}`,
+		`{"name":"John Doe","rollno":10,"status":"expelled"}`,
+		`{"name":"John Doe","rollno":10,"status":"graduated"}`,
+	}
+
+	for _, body := range tests {
+		w := performRequest(router, "POST", "/v1/students", []byte(body))
+
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("%s: expected %d, got %d", body, http.StatusUnprocessableEntity, w.Code)
+		}
+	}
+}
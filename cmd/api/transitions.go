@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/validator"
+)
+
+func (app *application) createTransitionHandler(c *gin.Context) {
+	studentID, err := app.readIDParam(c, "id")
+	if err != nil {
+		app.notFoundResponse(c)
+		return
+	}
+
+	var input struct {
+		To      string `json:"to"`
+		Reason  string `json:"reason"`
+		Actor   string `json:"actor"`
+		Version *int32 `json:"version"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		app.badRequestResponse(c, err)
+		return
+	}
+
+	student, err := app.models.Students.Get(studentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(c)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return
+	}
+
+	transition := &data.StudentTransition{
+		StudentID:  studentID,
+		FromStatus: student.Status,
+		ToStatus:   input.To,
+		Reason:     input.Reason,
+		Actor:      input.Actor,
+	}
+
+	v := validator.New()
+
+	if data.ValidateStudentTransition(v, transition); !v.Valid() {
+		app.failedValidationResponse(c, v.Errors)
+		return
+	}
+
+	if input.Version != nil && *input.Version != student.Version {
+		app.editConflictResponse(c)
+		return
+	}
+
+	if !data.CanTransition(transition.FromStatus, transition.ToStatus) {
+		app.invalidTransitionResponse(c, transition.FromStatus, transition.ToStatus)
+		return
+	}
+
+	promoted, err := app.models.Transitions.Apply(student, transition)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(c)
+		case errors.Is(err, data.ErrInvalidTransition):
+			app.invalidTransitionResponse(c, transition.FromStatus, transition.ToStatus)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return
+	}
+
+	for _, e := range promoted {
+		app.logger.PrintInfo("waitlisted student promoted", map[string]string{
+			"course_id":  strconv.FormatInt(e.CourseID, 10),
+			"student_id": strconv.FormatInt(e.StudentID, 10),
+		})
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"student":    student,
+		"transition": transition,
+	})
+}
+
+func (app *application) listTransitionsHandler(c *gin.Context) {
+	studentID, err := app.readIDParam(c, "id")
+	if err != nil {
+		app.notFoundResponse(c)
+		return
+	}
+
+	student, err := app.models.Students.Get(studentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(c)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return
+	}
+
+	transitions, err := app.models.Transitions.ListForStudent(studentID)
+	if err != nil {
+		app.serverErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":              student.Status,
+		"allowed_transitions": data.AllowedTransitions(student.Status),
+		"transitions":         transitions,
+	})
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+)
+
+type mockTransitionModel struct {
+	applyFn func(s *data.Student, t *data.StudentTransition) ([]*data.Enrollment, error)
+	listFn  func(studentID int64) ([]*data.StudentTransition, error)
+}
+
+func (m *mockTransitionModel) Apply(s *data.Student, t *data.StudentTransition) ([]*data.Enrollment, error) {
+	return m.applyFn(s, t)
+}
+
+func (m *mockTransitionModel) ListForStudent(studentID int64) ([]*data.StudentTransition, error) {
+	return m.listFn(studentID)
+}
+
+func studentWithStatus(status string) func(id int64) (*data.Student, error) {
+	return func(id int64) (*data.Student, error) {
+		return &data.Student{ID: id, Name: "Bob", RollNo: 22, Status: status, Version: 3}, nil
+	}
+}
+
+func TestCreateTransitionHandler_Withdrawal(t *testing.T) {
+	app := newTestApp(&mockStudentModel{getFn: studentWithStatus(data.StudentEnrolled)})
+	app.models.Transitions = &mockTransitionModel{
+		applyFn: func(s *data.Student, t *data.StudentTransition) ([]*data.Enrollment, error) {
+			s.Status = t.ToStatus
+			s.Version++
+			return []*data.Enrollment{{ID: 9, CourseID: 3, StudentID: 7, Status: data.EnrollmentEnrolled}}, nil
+		},
+	}
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/v1/students/:id/transitions", app.createTransitionHandler)
+
+	body := []byte(`{"to":"withdrawn","reason":"moved abroad","actor":"registrar@example.com"}`)
+	w := performRequest(router, "POST", "/v1/students/1/transitions", body)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+}
+
+func TestCreateTransitionHandler_FailedWithdrawal(t *testing.T) {
+	app := newTestApp(&mockStudentModel{getFn: studentWithStatus(data.StudentEnrolled)})
+	app.models.Transitions = &mockTransitionModel{
+		applyFn: func(s *data.Student, t *data.StudentTransition) ([]*data.Enrollment, error) {
+			return nil, errors.New("lock timeout")
+		},
+	}
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/v1/students/:id/transitions", app.createTransitionHandler)
+
+	body := []byte(`{"to":"withdrawn","reason":"moved abroad","actor":"registrar@example.com"}`)
+	w := performRequest(router, "POST", "/v1/students/1/transitions", body)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+}
+
+func TestCreateTransitionHandler_IllegalTransition(t *testing.T) {
+	app := newTestApp(&mockStudentModel{getFn: studentWithStatus(data.StudentGraduated)})
+	app.models.Transitions = &mockTransitionModel{
+		applyFn: func(s *data.Student, t *data.StudentTransition) ([]*data.Enrollment, error) {
+			panic("illegal transitions must not reach the store")
+		},
+	}
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/v1/students/:id/transitions", app.createTransitionHandler)
+
+	body := []byte(`{"to":"enrolled","reason":"re-admitted","actor":"registrar@example.com"}`)
+	w := performRequest(router, "POST", "/v1/students/1/transitions", body)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected %d, got %d", http.StatusUnprocessableEntity, w.Code)
+	}
+
+	var resp struct {
+		Error struct {
+			Allowed []string `json:"allowed_transitions"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.Error.Allowed == nil || len(resp.Error.Allowed) != 0 {
+		t.Fatalf("expected an empty list of allowed transitions, got %v", resp.Error.Allowed)
+	}
+}
+
+func TestUpdateStudentHandler_RejectsStatus(t *testing.T) {
+	app := newTestApp(&mockStudentModel{
+		getFn:    studentWithStatus(data.StudentEnrolled),
+		updateFn: func(s *data.Student) error { panic("status changes must not be saved") },
+	})
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.PATCH("/v1/students/:id", app.updateStudentHandler)
+
+	w := performRequest(router, "PATCH", "/v1/students/1", []byte(`{"status":"graduated"}`))
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected %d, got %d", http.StatusUnprocessableEntity, w.Code)
+	}
+}
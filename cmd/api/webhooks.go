@@ -0,0 +1,295 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/validator"
+	"github.com/sai29/one2n_sre_bootcamp/internal/webhook"
+)
+
+func (app *application) createWebhookHandler(c *gin.Context) {
+	var input struct {
+		URL        string   `json:"url"`
+		EventTypes []string `json:"event_types"`
+		Active     *bool    `json:"active"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		app.badRequestResponse(c, err)
+		return
+	}
+
+	hook := &data.Webhook{
+		URL:        input.URL,
+		EventTypes: input.EventTypes,
+		Active:     input.Active == nil || *input.Active,
+	}
+
+	v := validator.New()
+
+	if app.validateWebhook(v, hook); !v.Valid() {
+		app.failedValidationResponse(c, v.Errors)
+		return
+	}
+
+	secret, err := data.NewWebhookSecret()
+	if err != nil {
+		app.serverErrorResponse(c, err)
+		return
+	}
+	hook.Secret = secret
+
+	if err := app.models.Webhooks.Insert(hook); err != nil {
+		app.serverErrorResponse(c, err)
+		return
+	}
+
+	location := fmt.Sprintf("/v1/webhooks/%d", hook.ID)
+
+	// This is the only response that includes the signing secret.
+	c.Header("Location", location)
+	c.JSON(http.StatusCreated, gin.H{
+		"webhook": hook,
+	})
+}
+
+func (app *application) listWebhooksHandler(c *gin.Context) {
+	webhooks, err := app.models.Webhooks.ListAll()
+	if err != nil {
+		app.serverErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"webhooks": webhooks,
+	})
+}
+
+func (app *application) showWebhookHandler(c *gin.Context) {
+	id, err := app.readIDParam(c, "id")
+	if err != nil {
+		app.notFoundResponse(c)
+		return
+	}
+
+	hook, err := app.models.Webhooks.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(c)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"webhook": hook,
+	})
+}
+
+func (app *application) updateWebhookHandler(c *gin.Context) {
+	id, err := app.readIDParam(c, "id")
+	if err != nil {
+		app.notFoundResponse(c)
+		return
+	}
+
+	hook, err := app.models.Webhooks.Get(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(c)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return
+	}
+
+	var input struct {
+		URL        *string  `json:"url"`
+		EventTypes []string `json:"event_types"`
+		Active     *bool    `json:"active"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		app.badRequestResponse(c, err)
+		return
+	}
+
+	if input.URL != nil {
+		hook.URL = *input.URL
+	}
+
+	if input.EventTypes != nil {
+		hook.EventTypes = input.EventTypes
+	}
+
+	if input.Active != nil {
+		hook.Active = *input.Active
+	}
+
+	v := validator.New()
+
+	if app.validateWebhook(v, hook); !v.Valid() {
+		app.failedValidationResponse(c, v.Errors)
+		return
+	}
+
+	if err := app.models.Webhooks.Update(hook); err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(c)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"webhook": hook})
+}
+
+func (app *application) deleteWebhookHandler(c *gin.Context) {
+	id, err := app.readIDParam(c, "id")
+	if err != nil {
+		app.notFoundResponse(c)
+		return
+	}
+
+	err = app.models.Webhooks.Delete(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(c)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "webhook deleted",
+	})
+}
+
+// listWebhookDeliveriesHandler returns the delivery log, newest first. ?limit= caps the
+// number of entries (default 100, at most 1000).
+func (app *application) listWebhookDeliveriesHandler(c *gin.Context) {
+	id, err := app.readIDParam(c, "id")
+	if err != nil {
+		app.notFoundResponse(c)
+		return
+	}
+
+	limit := 100
+
+	if s := c.Query("limit"); s != "" {
+		v := validator.New()
+
+		limit, err = strconv.Atoi(s)
+		v.Check(err == nil && limit >= 1 && limit <= 1000, "limit", "must be an integer between 1 and 1000")
+
+		if !v.Valid() {
+			app.failedValidationResponse(c, v.Errors)
+			return
+		}
+	}
+
+	if _, err := app.models.Webhooks.Get(id); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(c)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return
+	}
+
+	deliveries, err := app.models.Webhooks.ListDeliveries(id, limit)
+	if err != nil {
+		app.serverErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deliveries": deliveries,
+	})
+}
+
+// redeliverWebhookHandler queues a delivery again with a fresh set of attempts, for
+// example once a dead-lettered endpoint has been fixed.
+func (app *application) redeliverWebhookHandler(c *gin.Context) {
+	id, err := app.readIDParam(c, "id")
+	if err != nil {
+		app.notFoundResponse(c)
+		return
+	}
+
+	deliveryID, err := app.readIDParam(c, "delivery_id")
+	if err != nil {
+		app.notFoundResponse(c)
+		return
+	}
+
+	delivery, err := app.models.Webhooks.Redeliver(id, deliveryID)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(c)
+		default:
+			app.serverErrorResponse(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"delivery": delivery,
+	})
+}
+
+// validateWebhook applies data.ValidateWebhook and, unless private addresses are allowed,
+// refuses URLs that point at this network by IP or localhost name. Hosts given by any
+// other name are checked again each time a delivery is sent.
+func (app *application) validateWebhook(v *validator.Validator, hook *data.Webhook) {
+	data.ValidateWebhook(v, hook)
+
+	if !app.config.Webhooks.AllowPrivate && hook.URL != "" {
+		v.Check(webhook.PublicHost(hook.URL), "url", "must not point to a loopback, private or link-local address")
+	}
+}
+
+// startWebhookDispatcher runs the delivery workers as a background task; they stop when
+// serve closes app.shutdown.
+func (app *application) startWebhookDispatcher() {
+	if app.models.Webhooks == nil {
+		return
+	}
+
+	dispatcher := &webhook.Dispatcher{
+		Store:       app.models.Webhooks,
+		Client:      webhook.NewClient(app.config.Webhooks.Timeout, app.config.Webhooks.AllowPrivate),
+		Workers:     app.config.Webhooks.Workers,
+		Interval:    time.Second,
+		MaxAttempts: app.config.Webhooks.MaxAttempts,
+		BaseBackoff: app.config.Webhooks.Backoff,
+		MaxBackoff:  time.Hour,
+		Logger:      app.logger,
+		OnAttempt: func(d *data.WebhookDelivery) {
+			webhookDeliveriesTotal.WithLabelValues(d.Status).Inc()
+		},
+	}
+
+	app.background(func() {
+		dispatcher.Run(app.shutdown)
+	})
+
+	app.logger.PrintInfo("webhook dispatcher started", map[string]string{
+		"workers": strconv.Itoa(dispatcher.Workers),
+	})
+}
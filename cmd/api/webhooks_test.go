@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+)
+
+type mockWebhookModel struct {
+	insertFn         func(w *data.Webhook) error
+	getFn            func(id int64) (*data.Webhook, error)
+	listDeliveriesFn func(webhookID int64, limit int) ([]*data.WebhookDelivery, error)
+	redeliverFn      func(webhookID, id int64) (*data.WebhookDelivery, error)
+}
+
+func (m *mockWebhookModel) Insert(w *data.Webhook) error {
+	return m.insertFn(w)
+}
+
+func (m *mockWebhookModel) Get(id int64) (*data.Webhook, error) {
+	return m.getFn(id)
+}
+
+func (m *mockWebhookModel) ListAll() ([]*data.Webhook, error) {
+	return nil, nil
+}
+
+func (m *mockWebhookModel) Update(w *data.Webhook) error {
+	return nil
+}
+
+func (m *mockWebhookModel) Delete(id int64) error {
+	return nil
+}
+
+func (m *mockWebhookModel) Enqueue(ctx context.Context, eventID int64, eventType string, payload []byte) (int, error) {
+	return 0, nil
+}
+
+func (m *mockWebhookModel) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*data.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func (m *mockWebhookModel) RecordAttempt(ctx context.Context, delivery *data.WebhookDelivery) error {
+	return nil
+}
+
+func (m *mockWebhookModel) ListDeliveries(webhookID int64, limit int) ([]*data.WebhookDelivery, error) {
+	return m.listDeliveriesFn(webhookID, limit)
+}
+
+func (m *mockWebhookModel) Redeliver(webhookID, id int64) (*data.WebhookDelivery, error) {
+	return m.redeliverFn(webhookID, id)
+}
+
+func TestCreateWebhookHandler(t *testing.T) {
+	var inserted *data.Webhook
+
+	mock := &mockWebhookModel{
+		insertFn: func(w *data.Webhook) error {
+			w.ID = 1
+			inserted = w
+			return nil
+		},
+	}
+
+	app := newTestApp(&mockStudentModel{})
+	app.models.Webhooks = mock
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/v1/webhooks", app.createWebhookHandler)
+
+	body := []byte(`{"url":"https://example.com/hooks","event_types":["student.created","student.deleted"]}`)
+	w := performRequest(router, "POST", "/v1/webhooks", body)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	if !inserted.Active || !strings.HasPrefix(inserted.Secret, "whsec_") {
+		t.Fatalf("expected an active webhook with a generated secret, got %+v", inserted)
+	}
+
+	var res struct {
+		Webhook data.Webhook `json:"webhook"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Webhook.Secret != inserted.Secret {
+		t.Fatal("expected the secret in the create response")
+	}
+}
+
+func TestCreateWebhookHandler_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		field string
+	}{
+		{"relative url", `{"url":"/hooks","event_types":["student.created"]}`, "url"},
+		{"unsupported scheme", `{"url":"ftp://example.com","event_types":["student.created"]}`, "url"},
+		{"no event types", `{"url":"https://example.com","event_types":[]}`, "event_types"},
+		{"unknown event type", `{"url":"https://example.com","event_types":["course.created"]}`, "event_types"},
+		{"duplicate event type", `{"url":"https://example.com","event_types":["student.created","student.created"]}`, "event_types"},
+		{"loopback url", `{"url":"http://127.0.0.1:8080/hooks","event_types":["student.created"]}`, "url"},
+		{"metadata url", `{"url":"http://169.254.169.254/latest","event_types":["student.created"]}`, "url"},
+	}
+
+	app := newTestApp(&mockStudentModel{})
+	app.models.Webhooks = &mockWebhookModel{}
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/v1/webhooks", app.createWebhookHandler)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performRequest(router, "POST", "/v1/webhooks", []byte(tt.body))
+
+			if w.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("expected %d, got %d", http.StatusUnprocessableEntity, w.Code)
+			}
+
+			if !strings.Contains(w.Body.String(), `"`+tt.field+`"`) {
+				t.Fatalf("expected an error for %s, got %s", tt.field, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestListWebhookDeliveriesHandler(t *testing.T) {
+	var gotLimit int
+
+	mock := &mockWebhookModel{
+		getFn: func(id int64) (*data.Webhook, error) {
+			if id != 1 {
+				return nil, data.ErrRecordNotFound
+			}
+			return &data.Webhook{ID: 1}, nil
+		},
+		listDeliveriesFn: func(webhookID int64, limit int) ([]*data.WebhookDelivery, error) {
+			gotLimit = limit
+			return []*data.WebhookDelivery{{ID: 3, WebhookID: webhookID, Status: data.DeliveryDead}}, nil
+		},
+	}
+
+	app := newTestApp(&mockStudentModel{})
+	app.models.Webhooks = mock
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/v1/webhooks/:id/deliveries", app.listWebhookDeliveriesHandler)
+
+	w := performRequest(router, "GET", "/v1/webhooks/1/deliveries?limit=20", nil)
+	if w.Code != http.StatusOK || gotLimit != 20 {
+		t.Fatalf("expected 200 with limit 20, got %d with limit %d", w.Code, gotLimit)
+	}
+
+	w = performRequest(router, "GET", "/v1/webhooks/1/deliveries?limit=0", nil)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected %d, got %d", http.StatusUnprocessableEntity, w.Code)
+	}
+
+	w = performRequest(router, "GET", "/v1/webhooks/2/deliveries", nil)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestRedeliverWebhookHandler(t *testing.T) {
+	mock := &mockWebhookModel{
+		redeliverFn: func(webhookID, id int64) (*data.WebhookDelivery, error) {
+			if webhookID != 1 || id != 3 {
+				return nil, data.ErrRecordNotFound
+			}
+			return &data.WebhookDelivery{ID: 3, WebhookID: 1, Status: data.DeliveryPending}, nil
+		},
+	}
+
+	app := newTestApp(&mockStudentModel{})
+	app.models.Webhooks = mock
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/v1/webhooks/:id/deliveries/:delivery_id/redeliver", app.redeliverWebhookHandler)
+
+	w := performRequest(router, "POST", "/v1/webhooks/1/deliveries/3/redeliver", nil)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected %d, got %d", http.StatusAccepted, w.Code)
+	}
+
+	w = performRequest(router, "POST", "/v1/webhooks/2/deliveries/3/redeliver", nil)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
@@ -0,0 +1,376 @@
+// Command loadgen sends a weighted mix of student create, get, list, update and delete
+// requests to the Student API at a fixed rate, then reports latency percentiles and
+// error rates and checks them against SLOs. The students it creates are deleted at the
+// end.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/sai29/one2n_sre_bootcamp/pkg/client"
+)
+
+const usage = `Usage: loadgen [flags]
+
+Sends -rps requests a second for -duration, picking each operation at random with the
+weights in -mix. get, update and delete only touch students loadgen created, and those
+are deleted when the run ends. Exits 1 if an SLO is not met.
+
+Flags:
+`
+
+var errSLO = errors.New("SLO not met")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "loadgen:", err)
+		os.Exit(1)
+	}
+}
+
+type config struct {
+	endpoint       string
+	token          string
+	rps            float64
+	duration       time.Duration
+	mix            string
+	concurrency    int
+	preload        int
+	seed           uint64
+	requestTimeout time.Duration
+	listLimit      int
+	out            string
+	keep           bool
+	slo            slo
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	var cfg config
+
+	fs := flag.NewFlagSet("loadgen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&cfg.endpoint, "endpoint", "http://localhost:4000", "API URL")
+	fs.StringVar(&cfg.token, "token", "", "Bearer token sent with every request")
+	fs.Float64Var(&cfg.rps, "rps", 20, "Target requests per second")
+	fs.DurationVar(&cfg.duration, "duration", 30*time.Second, "How long to send traffic for")
+	fs.StringVar(&cfg.mix, "mix", defaultMix, "Operation weights")
+	fs.IntVar(&cfg.concurrency, "concurrency", 64, "Maximum requests in flight; requests over it are dropped and counted")
+	fs.IntVar(&cfg.preload, "preload", 50, "Students to create, unmeasured, before the run")
+	fs.Uint64Var(&cfg.seed, "seed", 0, "Seed for the operation mix (default random)")
+	fs.DurationVar(&cfg.requestTimeout, "request-timeout", 5*time.Second, "Time limit for each request")
+	fs.IntVar(&cfg.listLimit, "list-limit", 50, "Page size for list requests (1-1000)")
+	fs.StringVar(&cfg.out, "out", "", "Write the results as JSON to this file")
+	fs.BoolVar(&cfg.keep, "keep", false, "Keep the students created by the run")
+	fs.DurationVar(&cfg.slo.p95, "slo-p95", 0, "p95 latency objective (0 disables)")
+	fs.DurationVar(&cfg.slo.p99, "slo-p99", 500*time.Millisecond, "p99 latency objective (0 disables)")
+	fs.Float64Var(&cfg.slo.errorRate, "slo-error-rate", 0.01, "Error rate objective, as a fraction (0 disables)")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	m, err := parseMix(cfg.mix)
+	if err != nil {
+		return fmt.Errorf("-mix: %w", err)
+	}
+
+	switch {
+	case cfg.rps <= 0:
+		return errors.New("-rps must be positive")
+	case cfg.duration <= 0:
+		return errors.New("-duration must be positive")
+	case cfg.concurrency < 1:
+		return errors.New("-concurrency must be at least 1")
+	case cfg.preload < 0:
+		return errors.New("-preload must not be negative")
+	case cfg.listLimit < 1 || cfg.listLimit > 1000:
+		return errors.New("-list-limit must be between 1 and 1000")
+	}
+
+	if cfg.seed == 0 {
+		cfg.seed = rand.Uint64()
+	}
+
+	g, err := newLoadgen(cfg, m)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	res, err := g.run(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := res.print(stdout); err != nil {
+		return err
+	}
+
+	if cfg.out != "" {
+		if err := writeResult(cfg.out, res); err != nil {
+			return err
+		}
+	}
+
+	if !res.SLO.Passed {
+		return errSLO
+	}
+
+	return nil
+}
+
+func writeResult(path string, res *result) error {
+	b, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
+
+type loadgen struct {
+	cfg config
+	mix *mix
+	rng *rand.Rand
+
+	// api sends the measured requests, without retries so that every failure counts.
+	// setup, for the preload and cleanup, retries.
+	api   *client.Client
+	setup *client.Client
+
+	pool  pool
+	rec   *recorder
+	runID string
+	seq   atomic.Int64
+}
+
+func newLoadgen(cfg config, m *mix) (*loadgen, error) {
+	opts := []client.Option{client.WithUserAgent("loadgen")}
+	if cfg.token != "" {
+		opts = append(opts, client.WithToken(cfg.token))
+	}
+
+	api, err := client.New(cfg.endpoint, append(opts, client.WithRetryPolicy(client.RetryPolicy{MaxAttempts: 1}))...)
+	if err != nil {
+		return nil, err
+	}
+
+	setup, err := client.New(cfg.endpoint, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &loadgen{
+		cfg:   cfg,
+		mix:   m,
+		rng:   rand.New(rand.NewPCG(cfg.seed, cfg.seed)),
+		api:   api,
+		setup: setup,
+		rec:   newRecorder(),
+		runID: strconv.FormatInt(time.Now().Unix(), 36),
+	}, nil
+}
+
+// run preloads students, sends traffic until the duration is up or ctx is cancelled, and
+// deletes what it created.
+func (g *loadgen) run(ctx context.Context) (*result, error) {
+	if err := g.preload(ctx); err != nil {
+		g.cleanup()
+		return nil, fmt.Errorf("preloading students: %w", err)
+	}
+
+	started := time.Now()
+	dropped := g.send(ctx)
+	elapsed := time.Since(started)
+
+	res := g.rec.summarize(elapsed, dropped)
+	res.StartedAt = started.UTC()
+	res.Endpoint = g.cfg.endpoint
+	res.Mix = g.cfg.mix
+	res.TargetRPS = g.cfg.rps
+	res.SLO = g.cfg.slo.evaluate(res)
+
+	if !g.cfg.keep {
+		res.Cleanup = g.cleanup()
+	}
+
+	return res, nil
+}
+
+func (g *loadgen) preload(ctx context.Context) error {
+	for range g.cfg.preload {
+		s, err := g.setup.CreateStudent(ctx, g.newStudent())
+		if err != nil {
+			return err
+		}
+		g.pool.put(s.ID)
+	}
+	return nil
+}
+
+// send schedules requests at the target rate and waits for them to finish. It returns
+// the number of requests it dropped because -concurrency were already in flight.
+func (g *loadgen) send(ctx context.Context) int {
+	interval := time.Duration(float64(time.Second) / g.cfg.rps)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	done := time.After(g.cfg.duration)
+	inflight := make(chan struct{}, g.cfg.concurrency)
+	dropped := 0
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return dropped
+		case <-done:
+			return dropped
+		case <-ticker.C:
+		}
+
+		op := g.mix.pick(g.rng)
+
+		var id int64
+		if op != opCreate && op != opList {
+			var ok bool
+			if id, ok = g.pool.take(g.rng); !ok {
+				op = opCreate
+			}
+		}
+
+		select {
+		case inflight <- struct{}{}:
+		default:
+			dropped++
+			if id != 0 {
+				g.pool.put(id)
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-inflight }()
+
+			g.do(op, id)
+		}()
+	}
+}
+
+// do sends one measured request. Requests already sent are allowed to finish when the
+// run is interrupted, so they use their own context.
+func (g *loadgen) do(op string, id int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), g.cfg.requestTimeout)
+	defer cancel()
+
+	var err error
+	start := time.Now()
+
+	switch op {
+	case opCreate:
+		var s *client.Student
+		if s, err = g.api.CreateStudent(ctx, g.newStudent()); err == nil {
+			id = s.ID
+		}
+
+	case opGet:
+		_, err = g.api.GetStudent(ctx, id)
+
+	case opList:
+		_, err = g.api.ListStudentsPage(ctx, g.cfg.listLimit, 0)
+
+	case opUpdate:
+		address := fmt.Sprintf("%d Load Street", g.seq.Add(1))
+		_, err = g.api.UpdateStudent(ctx, id, client.StudentUpdate{Address: &address})
+
+	case opDelete:
+		err = g.api.DeleteStudent(ctx, id)
+	}
+
+	g.rec.record(op, time.Since(start), err)
+
+	// Put the student back for later requests, unless it is gone.
+	if id != 0 && !(op == opDelete && err == nil) && !errors.Is(err, client.ErrNotFound) {
+		g.pool.put(id)
+	}
+}
+
+func (g *loadgen) newStudent() client.NewStudent {
+	n := g.seq.Add(1)
+
+	return client.NewStudent{
+		Name:   fmt.Sprintf("loadgen %s %d", g.runID, n),
+		RollNo: int32(n),
+		Email:  fmt.Sprintf("loadgen-%s-%d@example.com", g.runID, n),
+	}
+}
+
+// cleanup deletes every student the run created and has not deleted yet.
+func (g *loadgen) cleanup() cleanup {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var (
+		mu  sync.Mutex
+		out cleanup
+		wg  sync.WaitGroup
+	)
+
+	sem := make(chan struct{}, 8)
+
+	for _, id := range g.pool.drain() {
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			err := g.setup.DeleteStudent(ctx, id)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil && !errors.Is(err, client.ErrNotFound) {
+				out.Failed++
+				return
+			}
+			out.Deleted++
+		}()
+	}
+
+	wg.Wait()
+
+	return out
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeAPI is a minimal in-memory students API.
+type fakeAPI struct {
+	mu       sync.Mutex
+	nextID   int64
+	students map[int64]map[string]any
+	failGets bool
+}
+
+func newFakeAPI() *fakeAPI {
+	return &fakeAPI{students: map[int64]map[string]any{}}
+}
+
+func (f *fakeAPI) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	reply := func(w http.ResponseWriter, status int, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(v)
+	}
+
+	notFound := func(w http.ResponseWriter) {
+		reply(w, http.StatusNotFound, map[string]string{"error": "the requested resource could not be found"})
+	}
+
+	mux.HandleFunc("POST /v1/students", func(w http.ResponseWriter, r *http.Request) {
+		var s map[string]any
+		json.NewDecoder(r.Body).Decode(&s)
+
+		f.mu.Lock()
+		f.nextID++
+		s["id"] = f.nextID
+		f.students[f.nextID] = s
+		f.mu.Unlock()
+
+		reply(w, http.StatusCreated, map[string]any{"student": s})
+	})
+
+	mux.HandleFunc("GET /v1/students", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		students := []map[string]any{}
+		for _, s := range f.students {
+			students = append(students, s)
+		}
+		reply(w, http.StatusOK, map[string]any{"students": students})
+	})
+
+	mux.HandleFunc("/v1/students/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.ParseInt(r.PathValue("id"), 10, 64)
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		s, ok := f.students[id]
+		if !ok {
+			notFound(w)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			if f.failGets {
+				reply(w, http.StatusInternalServerError, map[string]string{"error": "the server encountered a problem"})
+				return
+			}
+			reply(w, http.StatusOK, map[string]any{"student": s})
+		case http.MethodPatch:
+			json.NewDecoder(r.Body).Decode(&s)
+			reply(w, http.StatusOK, map[string]any{"student": s})
+		case http.MethodDelete:
+			delete(f.students, id)
+			reply(w, http.StatusOK, map[string]string{"message": "student successfully deleted"})
+		}
+	})
+
+	return mux
+}
+
+func (f *fakeAPI) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.students)
+}
+
+func TestRun(t *testing.T) {
+	api := newFakeAPI()
+	srv := httptest.NewServer(api.handler())
+	defer srv.Close()
+
+	out := filepath.Join(t.TempDir(), "results.json")
+
+	var stdout, stderr strings.Builder
+	err := run([]string{
+		"-endpoint", srv.URL,
+		"-rps", "200",
+		"-duration", "500ms",
+		"-preload", "5",
+		"-seed", "42",
+		"-out", out,
+	}, &stdout, &stderr)
+	if err != nil {
+		t.Fatalf("run: %v\n%s%s", err, stdout.String(), stderr.String())
+	}
+
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var res result
+	if err := json.Unmarshal(b, &res); err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Requests < 20 || res.Errors != 0 {
+		t.Errorf("got %d requests with %d errors", res.Requests, res.Errors)
+	}
+	if len(res.Operations) != len(operations) {
+		t.Errorf("got %d operations in the results, want all %d", len(res.Operations), len(operations))
+	}
+	if !res.SLO.Passed {
+		t.Errorf("SLO failed: %+v", res.SLO)
+	}
+
+	if n := api.count(); n != 0 {
+		t.Errorf("%d students left after cleanup", n)
+	}
+	if res.Cleanup.Deleted == 0 || res.Cleanup.Failed != 0 {
+		t.Errorf("unexpected cleanup: %+v", res.Cleanup)
+	}
+
+	if !strings.Contains(stdout.String(), "p99_latency_ms") {
+		t.Errorf("report is missing the SLO table:\n%s", stdout.String())
+	}
+}
+
+func TestRunFailsSLO(t *testing.T) {
+	api := newFakeAPI()
+	api.failGets = true
+
+	srv := httptest.NewServer(api.handler())
+	defer srv.Close()
+
+	var stdout, stderr strings.Builder
+	err := run([]string{
+		"-endpoint", srv.URL,
+		"-rps", "200",
+		"-duration", "300ms",
+		"-preload", "5",
+		"-mix", "get=1",
+	}, &stdout, &stderr)
+	if err != errSLO {
+		t.Fatalf("got %v, want errSLO\n%s", err, stdout.String())
+	}
+
+	if !strings.Contains(stdout.String(), "500") {
+		t.Errorf("report does not show the 500s:\n%s", stdout.String())
+	}
+
+	// The students are cleaned up even though the run failed.
+	if n := api.count(); n != 0 {
+		t.Errorf("%d students left after cleanup", n)
+	}
+}
+
+func TestRunFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"-rps", "0"},
+		{"-mix", "get=x"},
+		{"-list-limit", "5000"},
+		{"extra"},
+	} {
+		var stdout, stderr strings.Builder
+		if err := run(args, &stdout, &stderr); err == nil {
+			t.Errorf("run(%v): expected an error", args)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// The operations loadgen sends. get, update and delete act on students loadgen created
+// itself, never on existing data.
+const (
+	opCreate = "create"
+	opGet    = "get"
+	opList   = "list"
+	opUpdate = "update"
+	opDelete = "delete"
+)
+
+var operations = []string{opCreate, opGet, opList, opUpdate, opDelete}
+
+const defaultMix = "create=15,get=50,list=15,update=15,delete=5"
+
+// mix picks operations at random in proportion to their weights.
+type mix struct {
+	ops     []string
+	weights []int
+	total   int
+}
+
+// parseMix parses a mix such as "create=1,get=4". Operations left out are not sent.
+func parseMix(s string) (*mix, error) {
+	m := &mix{}
+	seen := map[string]bool{}
+
+	for _, part := range strings.Split(s, ",") {
+		op, w, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid mix entry %q (must be operation=weight)", part)
+		}
+
+		if !isOperation(op) {
+			return nil, fmt.Errorf("unknown operation %q (must be one of %s)", op, strings.Join(operations, ", "))
+		}
+
+		if seen[op] {
+			return nil, fmt.Errorf("operation %q is listed twice", op)
+		}
+		seen[op] = true
+
+		weight, err := strconv.Atoi(w)
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("invalid weight %q for %s (must be a non-negative integer)", w, op)
+		}
+
+		if weight == 0 {
+			continue
+		}
+
+		m.ops = append(m.ops, op)
+		m.weights = append(m.weights, weight)
+		m.total += weight
+	}
+
+	if m.total == 0 {
+		return nil, fmt.Errorf("mix %q has no operations with a positive weight", s)
+	}
+
+	return m, nil
+}
+
+func isOperation(op string) bool {
+	for _, o := range operations {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *mix) pick(rng *rand.Rand) string {
+	n := rng.IntN(m.total)
+	for i, w := range m.weights {
+		if n < w {
+			return m.ops[i]
+		}
+		n -= w
+	}
+	return m.ops[len(m.ops)-1]
+}
+
+// pool holds the IDs of the students loadgen created and has not deleted. An operation
+// takes an ID out while it runs, so a student is never updated and deleted at once.
+type pool struct {
+	mu  sync.Mutex
+	ids []int64
+}
+
+func (p *pool) put(id int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.ids = append(p.ids, id)
+}
+
+// take removes and returns a random ID, or false if the pool is empty.
+func (p *pool) take(rng *rand.Rand) (int64, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.ids) == 0 {
+		return 0, false
+	}
+
+	i := rng.IntN(len(p.ids))
+	id := p.ids[i]
+	p.ids[i] = p.ids[len(p.ids)-1]
+	p.ids = p.ids[:len(p.ids)-1]
+
+	return id, true
+}
+
+// drain empties the pool and returns what was in it.
+func (p *pool) drain() []int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ids := p.ids
+	p.ids = nil
+
+	return ids
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func TestParseMix(t *testing.T) {
+	m, err := parseMix("create=1, get=3,delete=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m.total != 4 || len(m.ops) != 2 {
+		t.Errorf("got ops %v with total %d, want create and get with total 4", m.ops, m.total)
+	}
+
+	for _, bad := range []string{"", "create", "create=x", "create=-1", "fetch=1", "get=1,get=2", "create=0"} {
+		if _, err := parseMix(bad); err == nil {
+			t.Errorf("parseMix(%q): expected an error", bad)
+		}
+	}
+}
+
+func TestMixPick(t *testing.T) {
+	m, err := parseMix("create=1,get=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rng := rand.New(rand.NewPCG(1, 1))
+	counts := map[string]int{}
+	for range 10000 {
+		counts[m.pick(rng)]++
+	}
+
+	if counts[opCreate]+counts[opGet] != 10000 {
+		t.Fatalf("picked operations outside the mix: %v", counts)
+	}
+
+	// 1 in 4 should be creates; allow for randomness.
+	if counts[opCreate] < 2200 || counts[opCreate] > 2800 {
+		t.Errorf("got %d creates in 10000 picks, want about 2500", counts[opCreate])
+	}
+}
+
+func TestPool(t *testing.T) {
+	var p pool
+	rng := rand.New(rand.NewPCG(1, 1))
+
+	if _, ok := p.take(rng); ok {
+		t.Fatal("took an ID from an empty pool")
+	}
+
+	p.put(1)
+	p.put(2)
+
+	id, ok := p.take(rng)
+	if !ok || (id != 1 && id != 2) {
+		t.Fatalf("take = %d, %v", id, ok)
+	}
+
+	if rest := p.drain(); len(rest) != 1 || rest[0] == id {
+		t.Errorf("drain = %v, want the other ID", rest)
+	}
+	if rest := p.drain(); len(rest) != 0 {
+		t.Errorf("pool not empty after drain: %v", rest)
+	}
+}
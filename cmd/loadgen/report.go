@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sai29/one2n_sre_bootcamp/pkg/client"
+)
+
+// recorder collects the outcome of every measured request.
+type recorder struct {
+	mu  sync.Mutex
+	ops map[string]*opStats
+}
+
+type opStats struct {
+	latencies []time.Duration
+	errors    map[string]int
+}
+
+func newRecorder() *recorder {
+	return &recorder{ops: map[string]*opStats{}}
+}
+
+func (r *recorder) record(op string, d time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.ops[op]
+	if !ok {
+		s = &opStats{errors: map[string]int{}}
+		r.ops[op] = s
+	}
+
+	s.latencies = append(s.latencies, d)
+	if err != nil {
+		s.errors[errorKind(err)]++
+	}
+}
+
+// errorKind names an error by its status code, or as a timeout or network error.
+func errorKind(err error) string {
+	var apiErr *client.APIError
+	var validationErr *client.ValidationError
+
+	switch {
+	case errors.As(err, &apiErr):
+		return strconv.Itoa(apiErr.StatusCode)
+	case errors.As(err, &validationErr):
+		return "422"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	default:
+		return "network"
+	}
+}
+
+// result is the outcome of a run. It is written with -out, for CI to keep and compare.
+type result struct {
+	StartedAt   time.Time  `json:"started_at"`
+	Endpoint    string     `json:"endpoint"`
+	Mix         string     `json:"mix"`
+	Duration    float64    `json:"duration_seconds"`
+	TargetRPS   float64    `json:"target_rps"`
+	AchievedRPS float64    `json:"achieved_rps"`
+	Requests    int        `json:"requests"`
+	Errors      int        `json:"errors"`
+	ErrorRate   float64    `json:"error_rate"`
+	Dropped     int        `json:"dropped"`
+	Latency     latency    `json:"latency_ms"`
+	Operations  []opResult `json:"operations"`
+	SLO         sloResult  `json:"slo"`
+	Cleanup     cleanup    `json:"cleanup"`
+}
+
+type latency struct {
+	P50 float64 `json:"p50"`
+	P95 float64 `json:"p95"`
+	P99 float64 `json:"p99"`
+	Max float64 `json:"max"`
+}
+
+type opResult struct {
+	Operation string         `json:"operation"`
+	Requests  int            `json:"requests"`
+	Errors    int            `json:"errors"`
+	ErrorRate float64        `json:"error_rate"`
+	Latency   latency        `json:"latency_ms"`
+	ByKind    map[string]int `json:"errors_by_kind,omitempty"`
+}
+
+type cleanup struct {
+	Deleted int `json:"deleted"`
+	Failed  int `json:"failed"`
+}
+
+// summarize turns what was recorded into a result. elapsed is how long traffic was sent
+// for, and dropped the number of requests skipped because too many were in flight.
+func (r *recorder) summarize(elapsed time.Duration, dropped int) *result {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res := &result{Duration: elapsed.Seconds(), Dropped: dropped}
+	var all []time.Duration
+
+	for _, op := range operations {
+		s, ok := r.ops[op]
+		if !ok {
+			continue
+		}
+
+		or := opResult{
+			Operation: op,
+			Requests:  len(s.latencies),
+			Latency:   summarizeLatency(s.latencies),
+		}
+
+		for kind, n := range s.errors {
+			or.Errors += n
+			if or.ByKind == nil {
+				or.ByKind = map[string]int{}
+			}
+			or.ByKind[kind] = n
+		}
+		or.ErrorRate = rate(or.Errors, or.Requests)
+
+		res.Operations = append(res.Operations, or)
+		res.Requests += or.Requests
+		res.Errors += or.Errors
+		all = append(all, s.latencies...)
+	}
+
+	res.ErrorRate = rate(res.Errors, res.Requests)
+	res.Latency = summarizeLatency(all)
+	if elapsed > 0 {
+		res.AchievedRPS = float64(res.Requests) / elapsed.Seconds()
+	}
+
+	return res
+}
+
+func rate(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
+
+func summarizeLatency(ds []time.Duration) latency {
+	sorted := slices.Clone(ds)
+	slices.Sort(sorted)
+
+	return latency{
+		P50: ms(percentile(sorted, 50)),
+		P95: ms(percentile(sorted, 95)),
+		P99: ms(percentile(sorted, 99)),
+		Max: ms(percentile(sorted, 100)),
+	}
+}
+
+// percentile returns the nearest-rank percentile p of sorted.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
+
+func ms(d time.Duration) float64 {
+	return math.Round(float64(d)/float64(time.Microsecond)) / 1000
+}
+
+// slo holds the objectives a run is judged against. A zero objective is not checked.
+type slo struct {
+	p95       time.Duration
+	p99       time.Duration
+	errorRate float64
+}
+
+type sloResult struct {
+	Passed     bool        `json:"passed"`
+	Objectives []objective `json:"objectives"`
+}
+
+type objective struct {
+	Name      string  `json:"name"`
+	Threshold float64 `json:"threshold"`
+	Actual    float64 `json:"actual"`
+	Passed    bool    `json:"passed"`
+}
+
+func (s slo) evaluate(res *result) sloResult {
+	out := sloResult{Passed: true, Objectives: []objective{}}
+
+	check := func(name string, threshold, actual float64) {
+		o := objective{Name: name, Threshold: threshold, Actual: actual, Passed: actual <= threshold}
+		out.Objectives = append(out.Objectives, o)
+		out.Passed = out.Passed && o.Passed
+	}
+
+	if s.p95 > 0 {
+		check("p95_latency_ms", ms(s.p95), res.Latency.P95)
+	}
+	if s.p99 > 0 {
+		check("p99_latency_ms", ms(s.p99), res.Latency.P99)
+	}
+	if s.errorRate > 0 {
+		check("error_rate", s.errorRate, res.ErrorRate)
+	}
+
+	return out
+}
+
+// print writes res as tables for people.
+func (res *result) print(w io.Writer) error {
+	fmt.Fprintf(w, "%s: %.0f rps for %.1fs, %d requests (%.1f rps), %d dropped\n\n",
+		res.Endpoint, res.TargetRPS, res.Duration, res.Requests, res.AchievedRPS, res.Dropped)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "OPERATION\tREQUESTS\tERRORS\tERROR RATE\tP50\tP95\tP99\tMAX")
+
+	row := func(name string, requests, errors int, errorRate float64, l latency) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.2f%%\t%.1fms\t%.1fms\t%.1fms\t%.1fms\n",
+			name, requests, errors, errorRate*100, l.P50, l.P95, l.P99, l.Max)
+	}
+
+	for _, op := range res.Operations {
+		row(op.Operation, op.Requests, op.Errors, op.ErrorRate, op.Latency)
+	}
+	row("all", res.Requests, res.Errors, res.ErrorRate, res.Latency)
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, op := range res.Operations {
+		kinds := make([]string, 0, len(op.ByKind))
+		for kind := range op.ByKind {
+			kinds = append(kinds, kind)
+		}
+		sort.Strings(kinds)
+
+		for _, kind := range kinds {
+			fmt.Fprintf(w, "  %s: %d × %s\n", op.Operation, op.ByKind[kind], kind)
+		}
+	}
+
+	if len(res.SLO.Objectives) > 0 {
+		fmt.Fprintln(w)
+
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "OBJECTIVE\tTHRESHOLD\tACTUAL\tRESULT")
+		for _, o := range res.SLO.Objectives {
+			verdict := "pass"
+			if !o.Passed {
+				verdict = "FAIL"
+			}
+			fmt.Fprintf(tw, "%s\t%g\t%g\t%s\n", o.Name, o.Threshold, o.Actual, verdict)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if res.Dropped > 0 {
+		fmt.Fprintf(w, "\nwarning: %d requests were not sent because -concurrency requests were already in flight; the API is too slow for the target rate, or -concurrency is too low\n", res.Dropped)
+	}
+
+	fmt.Fprintf(w, "\ncleanup: deleted %d students, %d failed\n", res.Cleanup.Deleted, res.Cleanup.Failed)
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sai29/one2n_sre_bootcamp/pkg/client"
+)
+
+func TestPercentile(t *testing.T) {
+	var ds []time.Duration
+	for i := 1; i <= 100; i++ {
+		ds = append(ds, time.Duration(i)*time.Millisecond)
+	}
+
+	tests := []struct {
+		p    float64
+		want time.Duration
+	}{
+		{50, 50 * time.Millisecond},
+		{95, 95 * time.Millisecond},
+		{99, 99 * time.Millisecond},
+		{100, 100 * time.Millisecond},
+		{0, time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		if got := percentile(ds, tt.p); got != tt.want {
+			t.Errorf("p%v = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+
+	if got := percentile(nil, 99); got != 0 {
+		t.Errorf("p99 of nothing = %v, want 0", got)
+	}
+}
+
+func TestErrorKind(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{&client.APIError{StatusCode: 503}, "503"},
+		{fmt.Errorf("wrapped: %w", &client.APIError{StatusCode: 404}), "404"},
+		{&client.ValidationError{}, "422"},
+		{fmt.Errorf("client: %w", context.DeadlineExceeded), "timeout"},
+		{errors.New("connection refused"), "network"},
+	}
+
+	for _, tt := range tests {
+		if got := errorKind(tt.err); got != tt.want {
+			t.Errorf("errorKind(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestSummarizeAndSLO(t *testing.T) {
+	rec := newRecorder()
+
+	for i := 1; i <= 98; i++ {
+		rec.record(opGet, time.Duration(i)*time.Millisecond, nil)
+	}
+	rec.record(opGet, 900*time.Millisecond, &client.APIError{StatusCode: 503})
+	rec.record(opCreate, 10*time.Millisecond, &client.ValidationError{})
+
+	res := rec.summarize(10*time.Second, 3)
+
+	if res.Requests != 100 || res.Errors != 2 || res.ErrorRate != 0.02 {
+		t.Errorf("got %d requests, %d errors, rate %v", res.Requests, res.Errors, res.ErrorRate)
+	}
+	if res.AchievedRPS != 10 || res.Dropped != 3 {
+		t.Errorf("got %v rps and %d dropped", res.AchievedRPS, res.Dropped)
+	}
+
+	// Operations are listed in a fixed order.
+	if len(res.Operations) != 2 || res.Operations[0].Operation != opCreate {
+		t.Fatalf("unexpected operations: %+v", res.Operations)
+	}
+	if got := res.Operations[1].ByKind["503"]; got != 1 {
+		t.Errorf("get errors by kind = %v", res.Operations[1].ByKind)
+	}
+
+	slos := slo{p99: 500 * time.Millisecond, errorRate: 0.05}
+	if got := slos.evaluate(res); !got.Passed || len(got.Objectives) != 2 {
+		t.Errorf("expected both objectives to pass: %+v", got)
+	}
+
+	slos = slo{p95: 50 * time.Millisecond, errorRate: 0.01}
+	got := slos.evaluate(res)
+	if got.Passed {
+		t.Errorf("expected the SLO to fail: %+v", got)
+	}
+	for _, o := range got.Objectives {
+		if o.Passed {
+			t.Errorf("%s passed with %v against %v", o.Name, o.Actual, o.Threshold)
+		}
+	}
+
+	res.SLO = got
+
+	var out strings.Builder
+	if err := res.print(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"OPERATION", "all", "FAIL", "1 × 503", "3 requests were not sent"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("report is missing %q:\n%s", want, out.String())
+		}
+	}
+}
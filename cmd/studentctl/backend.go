@@ -0,0 +1,272 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/sai29/one2n_sre_bootcamp/internal/cache"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/migrate"
+	"github.com/sai29/one2n_sre_bootcamp/internal/validator"
+	"github.com/sai29/one2n_sre_bootcamp/migrations"
+	"github.com/sai29/one2n_sre_bootcamp/pkg/client"
+)
+
+// backend carries out the commands that work both against the API and, offline,
+// against the database.
+type backend interface {
+	listStudents(ctx context.Context, pageSize int) ([]*client.Student, error)
+	getStudent(ctx context.Context, id int64) (*client.Student, error)
+	createStudent(ctx context.Context, s client.NewStudent) (*client.Student, error)
+	deleteStudent(ctx context.Context, id int64) error
+	health(ctx context.Context) (*healthReport, error)
+	close() error
+}
+
+// healthReport is printed by the health command.
+type healthReport struct {
+	Target string `json:"target"`
+	Status string `json:"status"`
+	Env    string `json:"env,omitempty"`
+	Ready  bool   `json:"ready"`
+	Schema any    `json:"schema,omitempty"`
+}
+
+func (c *ctl) backend() (backend, error) {
+	if c.offline {
+		return openDBBackend(c.context.DatabaseURL, c.context.CacheRedisURL, c.cacheTTL)
+	}
+	return c.apiBackend()
+}
+
+func (c *ctl) apiClient() (*client.Client, error) {
+	// The client's own 30s limit would cut long imports and exports short; every command
+	// is bounded by its context instead.
+	opts := []client.Option{client.WithUserAgent("studentctl"), client.WithHTTPClient(&http.Client{})}
+	if c.context.Token != "" {
+		opts = append(opts, client.WithToken(c.context.Token))
+	}
+
+	return client.New(c.context.Endpoint, opts...)
+}
+
+func (c *ctl) apiBackend() (backend, error) {
+	api, err := c.apiClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return &apiBackend{api: api, endpoint: c.context.Endpoint}, nil
+}
+
+// === API ===
+
+type apiBackend struct {
+	api      *client.Client
+	endpoint string
+}
+
+func (b *apiBackend) listStudents(ctx context.Context, pageSize int) ([]*client.Student, error) {
+	students := []*client.Student{}
+
+	for s, err := range b.api.ListStudents(ctx, client.ListOptions{PageSize: pageSize}) {
+		if err != nil {
+			return nil, err
+		}
+		students = append(students, s)
+	}
+
+	return students, nil
+}
+
+func (b *apiBackend) getStudent(ctx context.Context, id int64) (*client.Student, error) {
+	return b.api.GetStudent(ctx, id)
+}
+
+func (b *apiBackend) createStudent(ctx context.Context, s client.NewStudent) (*client.Student, error) {
+	return b.api.CreateStudent(ctx, s)
+}
+
+func (b *apiBackend) deleteStudent(ctx context.Context, id int64) error {
+	return b.api.DeleteStudent(ctx, id)
+}
+
+func (b *apiBackend) health(ctx context.Context) (*healthReport, error) {
+	h, err := b.api.Health(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := b.api.Readiness(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &healthReport{Target: b.endpoint, Status: h.Status, Env: h.Env, Ready: r.Ready()}
+	if len(r.Schema) > 0 {
+		report.Schema = r.Schema
+	}
+
+	return report, nil
+}
+
+func (b *apiBackend) close() error { return nil }
+
+// === Database ===
+
+// dbBackend works on the database directly, for when the API is down. Writes go
+// through the same models as the API's, so they are validated the same way and still
+// recorded in the outbox. Given the API's Redis cache, they also invalidate it and set
+// the same version watermarks; the API's in-memory cache can't be reached, and keeps a
+// changed student until its TTL runs out.
+type dbBackend struct {
+	db     *sql.DB
+	cache  *cache.Redis
+	models data.Models
+}
+
+func openDBBackend(dsn, cacheRedisURL string, cacheTTL time.Duration) (backend, error) {
+	if dsn == "" {
+		return nil, errors.New("-offline needs a database: set database-url in the context, -db-dsn or DATABASE_URL")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	b := &dbBackend{db: db, models: data.NewModels(db)}
+
+	if cacheRedisURL != "" {
+		b.cache, err = cache.OpenRedis(ctx, cacheRedisURL, cache.StudentsPrefix)
+		if err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("connecting to the API's cache: %w", err)
+		}
+
+		b.models = data.WithStudentCache(b.models, b.cache, cacheTTL, nil)
+	}
+
+	return b, nil
+}
+
+func fromData(s *data.Student) *client.Student {
+	out := &client.Student{
+		ID:        s.ID,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+		Name:      s.Name,
+		RollNo:    s.RollNo,
+		Email:     s.Email,
+		Phone:     s.Phone,
+		Address:   s.Address,
+		Status:    s.Status,
+		Version:   s.Version,
+	}
+
+	if s.DateOfBirth != nil {
+		out.DateOfBirth = s.DateOfBirth.String()
+	}
+
+	return out
+}
+
+// notFound turns the models' not-found error into the client's, so that both backends
+// report it the same way.
+func notFound(err error) error {
+	if errors.Is(err, data.ErrRecordNotFound) {
+		return &client.APIError{StatusCode: 404, Message: "the requested resource could not be found"}
+	}
+	return err
+}
+
+func (b *dbBackend) listStudents(ctx context.Context, pageSize int) ([]*client.Student, error) {
+	students, err := b.models.Students.ListAll()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*client.Student, len(students))
+	for i, s := range students {
+		out[i] = fromData(s)
+	}
+
+	return out, nil
+}
+
+func (b *dbBackend) getStudent(ctx context.Context, id int64) (*client.Student, error) {
+	s, err := b.models.Students.Get(id)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	return fromData(s), nil
+}
+
+func (b *dbBackend) createStudent(ctx context.Context, in client.NewStudent) (*client.Student, error) {
+	v := validator.New()
+
+	s := data.StudentInput{
+		Name:        &in.Name,
+		RollNo:      &in.RollNo,
+		Email:       &in.Email,
+		Phone:       &in.Phone,
+		DateOfBirth: &in.DateOfBirth,
+		Address:     &in.Address,
+		Status:      &in.Status,
+	}.NewStudent(v)
+	if !v.Valid() {
+		return nil, &client.ValidationError{Fields: v.Errors}
+	}
+
+	if err := b.models.Students.Insert(s); err != nil {
+		return nil, err
+	}
+
+	return fromData(s), nil
+}
+
+func (b *dbBackend) deleteStudent(ctx context.Context, id int64) error {
+	return notFound(b.models.Students.Delete(id))
+}
+
+func (b *dbBackend) health(ctx context.Context) (*healthReport, error) {
+	m, err := migrate.New(b.db, migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+
+	version, dirty, err := m.Version(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reading the schema version: %w", err)
+	}
+
+	return &healthReport{
+		Target: "database",
+		Status: "available",
+		Ready:  version == m.Latest() && !dirty,
+		Schema: map[string]any{"version": version, "latest": m.Latest(), "dirty": dirty},
+	}, nil
+}
+
+func (b *dbBackend) close() error {
+	if b.cache != nil {
+		return errors.Join(b.cache.Close(), b.db.Close())
+	}
+	return b.db.Close()
+}
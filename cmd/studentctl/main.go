@@ -0,0 +1,202 @@
+// Command studentctl is an admin CLI for the Student API. It talks to the API at the
+// current context's endpoint or, with -offline, straight to its Postgres database.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/validator"
+)
+
+const usage = `Usage: studentctl [flags] <command> [args]
+
+Commands:
+  students list                  list students
+  students get ID                show a student
+  students create -name N -rollno R [flags]
+                                 create a student
+  students delete ID             delete a student
+  import [-dry-run] [-map field=column] [-wait] FILE
+                                 import students from a CSV file
+  export [-format csv|ndjson|xlsx] [-f FILE]
+                                 export every student
+  health                         check liveness and readiness; exits 1 if not ready
+  users create                   not supported: the API has no user accounts
+  tokens revoke                  not supported: gRPC tokens are static API configuration
+  config get-contexts            list the contexts in the config file
+  config current-context         show the current context
+  config use-context NAME        make NAME the current context
+
+Run "studentctl <command> -h" for a command's flags.
+
+Flags:
+`
+
+// ctl holds what every command needs: where to send it, how to print the result, and
+// where to print it.
+type ctl struct {
+	stdout io.Writer
+	stderr io.Writer
+
+	configPath string
+	config     *ctlConfig
+	context    ctlContext
+
+	output  string
+	offline bool
+	timeout time.Duration
+
+	// cacheRedisURL and cacheTTL let -offline writes invalidate the API's Redis cache.
+	cacheRedisURL string
+	cacheTTL      time.Duration
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "studentctl:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	c := &ctl{stdout: stdout, stderr: stderr}
+
+	var (
+		contextName string
+		endpoint    string
+		token       string
+		databaseURL string
+	)
+
+	fs := flag.NewFlagSet("studentctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&c.configPath, "config", defaultConfigPath(), "Config file (STUDENTCTL_CONFIG)")
+	fs.StringVar(&contextName, "context", "", "Context to use instead of the current one")
+	fs.StringVar(&endpoint, "endpoint", "", "API URL, overriding the context's")
+	fs.StringVar(&token, "token", "", "Bearer token, overriding the context's")
+	fs.BoolVar(&c.offline, "offline", false, "Use the database directly instead of the API")
+	fs.StringVar(&databaseURL, "db-dsn", "", "Database DSN for -offline, overriding the context's (default DATABASE_URL)")
+	fs.StringVar(&c.output, "o", outputTable, "Output format (table|json|yaml)")
+	fs.StringVar(&c.cacheRedisURL, "cache-redis-url", "", "Redis URL of the API's student cache, for -offline writes (default REDIS_URL)")
+	fs.DurationVar(&c.cacheTTL, "cache-ttl", time.Minute, "The API's -cache-ttl, for -offline writes")
+	fs.DurationVar(&c.timeout, "timeout", 30*time.Second, "Time limit for the command; import and export have none unless it is set (0 for none)")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	if !validator.PermittedValue(c.output, outputTable, outputJSON, outputYAML) {
+		return fmt.Errorf("invalid -o %q (must be table, json or yaml)", c.output)
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("missing command")
+	}
+
+	var err error
+	c.config, err = loadConfig(c.configPath)
+	if err != nil {
+		return err
+	}
+
+	c.context, err = c.config.context(contextName)
+	if err != nil {
+		return err
+	}
+
+	if endpoint != "" {
+		c.context.Endpoint = endpoint
+	}
+	if token != "" {
+		c.context.Token = token
+	}
+	if databaseURL != "" {
+		c.context.DatabaseURL = databaseURL
+	}
+	if c.context.Endpoint == "" {
+		c.context.Endpoint = "http://localhost:4000"
+	}
+	if c.context.DatabaseURL == "" {
+		c.context.DatabaseURL = os.Getenv("DATABASE_URL")
+	}
+	if c.cacheRedisURL != "" {
+		c.context.CacheRedisURL = c.cacheRedisURL
+	}
+	if c.context.CacheRedisURL == "" {
+		c.context.CacheRedisURL = os.Getenv("REDIS_URL")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	cmd, rest := fs.Arg(0), fs.Args()[1:]
+
+	// Imports and exports take as long as the data does, so the default time limit,
+	// which suits single requests, only applies to them when -timeout is given.
+	timeoutSet := false
+	fs.Visit(func(f *flag.Flag) { timeoutSet = timeoutSet || f.Name == "timeout" })
+
+	if c.timeout > 0 && (timeoutSet || cmd != "import" && cmd != "export") {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	switch cmd {
+	case "students":
+		return c.students(ctx, rest)
+	case "import":
+		return c.importStudents(ctx, rest)
+	case "export":
+		return c.exportStudents(ctx, rest)
+	case "health":
+		return c.health(ctx)
+	case "users":
+		return errors.New("users: the Student API has no user accounts, so there is nothing to create or manage")
+	case "tokens":
+		return errors.New("tokens: the Student API has no token store to revoke from; its gRPC server accepts the static tokens in -grpc-auth-tokens (GRPC_AUTH_TOKENS), so remove the token there and restart the API. The REST API does not check tokens; a context's token is forwarded for gateways in front of it and is revoked there")
+	case "config":
+		return c.configCommand(rest)
+	default:
+		fs.Usage()
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
+
+// subcommand returns a flag set for a command whose -h prints usage.
+func (c *ctl) subcommand(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: studentctl %s\n", usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse parses a subcommand's flags, reporting -h as success.
+func parse(fs *flag.FlagSet, args []string) (bool, error) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeAPI answers the few endpoints the tests need, recording the requests it gets.
+type fakeAPI struct {
+	requests []*http.Request
+	bodies   []string
+	ready    bool
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body := new(bytes.Buffer)
+	body.ReadFrom(r.Body)
+	f.requests = append(f.requests, r)
+	f.bodies = append(f.bodies, body.String())
+
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/students":
+		if r.URL.Query().Get("offset") != "0" {
+			w.Write([]byte(`{"students":[]}`))
+			return
+		}
+		w.Write([]byte(`{"students":[
+			{"id":1,"name":"Ada","rollno":7,"email":"ada@example.com","date_of_birth":"2001-02-03","status":"enrolled","version":1},
+			{"id":2,"name":"Alan","rollno":8,"status":"applicant","version":3}
+		]}`))
+
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/students/99":
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"the requested resource could not be found"}`))
+
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/students":
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"student":{"id":3,"name":"Grace","rollno":9,"status":"applicant","version":1}}`))
+
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/students/import":
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"error":"the file has invalid lines","report":{"dry_run":false,"total":2,"valid":1,"errors":[{"line":3,"errors":{"rollno":"must be a positive integer"}}]}}`))
+
+	case r.URL.Path == "/v1/healthcheck":
+		w.Write([]byte(`{"status":"available","env":"staging"}`))
+
+	case r.URL.Path == "/v1/readiness":
+		if !f.ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"status":"degraded","schema":{"version":3,"latest":4}}`))
+			return
+		}
+		w.Write([]byte(`{"status":"ready"}`))
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"unexpected request"}`))
+	}
+}
+
+// runCtl runs studentctl against api with a config file holding one context for it.
+func runCtl(t *testing.T, api *fakeAPI, args ...string) (string, error) {
+	t.Helper()
+
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	cfg := &ctlConfig{
+		CurrentContext: "test",
+		Contexts:       []ctlContext{{Name: "test", Endpoint: srv.URL, Token: "s3cret"}},
+	}
+	if err := cfg.save(config); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	err := run(append([]string{"-config", config}, args...), &stdout, &stderr)
+
+	return stdout.String(), err
+}
+
+func TestStudentsList(t *testing.T) {
+	api := &fakeAPI{}
+
+	out, err := runCtl(t, api, "students", "list")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want a header and 2 students:\n%s", len(lines), out)
+	}
+	if !strings.HasPrefix(lines[0], "ID") || !strings.Contains(lines[1], "ada@example.com") {
+		t.Errorf("unexpected table:\n%s", out)
+	}
+
+	if got := api.requests[0].Header.Get("Authorization"); got != "Bearer s3cret" {
+		t.Errorf("Authorization = %q, want the context's token", got)
+	}
+	if got := api.requests[0].Header.Get("User-Agent"); got != "studentctl" {
+		t.Errorf("User-Agent = %q", got)
+	}
+}
+
+func TestOutputFormats(t *testing.T) {
+	out, err := runCtl(t, &fakeAPI{}, "-o", "json", "students", "list")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var students []map[string]any
+	if err := json.Unmarshal([]byte(out), &students); err != nil {
+		t.Fatalf("output is not a JSON array: %v\n%s", err, out)
+	}
+	if len(students) != 2 || students[0]["name"] != "Ada" {
+		t.Errorf("unexpected students: %v", students)
+	}
+
+	out, err = runCtl(t, &fakeAPI{}, "-o", "yaml", "students", "list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "- id: 1") || !strings.Contains(out, "name: Alan") {
+		t.Errorf("unexpected YAML:\n%s", out)
+	}
+
+	if _, err := runCtl(t, &fakeAPI{}, "-o", "xml", "students", "list"); err == nil {
+		t.Error("expected an error for -o xml")
+	}
+}
+
+func TestStudentsGetAndCreate(t *testing.T) {
+	api := &fakeAPI{}
+
+	if _, err := runCtl(t, api, "students", "get", "99"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("get 99: got %v, want a 404", err)
+	}
+
+	if _, err := runCtl(t, api, "students", "get", "abc"); err == nil {
+		t.Error("expected an error for a non-numeric ID")
+	}
+
+	out, err := runCtl(t, api, "students", "create", "-name", "Grace", "-rollno", "9", "-dob", "1906-12-09")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Grace") {
+		t.Errorf("created student not printed:\n%s", out)
+	}
+
+	var sent map[string]any
+	if err := json.Unmarshal([]byte(api.bodies[len(api.bodies)-1]), &sent); err != nil {
+		t.Fatal(err)
+	}
+	if sent["name"] != "Grace" || sent["rollno"] != float64(9) || sent["date_of_birth"] != "1906-12-09" {
+		t.Errorf("unexpected request body: %v", sent)
+	}
+}
+
+func TestImportRejected(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "students.csv")
+	if err := os.WriteFile(file, []byte("full_name,rollno\nAda,7\nAlan,x\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	api := &fakeAPI{}
+
+	out, err := runCtl(t, api, "import", "-map", "name=full_name", file)
+	if err == nil {
+		t.Fatal("expected the rejected import to fail")
+	}
+	if !strings.Contains(out, "must be a positive integer") {
+		t.Errorf("line errors not printed:\n%s", out)
+	}
+
+	req := api.requests[0]
+	if got := req.URL.Query().Get("map[name]"); got != "full_name" {
+		t.Errorf("map[name] = %q, want full_name", got)
+	}
+	if got := req.Header.Get("Content-Type"); got != "text/csv" {
+		t.Errorf("Content-Type = %q, want text/csv", got)
+	}
+
+	if _, err := runCtl(t, api, "-offline", "import", file); err != errOfflineTransfer {
+		t.Errorf("offline import: got %v, want errOfflineTransfer", err)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	out, err := runCtl(t, &fakeAPI{ready: true}, "health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "available") || !strings.Contains(out, "true") {
+		t.Errorf("unexpected report:\n%s", out)
+	}
+
+	out, err = runCtl(t, &fakeAPI{}, "-o", "json", "health")
+	if err == nil {
+		t.Error("expected an error when the API is not ready")
+	}
+	if !strings.Contains(out, `"ready": false`) || !strings.Contains(out, `"latest": 4`) {
+		t.Errorf("unexpected report:\n%s", out)
+	}
+}
+
+func TestUnsupportedCommands(t *testing.T) {
+	for _, cmd := range []string{"users", "tokens"} {
+		if _, err := runCtl(t, &fakeAPI{}, cmd, "create"); err == nil {
+			t.Errorf("%s: expected an error", cmd)
+		}
+	}
+
+	// gRPC does check tokens, so the explanation must point at where they are configured.
+	if _, err := runCtl(t, &fakeAPI{}, "tokens", "revoke"); err == nil || !strings.Contains(err.Error(), "-grpc-auth-tokens") {
+		t.Errorf("expected tokens to explain where gRPC tokens live, got %v", err)
+	}
+}
+
+func TestOfflineNeedsDatabase(t *testing.T) {
+	t.Setenv("DATABASE_URL", "")
+
+	_, err := runCtl(t, &fakeAPI{}, "-offline", "students", "list")
+	if err == nil || !strings.Contains(err.Error(), "-offline needs a database") {
+		t.Errorf("got %v, want an error about the missing database", err)
+	}
+}
+
+func TestConfigContexts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte(`current-context: dev
+contexts:
+  - name: dev
+    endpoint: http://localhost:4000
+  - name: prod
+    endpoint: https://students.example.com
+    token: s3cret
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+
+	if err := run([]string{"-config", path, "-o", "json", "config", "get-contexts"}, &stdout, &stdout); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(stdout.String(), "s3cret") {
+		t.Errorf("get-contexts printed a token:\n%s", stdout.String())
+	}
+
+	if err := run([]string{"-config", path, "config", "use-context", "prod"}, &stdout, &stdout); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, err := cfg.context("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Name != "prod" || ctx.Token != "s3cret" {
+		t.Errorf("current context = %+v, want prod with its token", ctx)
+	}
+
+	if err := run([]string{"-config", path, "config", "use-context", "qa"}, &stdout, &stdout); err == nil {
+		t.Error("expected an error for an unknown context")
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := loadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("a missing file should be an empty config: %v", err)
+	}
+	if len(cfg.Contexts) != 0 {
+		t.Errorf("got %d contexts, want none", len(cfg.Contexts))
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(path, []byte("contexts:\n  - name: a\n  - name: a\n"), 0o600)
+
+	if _, err := loadConfig(path); err == nil {
+		t.Error("expected an error for a duplicate context")
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/goccy/go-yaml"
+	"github.com/sai29/one2n_sre_bootcamp/pkg/client"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// tabular is implemented by results with a table form. Others are printed as JSON even
+// with -o table.
+type tabular interface {
+	header() []string
+	rows() [][]string
+}
+
+// print writes v in the -o format. JSON and YAML use v's json tags, so they match what
+// the API returns.
+func (c *ctl) print(v any) error {
+	t, ok := v.(tabular)
+
+	switch {
+	case c.output == outputYAML:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+
+		y, err := yaml.JSONToYAML(b)
+		if err != nil {
+			return err
+		}
+
+		_, err = c.stdout.Write(y)
+		return err
+
+	case c.output == outputJSON || !ok:
+		enc := json.NewEncoder(c.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+
+	for i, col := range t.header() {
+		if i > 0 {
+			fmt.Fprint(w, "\t")
+		}
+		fmt.Fprint(w, col)
+	}
+	fmt.Fprintln(w)
+
+	for _, row := range t.rows() {
+		for i, cell := range row {
+			if i > 0 {
+				fmt.Fprint(w, "\t")
+			}
+			fmt.Fprint(w, cell)
+		}
+		fmt.Fprintln(w)
+	}
+
+	return w.Flush()
+}
+
+type studentTable []*client.Student
+
+func (t studentTable) header() []string {
+	return []string{"ID", "NAME", "ROLLNO", "EMAIL", "DATE OF BIRTH", "STATUS", "VERSION"}
+}
+
+func (t studentTable) rows() [][]string {
+	rows := make([][]string, len(t))
+	for i, s := range t {
+		rows[i] = []string{
+			strconv.FormatInt(s.ID, 10),
+			s.Name,
+			strconv.Itoa(int(s.RollNo)),
+			s.Email,
+			s.DateOfBirth,
+			s.Status,
+			strconv.Itoa(int(s.Version)),
+		}
+	}
+	return rows
+}
+
+func (r *healthReport) header() []string {
+	return []string{"TARGET", "STATUS", "ENV", "READY"}
+}
+
+func (r *healthReport) rows() [][]string {
+	return [][]string{{r.Target, r.Status, r.Env, strconv.FormatBool(r.Ready)}}
+}
+
+// importErrors lists the invalid lines of an import, one row per field.
+type importErrors []client.ImportLineError
+
+func (t importErrors) header() []string {
+	return []string{"LINE", "FIELD", "PROBLEM"}
+}
+
+func (t importErrors) rows() [][]string {
+	var rows [][]string
+	for _, line := range t {
+		for field, problem := range line.Errors {
+			rows = append(rows, []string{strconv.Itoa(line.Line), field, problem})
+		}
+	}
+	return rows
+}
+
+// contextTable lists the contexts in the config file. Tokens and database URLs hold
+// secrets, so they are left out.
+type contextTable struct {
+	current  string
+	contexts []ctlContext
+}
+
+func (t contextTable) MarshalJSON() ([]byte, error) {
+	type row struct {
+		Name     string `json:"name"`
+		Endpoint string `json:"endpoint"`
+		Current  bool   `json:"current"`
+	}
+
+	rows := make([]row, len(t.contexts))
+	for i, c := range t.contexts {
+		rows[i] = row{Name: c.Name, Endpoint: c.Endpoint, Current: c.Name == t.current}
+	}
+
+	return json.Marshal(rows)
+}
+
+func (t contextTable) header() []string {
+	return []string{"CURRENT", "NAME", "ENDPOINT", "OFFLINE DATABASE"}
+}
+
+func (t contextTable) rows() [][]string {
+	rows := make([][]string, len(t.contexts))
+	for i, c := range t.contexts {
+		current := ""
+		if c.Name == t.current {
+			current = "*"
+		}
+
+		db := ""
+		if c.DatabaseURL != "" {
+			db = "yes"
+		}
+
+		rows[i] = []string{current, c.Name, c.Endpoint, db}
+	}
+	return rows
+}
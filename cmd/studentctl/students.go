@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/sai29/one2n_sre_bootcamp/pkg/client"
+)
+
+func (c *ctl) students(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("students: missing subcommand (list, get, create or delete)")
+	}
+
+	switch args[0] {
+	case "list":
+		return c.listStudents(ctx, args[1:])
+	case "get":
+		return c.getStudent(ctx, args[1:])
+	case "create":
+		return c.createStudent(ctx, args[1:])
+	case "delete":
+		return c.deleteStudent(ctx, args[1:])
+	default:
+		return fmt.Errorf("students: unknown subcommand %q", args[0])
+	}
+}
+
+func (c *ctl) listStudents(ctx context.Context, args []string) error {
+	fs := c.subcommand("students list", "students list [flags]")
+	pageSize := fs.Int("page-size", 100, "Students fetched per request")
+
+	if ok, err := parse(fs, args); !ok {
+		return err
+	}
+
+	b, err := c.backend()
+	if err != nil {
+		return err
+	}
+	defer b.close()
+
+	students, err := b.listStudents(ctx, *pageSize)
+	if err != nil {
+		return err
+	}
+
+	return c.print(studentTable(students))
+}
+
+func (c *ctl) getStudent(ctx context.Context, args []string) error {
+	fs := c.subcommand("students get", "students get ID")
+
+	if ok, err := parse(fs, args); !ok {
+		return err
+	}
+
+	id, err := studentID(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	b, err := c.backend()
+	if err != nil {
+		return err
+	}
+	defer b.close()
+
+	s, err := b.getStudent(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return c.print(studentTable{s})
+}
+
+func (c *ctl) createStudent(ctx context.Context, args []string) error {
+	var s client.NewStudent
+	var rollno int
+
+	fs := c.subcommand("students create", "students create -name NAME -rollno N [flags]")
+	fs.StringVar(&s.Name, "name", "", "Name (required)")
+	fs.IntVar(&rollno, "rollno", 0, "Roll number (required)")
+	fs.StringVar(&s.Email, "email", "", "Email address")
+	fs.StringVar(&s.Phone, "phone", "", "Phone number")
+	fs.StringVar(&s.DateOfBirth, "dob", "", "Date of birth (YYYY-MM-DD)")
+	fs.StringVar(&s.Address, "address", "", "Address")
+	fs.StringVar(&s.Status, "status", "", "Initial status (default applicant)")
+
+	if ok, err := parse(fs, args); !ok {
+		return err
+	}
+
+	if fs.NArg() > 0 {
+		return fmt.Errorf("students create: unexpected argument %q", fs.Arg(0))
+	}
+
+	s.RollNo = int32(rollno)
+
+	b, err := c.backend()
+	if err != nil {
+		return err
+	}
+	defer b.close()
+
+	created, err := b.createStudent(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	return c.print(studentTable{created})
+}
+
+func (c *ctl) deleteStudent(ctx context.Context, args []string) error {
+	fs := c.subcommand("students delete", "students delete ID")
+
+	if ok, err := parse(fs, args); !ok {
+		return err
+	}
+
+	id, err := studentID(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	b, err := c.backend()
+	if err != nil {
+		return err
+	}
+	defer b.close()
+
+	if err := b.deleteStudent(ctx, id); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "student %d deleted\n", id)
+	return nil
+}
+
+func studentID(args []string) (int64, error) {
+	if len(args) != 1 {
+		return 0, errors.New("expected exactly one student ID")
+	}
+
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid student ID %q", args[0])
+	}
+
+	return id, nil
+}
+
+func (c *ctl) health(ctx context.Context) error {
+	b, err := c.backend()
+	if err != nil {
+		return err
+	}
+	defer b.close()
+
+	report, err := b.health(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := c.print(report); err != nil {
+		return err
+	}
+
+	if !report.Ready {
+		return errors.New("not ready")
+	}
+
+	return nil
+}
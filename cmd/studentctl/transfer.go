@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sai29/one2n_sre_bootcamp/pkg/client"
+)
+
+// importPollInterval is how often import -wait checks a background import.
+var importPollInterval = time.Second
+
+var errOfflineTransfer = errors.New("import and export go through the API and are not available with -offline")
+
+func (c *ctl) importStudents(ctx context.Context, args []string) error {
+	opts := client.ImportOptions{Columns: map[string]string{}}
+
+	fs := c.subcommand("import", "import [flags] FILE")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "Only validate the file")
+	wait := fs.Bool("wait", false, "Wait for a background import to finish")
+	fs.Func("map", "Read a field from a differently named column, as field=column (repeatable)", func(s string) error {
+		field, column, ok := strings.Cut(s, "=")
+		if !ok || field == "" || column == "" {
+			return fmt.Errorf("%q is not field=column", s)
+		}
+		opts.Columns[field] = column
+		return nil
+	})
+
+	if ok, err := parse(fs, args); !ok {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return errors.New("import: expected exactly one CSV file")
+	}
+
+	if c.offline {
+		return errOfflineTransfer
+	}
+
+	csv, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	api, err := c.apiClient()
+	if err != nil {
+		return err
+	}
+
+	result, err := api.ImportStudents(ctx, csv, opts)
+	if err != nil {
+		var rejected *client.ImportError
+		if errors.As(err, &rejected) {
+			if perr := c.print(importErrors(rejected.Report.Errors)); perr != nil {
+				return perr
+			}
+		}
+		return err
+	}
+
+	if result.Job != nil && *wait {
+		if result.Job, err = waitForImport(ctx, api, result.Job); err != nil {
+			return err
+		}
+	}
+
+	if err := c.print(result); err != nil {
+		return err
+	}
+
+	if result.Job != nil && result.Job.Status == "failed" {
+		return fmt.Errorf("import %s failed: %s", result.Job.ID, result.Job.Error)
+	}
+
+	return nil
+}
+
+// waitForImport polls a background import until it is no longer running. It is only
+// bounded by -timeout when one is given.
+func waitForImport(ctx context.Context, api *client.Client, job *client.ImportJob) (*client.ImportJob, error) {
+	ticker := time.NewTicker(importPollInterval)
+	defer ticker.Stop()
+
+	for job.Status == "running" {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for import %s: %w", job.ID, ctx.Err())
+		case <-ticker.C:
+		}
+
+		next, err := api.ImportJob(ctx, job.ID)
+		if err != nil {
+			return nil, err
+		}
+		job = next
+	}
+
+	return job, nil
+}
+
+func (c *ctl) exportStudents(ctx context.Context, args []string) error {
+	fs := c.subcommand("export", "export [flags]")
+	format := fs.String("format", "csv", "Export format (csv|ndjson|xlsx)")
+	file := fs.String("f", "", "Write to FILE instead of standard output")
+
+	if ok, err := parse(fs, args); !ok {
+		return err
+	}
+
+	if c.offline {
+		return errOfflineTransfer
+	}
+
+	api, err := c.apiClient()
+	if err != nil {
+		return err
+	}
+
+	if *file == "" {
+		return api.ExportStudents(ctx, *format, c.stdout)
+	}
+
+	f, err := os.Create(*file)
+	if err != nil {
+		return err
+	}
+
+	err = api.ExportStudents(ctx, *format, f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
+
+func (c *ctl) configCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("config: missing subcommand (get-contexts, current-context or use-context)")
+	}
+
+	switch args[0] {
+	case "get-contexts":
+		return c.print(contextTable{current: c.config.CurrentContext, contexts: c.config.Contexts})
+
+	case "current-context":
+		if c.config.CurrentContext == "" {
+			return errors.New("no current context is set")
+		}
+		fmt.Fprintln(c.stdout, c.config.CurrentContext)
+		return nil
+
+	case "use-context":
+		if len(args) != 2 {
+			return errors.New("config use-context: expected exactly one context name")
+		}
+		if _, err := c.config.context(args[1]); err != nil {
+			return err
+		}
+		c.config.CurrentContext = args[1]
+		if err := c.config.save(c.configPath); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.stdout, "switched to context %q\n", args[1])
+		return nil
+
+	default:
+		return fmt.Errorf("config: unknown subcommand %q", args[0])
+	}
+}
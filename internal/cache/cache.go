@@ -0,0 +1,31 @@
+// Package cache provides the key/value backends used by the read-through store caches:
+// an in-process LRU with per-entry TTLs and a Redis backend for sharing a cache between
+// API instances.
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Backend stores opaque values under string keys. Implementations must be safe for
+// concurrent use. A missing or expired key is reported with ok == false, not an error.
+type Backend interface {
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Recorder receives cache events, typically to update metrics.
+type Recorder interface {
+	Hit()
+	Miss()
+	Evict()
+}
+
+// NopRecorder discards every event.
+type NopRecorder struct{}
+
+func (NopRecorder) Hit()   {}
+func (NopRecorder) Miss()  {}
+func (NopRecorder) Evict() {}
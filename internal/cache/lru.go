@@ -0,0 +1,176 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// LRU is an in-process Backend holding at most a fixed number of entries. When it is full
+// the least recently used entry is evicted. Expired entries are dropped when they are read.
+//
+// Keys matched by NeverEvict are kept apart in a map that doesn't count towards the
+// capacity. They are only dropped once they expire or are deleted.
+type LRU struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+	recorder Recorder
+
+	durable func(key string) bool
+	pinned  map[string]*lruEntry
+	// sweepAt is the size of pinned at which expired pinned entries are next swept.
+	sweepAt int
+
+	now func() time.Time
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewLRU returns an LRU holding up to capacity entries. Capacity evictions are reported to
+// recorder, which may be nil.
+func NewLRU(capacity int, recorder Recorder) *LRU {
+	if recorder == nil {
+		recorder = NopRecorder{}
+	}
+
+	return &LRU{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+		recorder: recorder,
+		pinned:   make(map[string]*lruEntry),
+		sweepAt:  capacity,
+		now:      time.Now,
+	}
+}
+
+// NeverEvict keeps the keys for which match returns true out of the LRU. Use it for
+// entries whose loss would be unsafe rather than just a miss. It must be called before
+// the cache is used.
+func (c *LRU) NeverEvict(match func(key string) bool) {
+	c.durable = match
+}
+
+func (c *LRU) isDurable(key string) bool {
+	return c.durable != nil && c.durable(key)
+}
+
+func (c *LRU) expired(entry *lruEntry) bool {
+	return !entry.expires.IsZero() && !c.now().Before(entry.expires)
+}
+
+func (c *LRU) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.isDurable(key) {
+		entry, ok := c.pinned[key]
+		if !ok {
+			return nil, false, nil
+		}
+		if c.expired(entry) {
+			delete(c.pinned, key)
+			return nil, false, nil
+		}
+		return entry.value, true, nil
+	}
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := el.Value.(*lruEntry)
+	if c.expired(entry) {
+		c.remove(el)
+		return nil, false, nil
+	}
+
+	c.ll.MoveToFront(el)
+	return entry.value, true, nil
+}
+
+// Set stores value under key. A ttl of zero or less means the entry does not expire.
+func (c *LRU) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expires time.Time
+	if ttl > 0 {
+		expires = c.now().Add(ttl)
+	}
+
+	if c.isDurable(key) {
+		c.pinned[key] = &lruEntry{key: key, value: value, expires: expires}
+		c.sweepPinned()
+		return nil
+	}
+
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*lruEntry)
+		entry.value = value
+		entry.expires = expires
+		c.ll.MoveToFront(el)
+		return nil
+	}
+
+	c.items[key] = c.ll.PushFront(&lruEntry{key: key, value: value, expires: expires})
+
+	for c.ll.Len() > c.capacity {
+		c.remove(c.ll.Back())
+		c.recorder.Evict()
+	}
+
+	return nil
+}
+
+func (c *LRU) Delete(_ context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if el, ok := c.items[key]; ok {
+			c.remove(el)
+		}
+		delete(c.pinned, key)
+	}
+
+	return nil
+}
+
+// Len returns the number of entries, including expired ones not yet dropped and those
+// kept by NeverEvict.
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ll.Len() + len(c.pinned)
+}
+
+// sweepPinned drops expired pinned entries once their number has doubled since the last
+// sweep, so entries that are never read again don't pile up.
+func (c *LRU) sweepPinned() {
+	if len(c.pinned) < c.sweepAt {
+		return
+	}
+
+	for key, entry := range c.pinned {
+		if c.expired(entry) {
+			delete(c.pinned, key)
+		}
+	}
+
+	c.sweepAt = max(2*len(c.pinned), c.capacity)
+}
+
+func (c *LRU) remove(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*lruEntry).key)
+}
@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+type countingRecorder struct {
+	hits, misses, evictions int
+}
+
+func (r *countingRecorder) Hit()   { r.hits++ }
+func (r *countingRecorder) Miss()  { r.misses++ }
+func (r *countingRecorder) Evict() { r.evictions++ }
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	rec := &countingRecorder{}
+	c := NewLRU(2, rec)
+
+	_ = c.Set(ctx, "a", []byte("1"), 0)
+	_ = c.Set(ctx, "b", []byte("2"), 0)
+
+	// Reading "a" makes "b" the least recently used entry.
+	if _, ok, _ := c.Get(ctx, "a"); !ok {
+		t.Fatal("expected a to be cached")
+	}
+
+	_ = c.Set(ctx, "c", []byte("3"), 0)
+
+	if _, ok, _ := c.Get(ctx, "b"); ok {
+		t.Fatal("expected b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok, _ := c.Get(ctx, key); !ok {
+			t.Fatalf("expected %s to be cached", key)
+		}
+	}
+
+	if rec.evictions != 1 {
+		t.Fatalf("expected 1 eviction, got %d", rec.evictions)
+	}
+}
+
+func TestLRUExpiresEntries(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(10, nil)
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	_ = c.Set(ctx, "a", []byte("1"), time.Minute)
+	_ = c.Set(ctx, "b", []byte("2"), 0)
+
+	now = now.Add(time.Minute)
+
+	if _, ok, _ := c.Get(ctx, "a"); ok {
+		t.Fatal("expected a to have expired")
+	}
+	if _, ok, _ := c.Get(ctx, "b"); !ok {
+		t.Fatal("expected b without a TTL to still be cached")
+	}
+	if c.Len() != 1 {
+		t.Fatalf("expected the expired entry to be dropped, len = %d", c.Len())
+	}
+}
+
+func TestLRUDelete(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(10, nil)
+
+	_ = c.Set(ctx, "a", []byte("1"), 0)
+	_ = c.Delete(ctx, "a", "missing")
+
+	if _, ok, _ := c.Get(ctx, "a"); ok {
+		t.Fatal("expected a to be deleted")
+	}
+}
+
+func TestLRUNeverEvict(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(1, nil)
+	c.NeverEvict(func(key string) bool { return strings.HasPrefix(key, "keep:") })
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	_ = c.Set(ctx, "keep:a", []byte("1"), time.Minute)
+	_ = c.Set(ctx, "b", []byte("2"), 0)
+	_ = c.Set(ctx, "c", []byte("3"), 0)
+
+	if _, ok, _ := c.Get(ctx, "keep:a"); !ok {
+		t.Fatal("expected keep:a to survive evictions")
+	}
+	if _, ok, _ := c.Get(ctx, "b"); ok {
+		t.Fatal("expected b to be evicted")
+	}
+
+	now = now.Add(time.Minute)
+
+	if _, ok, _ := c.Get(ctx, "keep:a"); ok {
+		t.Fatal("expected keep:a to have expired")
+	}
+
+	_ = c.Set(ctx, "keep:d", []byte("4"), 0)
+	_ = c.Delete(ctx, "keep:d")
+
+	if _, ok, _ := c.Get(ctx, "keep:d"); ok {
+		t.Fatal("expected keep:d to be deleted")
+	}
+}
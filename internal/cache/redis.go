@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// StudentsPrefix is the prefix the API's student cache uses, so that studentctl can
+// invalidate the same keys.
+const StudentsPrefix = "students_api:"
+
+// Redis is a Backend stored in Redis, so that every API instance shares one cache. Keys
+// are namespaced with a prefix. Redis does its own eviction, so no Evict events are
+// reported.
+type Redis struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedis returns a Backend using client. prefix is prepended to every key.
+func NewRedis(client redis.UniversalClient, prefix string) *Redis {
+	return &Redis{client: client, prefix: prefix}
+}
+
+// OpenRedis connects to the server at url (redis://[user:password@]host:port/db) and
+// checks that it responds.
+func OpenRedis(ctx context.Context, url, prefix string) (*Redis, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	client := redis.NewClient(opts)
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+
+	return NewRedis(client, prefix), nil
+}
+
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := r.client.Get(ctx, r.prefix+key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return value, true, nil
+}
+
+// Set stores value under key. A ttl of zero or less means the entry does not expire.
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl < 0 {
+		ttl = 0
+	}
+	return r.client.Set(ctx, r.prefix+key, value, ttl).Err()
+}
+
+func (r *Redis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = r.prefix + key
+	}
+
+	return r.client.Del(ctx, prefixed...).Err()
+}
+
+// Close closes the underlying client.
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
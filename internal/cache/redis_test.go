@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestRedis(t *testing.T) {
+	srv := miniredis.RunT(t)
+	ctx := context.Background()
+
+	r, err := OpenRedis(ctx, "redis://"+srv.Addr(), "test:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = r.Close() })
+
+	if _, ok, err := r.Get(ctx, "a"); ok || err != nil {
+		t.Fatalf("expected a miss, got ok=%v err=%v", ok, err)
+	}
+
+	if err := r.Set(ctx, "a", []byte("1"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	if !srv.Exists("test:a") {
+		t.Fatal("expected the key to be prefixed")
+	}
+
+	value, ok, err := r.Get(ctx, "a")
+	if err != nil || !ok || string(value) != "1" {
+		t.Fatalf("got %q, %v, %v", value, ok, err)
+	}
+
+	srv.FastForward(time.Minute)
+
+	if _, ok, _ := r.Get(ctx, "a"); ok {
+		t.Fatal("expected a to have expired")
+	}
+
+	_ = r.Set(ctx, "b", []byte("2"), 0)
+	if err := r.Delete(ctx, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := r.Get(ctx, "b"); ok {
+		t.Fatal("expected b to be deleted")
+	}
+}
@@ -0,0 +1,257 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	AttendancePresent = "present"
+	AttendanceAbsent  = "absent"
+	AttendanceLate    = "late"
+	AttendanceExcused = "excused"
+)
+
+var AttendanceStatuses = []string{AttendancePresent, AttendanceAbsent, AttendanceLate, AttendanceExcused}
+
+type AttendanceRecord struct {
+	ID         int64  `json:"id"`
+	CourseID   int64  `json:"course_id"`
+	CourseCode string `json:"course_code"`
+	StudentID  int64  `json:"student_id"`
+	Date       Date   `json:"date"`
+	Status     string `json:"status"`
+}
+
+// AttendanceSummary counts sessions by status. Late counts as attended and excused
+// sessions are left out of the percentage altogether. With no counted sessions the
+// percentage is 100, since nothing has been missed; use NewAttendanceSummary to start
+// from that rather than the zero value.
+type AttendanceSummary struct {
+	Present    int     `json:"present"`
+	Absent     int     `json:"absent"`
+	Late       int     `json:"late"`
+	Excused    int     `json:"excused"`
+	Total      int     `json:"total"`
+	Percentage float64 `json:"percentage"`
+}
+
+// NewAttendanceSummary returns a summary of no sessions.
+func NewAttendanceSummary() *AttendanceSummary {
+	return &AttendanceSummary{Percentage: 100}
+}
+
+// Add counts one session with the given status and updates the percentage.
+func (s *AttendanceSummary) Add(status string) {
+	switch status {
+	case AttendancePresent:
+		s.Present++
+	case AttendanceAbsent:
+		s.Absent++
+	case AttendanceLate:
+		s.Late++
+	case AttendanceExcused:
+		s.Excused++
+	}
+	s.Total++
+
+	counted := s.Total - s.Excused
+	if counted == 0 {
+		s.Percentage = 100
+		return
+	}
+
+	s.Percentage = float64(int(float64(s.Present+s.Late)/float64(counted)*10000+0.5)) / 100
+}
+
+type AttendanceModel struct {
+	DB       *sql.DB
+	InfoLog  *log.Logger
+	ErrorLog *log.Logger
+}
+
+// ResolveRollNos maps roll numbers to the IDs of students enrolled (not waitlisted) in
+// the course. Roll numbers are not unique across the roster, so a roll number may map
+// to more than one student; callers should treat that as ambiguous.
+func (m AttendanceModel) ResolveRollNos(courseID int64, rollNos []int32) (map[int32][]int64, error) {
+	query := `
+		SELECT s.rollno, s.id
+		FROM students s
+		JOIN enrollments e ON e.student_id = s.id
+		WHERE e.course_id = $1 AND e.status = 'enrolled' AND s.rollno = ANY($2)
+		ORDER BY s.id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, courseID, pq.Array(rollNos))
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			m.ErrorLog.Println(err)
+		}
+	}()
+
+	ids := make(map[int32][]int64)
+
+	for rows.Next() {
+		var rollNo int32
+		var id int64
+
+		if err := rows.Scan(&rollNo, &id); err != nil {
+			return nil, err
+		}
+		ids[rollNo] = append(ids[rollNo], id)
+	}
+
+	return ids, rows.Err()
+}
+
+// Mark records the status for each student (keyed by student ID) on the given date,
+// overwriting earlier marks for the same day, and returns the students' summaries for
+// the course from just before and just after the change. The course row is locked and
+// everything happens in one transaction, so concurrent marks for the course can't land
+// between the two summaries. Students with no recorded sessions are absent from before.
+func (m AttendanceModel) Mark(courseID int64, date Date, marks map[int64]string) (before, after map[int64]*AttendanceSummary, err error) {
+	query := `
+		INSERT INTO attendance (course_id, student_id, date, status)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (course_id, student_id, date) DO UPDATE
+		SET status = EXCLUDED.status, updated_at = NOW()`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := lockCourse(ctx, tx, courseID); err != nil {
+		return nil, nil, err
+	}
+
+	studentIDs := make([]int64, 0, len(marks))
+	for studentID := range marks {
+		studentIDs = append(studentIDs, studentID)
+	}
+
+	before, err = courseSummaries(ctx, tx, courseID, studentIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for studentID, status := range marks {
+		if _, err := stmt.ExecContext(ctx, courseID, studentID, date, status); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	after, err = courseSummaries(ctx, tx, courseID, studentIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return before, after, tx.Commit()
+}
+
+// courseSummaries returns each listed student's attendance summary for a course.
+// Students with no recorded sessions are absent from the map.
+func courseSummaries(ctx context.Context, tx *sql.Tx, courseID int64, studentIDs []int64) (map[int64]*AttendanceSummary, error) {
+	query := `
+		SELECT student_id, status
+		FROM attendance
+		WHERE course_id = $1 AND student_id = ANY($2)`
+
+	rows, err := tx.QueryContext(ctx, query, courseID, pq.Array(studentIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	summaries := make(map[int64]*AttendanceSummary)
+
+	for rows.Next() {
+		var studentID int64
+		var status string
+
+		if err := rows.Scan(&studentID, &status); err != nil {
+			return nil, err
+		}
+
+		s, ok := summaries[studentID]
+		if !ok {
+			s = NewAttendanceSummary()
+			summaries[studentID] = s
+		}
+		s.Add(status)
+	}
+
+	return summaries, rows.Err()
+}
+
+// ListForStudent returns the student's attendance between from and to inclusive, either
+// of which may be the zero Date to leave that end open.
+func (m AttendanceModel) ListForStudent(studentID int64, from, to Date) ([]*AttendanceRecord, error) {
+	query := `
+		SELECT a.id, a.course_id, c.code, a.student_id, a.date, a.status
+		FROM attendance a
+		JOIN courses c ON c.id = a.course_id
+		WHERE a.student_id = $1
+		AND ($2::date IS NULL OR a.date >= $2)
+		AND ($3::date IS NULL OR a.date <= $3)
+		ORDER BY a.date, c.code`
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, studentID, nullDate(from), nullDate(to))
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			m.ErrorLog.Println(err)
+		}
+	}()
+
+	records := []*AttendanceRecord{}
+
+	for rows.Next() {
+		var r AttendanceRecord
+
+		err := rows.Scan(
+			&r.ID,
+			&r.CourseID,
+			&r.CourseCode,
+			&r.StudentID,
+			&r.Date,
+			&r.Status,
+		)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, &r)
+	}
+
+	return records, rows.Err()
+}
+
+func nullDate(d Date) sql.NullString {
+	return sql.NullString{String: d.String(), Valid: !d.IsZero()}
+}
@@ -0,0 +1,268 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/validator"
+)
+
+var (
+	ErrDuplicateCourse = errors.New("duplicate course")
+
+	// ErrCapacityBelowEnrolled is returned by CourseModel.Update when the new capacity
+	// is lower than the number of students already holding a seat.
+	ErrCapacityBelowEnrolled = errors.New("capacity below enrolled count")
+)
+
+type Course struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"-"`
+	Code      string    `json:"code"`
+	Title     string    `json:"title"`
+	Credits   int32     `json:"credits"`
+	Capacity  int32     `json:"capacity"`
+	Term      string    `json:"term"`
+	Version   int32     `json:"version"`
+}
+
+func ValidateCourse(v *validator.Validator, course *Course) {
+	v.Check(course.Code != "", "code", "must be provided")
+	v.Check(len(course.Code) <= 20, "code", "must not be more than 20 bytes long")
+
+	v.Check(course.Title != "", "title", "must be provided")
+	v.Check(len(course.Title) <= 500, "title", "must not be more than 500 bytes long")
+
+	v.Check(course.Credits > 0, "credits", "must be a positive integer")
+	v.Check(course.Credits <= 30, "credits", "must not be more than 30")
+
+	v.Check(course.Capacity > 0, "capacity", "must be a positive integer")
+
+	v.Check(course.Term != "", "term", "must be provided")
+	v.Check(len(course.Term) <= 50, "term", "must not be more than 50 bytes long")
+}
+
+type CourseModel struct {
+	DB       *sql.DB
+	InfoLog  *log.Logger
+	ErrorLog *log.Logger
+}
+
+func (m CourseModel) Insert(course *Course) error {
+	query := `
+	INSERT INTO courses (code, title, credits, capacity, term)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING id, created_at, version`
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	args := []interface{}{course.Code, course.Title, course.Credits, course.Capacity, course.Term}
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&course.ID, &course.CreatedAt, &course.Version)
+	if err != nil {
+		if isDuplicateCourse(err) {
+			return ErrDuplicateCourse
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (m CourseModel) Get(id int64) (*Course, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+	SELECT id, created_at, code, title, credits, capacity, term, version
+	FROM courses
+	WHERE id = $1`
+
+	var course Course
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id).Scan(
+		&course.ID,
+		&course.CreatedAt,
+		&course.Code,
+		&course.Title,
+		&course.Credits,
+		&course.Capacity,
+		&course.Term,
+		&course.Version,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &course, nil
+}
+
+func (m CourseModel) ListAll() ([]*Course, error) {
+	query := `
+		SELECT id, created_at, code, title, credits, capacity, term, version
+		FROM courses
+		ORDER BY id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			m.ErrorLog.Println(err)
+		}
+	}()
+
+	courses := []*Course{}
+
+	for rows.Next() {
+		var c Course
+
+		err := rows.Scan(
+			&c.ID,
+			&c.CreatedAt,
+			&c.Code,
+			&c.Title,
+			&c.Credits,
+			&c.Capacity,
+			&c.Term,
+			&c.Version,
+		)
+		if err != nil {
+			return nil, err
+		}
+		courses = append(courses, &c)
+	}
+
+	return courses, rows.Err()
+}
+
+// Update applies changes using optimistic locking on version. Raising the capacity
+// promotes waitlisted students into the new seats within the same transaction, and
+// lowering it below the number of enrolled students fails with
+// ErrCapacityBelowEnrolled.
+func (m CourseModel) Update(course *Course) error {
+	query := `
+		UPDATE courses
+		SET code = $1, title = $2, credits = $3, capacity = $4, term = $5, version = version + 1
+		WHERE id = $6 AND version = $7
+		RETURNING version`
+
+	args := []interface{}{
+		course.Code,
+		course.Title,
+		course.Credits,
+		course.Capacity,
+		course.Term,
+		course.ID,
+		course.Version,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Hold the course lock so no enrollment can take a seat between the count and the
+	// update.
+	if _, err := lockCourse(ctx, tx, course.ID); err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			return ErrEditConflict
+		}
+		return err
+	}
+
+	var enrolled int32
+
+	err = tx.QueryRowContext(ctx, `
+		SELECT count(*)
+		FROM enrollments
+		WHERE course_id = $1 AND status = 'enrolled'`, course.ID).Scan(&enrolled)
+	if err != nil {
+		return err
+	}
+
+	if course.Capacity < enrolled {
+		return ErrCapacityBelowEnrolled
+	}
+
+	var version int32
+
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&version)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrEditConflict
+		case isDuplicateCourse(err):
+			return ErrDuplicateCourse
+		default:
+			return err
+		}
+	}
+
+	if _, err := promoteWaitlisted(ctx, tx, course.ID, course.Capacity); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	course.Version = version
+	return nil
+}
+
+func (m CourseModel) Delete(id int64) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `
+		DELETE FROM courses
+		WHERE id = $1
+		`
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func isDuplicateCourse(err error) bool {
+	return strings.Contains(err.Error(), `violates unique constraint "courses_code_term_key"`)
+}
@@ -0,0 +1,81 @@
+package data_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+)
+
+func TestCourseModel_EnrollWaitlistPromote(t *testing.T) {
+	db := openTestDB(t)
+	truncate(t, db)
+
+	models := data.NewModels(db)
+
+	course := &data.Course{Code: "CS101", Title: "Intro", Credits: 4, Capacity: 2, Term: "2025-fall"}
+	if err := models.Courses.Insert(course); err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []int64
+	for i := int32(1); i <= 4; i++ {
+		s := &data.Student{Name: "Student", RollNo: i, Status: data.StudentApplicant}
+		if err := models.Students.Insert(s); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, s.ID)
+	}
+
+	wantStatus := []string{data.EnrollmentEnrolled, data.EnrollmentEnrolled, data.EnrollmentWaitlisted, data.EnrollmentWaitlisted}
+	for i, id := range ids {
+		e, err := models.Enrollments.Enroll(course.ID, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.Status != wantStatus[i] {
+			t.Fatalf("student %d: got status %q, want %q", i+1, e.Status, wantStatus[i])
+		}
+		if e.Status == data.EnrollmentWaitlisted && e.WaitlistPosition != int64(i-1) {
+			t.Fatalf("student %d: got waitlist position %d, want %d", i+1, e.WaitlistPosition, i-1)
+		}
+	}
+
+	if _, err := models.Enrollments.Enroll(course.ID, ids[0]); !errors.Is(err, data.ErrAlreadyEnrolled) {
+		t.Fatalf("expected ErrAlreadyEnrolled, got %v", err)
+	}
+
+	// Dropping a seat promotes the longest waiting student.
+	promoted, err := models.Enrollments.Drop(course.ID, ids[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(promoted) != 1 || promoted[0].StudentID != ids[2] {
+		t.Fatalf("expected student 3 to be promoted, got %+v", promoted)
+	}
+
+	// Capacity can't drop below the two students holding seats.
+	course.Capacity = 1
+	if err := models.Courses.Update(course); !errors.Is(err, data.ErrCapacityBelowEnrolled) {
+		t.Fatalf("expected ErrCapacityBelowEnrolled, got %v", err)
+	}
+
+	// Raising it promotes the rest of the waitlist.
+	course.Capacity = 3
+	if err := models.Courses.Update(course); err != nil {
+		t.Fatal(err)
+	}
+
+	enrollments, err := models.Enrollments.ListForCourse(course.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range enrollments {
+		if e.Status != data.EnrollmentEnrolled {
+			t.Errorf("student %d is still %s after the capacity was raised", e.StudentID, e.Status)
+		}
+	}
+	if len(enrollments) != 3 {
+		t.Fatalf("expected 3 enrollments, got %d", len(enrollments))
+	}
+}
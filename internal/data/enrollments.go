@@ -0,0 +1,394 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	EnrollmentEnrolled   = "enrolled"
+	EnrollmentWaitlisted = "waitlisted"
+)
+
+var ErrAlreadyEnrolled = errors.New("already enrolled")
+
+type Enrollment struct {
+	ID               int64     `json:"id"`
+	CreatedAt        time.Time `json:"-"`
+	CourseID         int64     `json:"course_id"`
+	StudentID        int64     `json:"student_id"`
+	Status           string    `json:"status"`
+	WaitlistPosition int64     `json:"waitlist_position,omitempty"`
+}
+
+// StudentCourse is a course as seen from one student's enrollment in it.
+type StudentCourse struct {
+	EnrollmentID     int64   `json:"enrollment_id"`
+	Status           string  `json:"status"`
+	WaitlistPosition int64   `json:"waitlist_position,omitempty"`
+	Course           *Course `json:"course"`
+}
+
+type EnrollmentModel struct {
+	DB       *sql.DB
+	InfoLog  *log.Logger
+	ErrorLog *log.Logger
+}
+
+// Enroll adds a student to a course. The course row is locked for the duration of the
+// transaction so concurrent enrollments are serialised and the seat count can't exceed
+// capacity; once the course is full the student is put on the waitlist instead.
+func (m EnrollmentModel) Enroll(courseID, studentID int64) (*Enrollment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	capacity, err := lockCourse(ctx, tx, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	var enrolled int32
+
+	err = tx.QueryRowContext(ctx, `
+		SELECT count(*)
+		FROM enrollments
+		WHERE course_id = $1 AND status = 'enrolled'`, courseID).Scan(&enrolled)
+	if err != nil {
+		return nil, err
+	}
+
+	enrollment := &Enrollment{
+		CourseID:  courseID,
+		StudentID: studentID,
+		Status:    EnrollmentEnrolled,
+	}
+
+	if enrolled >= capacity {
+		enrollment.Status = EnrollmentWaitlisted
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO enrollments (course_id, student_id, status)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at`, courseID, studentID, enrollment.Status).Scan(&enrollment.ID, &enrollment.CreatedAt)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), `violates unique constraint "enrollments_course_student_key"`):
+			return nil, ErrAlreadyEnrolled
+		case strings.Contains(err.Error(), `violates foreign key constraint`):
+			return nil, ErrRecordNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	if enrollment.Status == EnrollmentWaitlisted {
+		err = tx.QueryRowContext(ctx, `
+			SELECT count(*)
+			FROM enrollments
+			WHERE course_id = $1 AND status = 'waitlisted' AND id <= $2`, courseID, enrollment.ID).Scan(&enrollment.WaitlistPosition)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return enrollment, tx.Commit()
+}
+
+// Drop removes a student from a course. If the student held a seat, the longest
+// waiting students on the waitlist are promoted into any free seats; the promoted
+// enrollments are returned.
+func (m EnrollmentModel) Drop(courseID, studentID int64) ([]*Enrollment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	capacity, err := lockCourse(ctx, tx, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	var status string
+
+	err = tx.QueryRowContext(ctx, `
+		DELETE FROM enrollments
+		WHERE course_id = $1 AND student_id = $2
+		RETURNING status`, courseID, studentID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	promoted := []*Enrollment{}
+
+	if status == EnrollmentEnrolled {
+		promoted, err = promoteWaitlisted(ctx, tx, courseID, capacity)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return promoted, tx.Commit()
+}
+
+// releaseEnrollments removes a student from every course still in progress, promoting
+// waitlisted students into any seats that open up, and returns the promoted enrollments.
+// A course is in progress until its first grade is recorded. Enrollments in courses
+// with grades are kept, since deleting them would delete the grades with them. Courses
+// are locked in id order so concurrent calls can't deadlock with each other.
+func releaseEnrollments(ctx context.Context, tx *sql.Tx, studentID int64) ([]*Enrollment, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT e.course_id
+		FROM enrollments e
+		WHERE e.student_id = $1 AND NOT EXISTS (
+			SELECT 1
+			FROM grades g
+			JOIN enrollments ge ON ge.id = g.enrollment_id
+			WHERE ge.course_id = e.course_id)
+		ORDER BY e.course_id`, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var courseIDs []int64
+
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		courseIDs = append(courseIDs, id)
+	}
+
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	promoted := []*Enrollment{}
+
+	for _, courseID := range courseIDs {
+		capacity, err := lockCourse(ctx, tx, courseID)
+		if err != nil {
+			return nil, err
+		}
+
+		// The course is checked again now that it is locked, in case it was graded in
+		// the meantime.
+		var status string
+
+		err = tx.QueryRowContext(ctx, `
+			DELETE FROM enrollments e
+			WHERE e.course_id = $1 AND e.student_id = $2 AND NOT EXISTS (
+				SELECT 1
+				FROM grades g
+				JOIN enrollments ge ON ge.id = g.enrollment_id
+				WHERE ge.course_id = e.course_id)
+			RETURNING e.status`, courseID, studentID).Scan(&status)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return nil, err
+		}
+
+		if status != EnrollmentEnrolled {
+			continue
+		}
+
+		p, err := promoteWaitlisted(ctx, tx, courseID, capacity)
+		if err != nil {
+			return nil, err
+		}
+		promoted = append(promoted, p...)
+	}
+
+	return promoted, nil
+}
+
+func (m EnrollmentModel) ListForCourse(courseID int64) ([]*Enrollment, error) {
+	query := `
+		SELECT id, created_at, course_id, student_id, status,
+			CASE WHEN status = 'waitlisted'
+				THEN row_number() OVER (PARTITION BY status ORDER BY id)
+				ELSE 0
+			END
+		FROM enrollments
+		WHERE course_id = $1
+		ORDER BY status, id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			m.ErrorLog.Println(err)
+		}
+	}()
+
+	enrollments := []*Enrollment{}
+
+	for rows.Next() {
+		var e Enrollment
+
+		err := rows.Scan(
+			&e.ID,
+			&e.CreatedAt,
+			&e.CourseID,
+			&e.StudentID,
+			&e.Status,
+			&e.WaitlistPosition,
+		)
+		if err != nil {
+			return nil, err
+		}
+		enrollments = append(enrollments, &e)
+	}
+
+	return enrollments, rows.Err()
+}
+
+func (m EnrollmentModel) ListForStudent(studentID int64) ([]*StudentCourse, error) {
+	byStudent, err := m.ListForStudents([]int64{studentID})
+	if err != nil {
+		return nil, err
+	}
+
+	if courses, ok := byStudent[studentID]; ok {
+		return courses, nil
+	}
+
+	return []*StudentCourse{}, nil
+}
+
+// ListForStudents is ListForStudent for several students in one query, keyed by student
+// id. Students without enrollments are absent from the map.
+func (m EnrollmentModel) ListForStudents(studentIDs []int64) (map[int64][]*StudentCourse, error) {
+	query := `
+		SELECT e.student_id, e.id, e.status,
+			CASE WHEN e.status = 'waitlisted'
+				THEN (SELECT count(*) FROM enrollments w
+					WHERE w.course_id = e.course_id AND w.status = 'waitlisted' AND w.id <= e.id)
+				ELSE 0
+			END,
+			c.id, c.created_at, c.code, c.title, c.credits, c.capacity, c.term, c.version
+		FROM enrollments e
+		JOIN courses c ON c.id = e.course_id
+		WHERE e.student_id = ANY($1)
+		ORDER BY e.student_id, c.term, c.code`
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, pq.Array(studentIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			m.ErrorLog.Println(err)
+		}
+	}()
+
+	courses := map[int64][]*StudentCourse{}
+
+	for rows.Next() {
+		var studentID int64
+		sc := StudentCourse{Course: &Course{}}
+
+		err := rows.Scan(
+			&studentID,
+			&sc.EnrollmentID,
+			&sc.Status,
+			&sc.WaitlistPosition,
+			&sc.Course.ID,
+			&sc.Course.CreatedAt,
+			&sc.Course.Code,
+			&sc.Course.Title,
+			&sc.Course.Credits,
+			&sc.Course.Capacity,
+			&sc.Course.Term,
+			&sc.Course.Version,
+		)
+		if err != nil {
+			return nil, err
+		}
+		courses[studentID] = append(courses[studentID], &sc)
+	}
+
+	return courses, rows.Err()
+}
+
+// lockCourse takes a row lock on the course and returns its capacity.
+func lockCourse(ctx context.Context, tx *sql.Tx, courseID int64) (int32, error) {
+	var capacity int32
+
+	err := tx.QueryRowContext(ctx, `SELECT capacity FROM courses WHERE id = $1 FOR UPDATE`, courseID).Scan(&capacity)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrRecordNotFound
+		}
+		return 0, err
+	}
+
+	return capacity, nil
+}
+
+// promoteWaitlisted moves waitlisted students, oldest first, into whatever seats are
+// free. The caller must hold the course lock taken by lockCourse.
+func promoteWaitlisted(ctx context.Context, tx *sql.Tx, courseID int64, capacity int32) ([]*Enrollment, error) {
+	query := `
+		UPDATE enrollments
+		SET status = 'enrolled'
+		WHERE id IN (
+			SELECT id FROM enrollments
+			WHERE course_id = $1 AND status = 'waitlisted'
+			ORDER BY id
+			LIMIT GREATEST($2 - (SELECT count(*) FROM enrollments WHERE course_id = $1 AND status = 'enrolled'), 0)
+		)
+		RETURNING id, created_at, course_id, student_id, status`
+
+	rows, err := tx.QueryContext(ctx, query, courseID, capacity)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	promoted := []*Enrollment{}
+
+	for rows.Next() {
+		var e Enrollment
+
+		if err := rows.Scan(&e.ID, &e.CreatedAt, &e.CourseID, &e.StudentID, &e.Status); err != nil {
+			return nil, err
+		}
+		promoted = append(promoted, &e)
+	}
+
+	return promoted, rows.Err()
+}
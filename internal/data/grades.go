@@ -0,0 +1,134 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+)
+
+var ErrNotEnrolled = errors.New("enrollment does not hold a seat")
+
+type Grade struct {
+	ID           int64     `json:"id"`
+	CreatedAt    time.Time `json:"-"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	EnrollmentID int64     `json:"enrollment_id"`
+	Grade        string    `json:"grade"`
+	Version      int32     `json:"version"`
+}
+
+// TranscriptEntry is one course a student holds a seat in, with its grade if one has
+// been recorded.
+type TranscriptEntry struct {
+	EnrollmentID int64  `json:"enrollment_id"`
+	CourseID     int64  `json:"course_id"`
+	Code         string `json:"code"`
+	Title        string `json:"title"`
+	Credits      int32  `json:"credits"`
+	Term         string `json:"term"`
+	Grade        string `json:"grade,omitempty"`
+}
+
+type GradeModel struct {
+	DB       *sql.DB
+	InfoLog  *log.Logger
+	ErrorLog *log.Logger
+}
+
+// Set records the grade for an enrollment, replacing any previous grade. Waitlisted
+// enrollments can't be graded. The returned bool is true when a new grade was created.
+func (m GradeModel) Set(enrollmentID int64, grade string) (*Grade, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var status string
+
+	err = tx.QueryRowContext(ctx, `SELECT status FROM enrollments WHERE id = $1 FOR SHARE`, enrollmentID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, false, ErrRecordNotFound
+		}
+		return nil, false, err
+	}
+
+	if status != EnrollmentEnrolled {
+		return nil, false, ErrNotEnrolled
+	}
+
+	query := `
+		INSERT INTO grades (enrollment_id, grade)
+		VALUES ($1, $2)
+		ON CONFLICT (enrollment_id) DO UPDATE
+		SET grade = EXCLUDED.grade, updated_at = NOW(), version = grades.version + 1
+		RETURNING id, created_at, updated_at, version, (xmax = 0)`
+
+	g := Grade{EnrollmentID: enrollmentID, Grade: grade}
+	var created bool
+
+	err = tx.QueryRowContext(ctx, query, enrollmentID, grade).Scan(&g.ID, &g.CreatedAt, &g.UpdatedAt, &g.Version, &created)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+
+	return &g, created, nil
+}
+
+// TranscriptFor returns every course the student holds a seat in. Terms are ordered by
+// when the student first enrolled in them, and courses by code within a term.
+func (m GradeModel) TranscriptFor(studentID int64) ([]*TranscriptEntry, error) {
+	query := `
+		SELECT e.id, c.id, c.code, c.title, c.credits, c.term, COALESCE(g.grade, '')
+		FROM enrollments e
+		JOIN courses c ON c.id = e.course_id
+		LEFT JOIN grades g ON g.enrollment_id = e.id
+		WHERE e.student_id = $1 AND e.status = 'enrolled'
+		ORDER BY min(e.id) OVER (PARTITION BY c.term), c.code`
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			m.ErrorLog.Println(err)
+		}
+	}()
+
+	entries := []*TranscriptEntry{}
+
+	for rows.Next() {
+		var e TranscriptEntry
+
+		err := rows.Scan(
+			&e.EnrollmentID,
+			&e.CourseID,
+			&e.Code,
+			&e.Title,
+			&e.Credits,
+			&e.Term,
+			&e.Grade,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+
+	return entries, rows.Err()
+}
@@ -0,0 +1,220 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sai29/one2n_sre_bootcamp/internal/validator"
+)
+
+type Guardian struct {
+	ID           int64     `json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	StudentID    int64     `json:"student_id"`
+	Name         string    `json:"name"`
+	Relationship string    `json:"relationship"`
+	Email        string    `json:"email"`
+	Phone        string    `json:"phone"`
+	Version      int32     `json:"version"`
+}
+
+func ValidateGuardian(v *validator.Validator, guardian *Guardian) {
+	v.Check(guardian.Name != "", "name", "must be provided")
+	v.Check(len(guardian.Name) <= 500, "name", "must not be more than 500 bytes long")
+
+	v.Check(guardian.Relationship != "", "relationship", "must be provided")
+	v.Check(len(guardian.Relationship) <= 50, "relationship", "must not be more than 50 bytes long")
+
+	v.Check(guardian.Email != "" || guardian.Phone != "", "contact", "an email or phone number must be provided")
+	ValidateContact(v, guardian.Email, guardian.Phone)
+}
+
+type GuardianModel struct {
+	DB       *sql.DB
+	InfoLog  *log.Logger
+	ErrorLog *log.Logger
+}
+
+func (m GuardianModel) Insert(guardian *Guardian) error {
+	query := `
+	INSERT INTO guardians (student_id, name, relationship, email, phone)
+	VALUES ($1, $2, $3, $4, $5)
+	RETURNING id, created_at, updated_at, version`
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	args := []interface{}{guardian.StudentID, guardian.Name, guardian.Relationship, guardian.Email, guardian.Phone}
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&guardian.ID, &guardian.CreatedAt, &guardian.UpdatedAt, &guardian.Version)
+	if err != nil {
+		if strings.Contains(err.Error(), `violates foreign key constraint`) {
+			return ErrRecordNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Get returns a guardian only if it belongs to the given student.
+func (m GuardianModel) Get(studentID, id int64) (*Guardian, error) {
+	query := `
+	SELECT id, created_at, updated_at, student_id, name, relationship, email, phone, version
+	FROM guardians
+	WHERE id = $1 AND student_id = $2`
+
+	var g Guardian
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, id, studentID).Scan(
+		&g.ID,
+		&g.CreatedAt,
+		&g.UpdatedAt,
+		&g.StudentID,
+		&g.Name,
+		&g.Relationship,
+		&g.Email,
+		&g.Phone,
+		&g.Version,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &g, nil
+}
+
+func (m GuardianModel) ListForStudent(studentID int64) ([]*Guardian, error) {
+	byStudent, err := m.ListForStudents([]int64{studentID})
+	if err != nil {
+		return nil, err
+	}
+
+	if guardians, ok := byStudent[studentID]; ok {
+		return guardians, nil
+	}
+
+	return []*Guardian{}, nil
+}
+
+// ListForStudents is ListForStudent for several students in one query, keyed by student
+// id. Students without guardians are absent from the map.
+func (m GuardianModel) ListForStudents(studentIDs []int64) (map[int64][]*Guardian, error) {
+	query := `
+		SELECT id, created_at, updated_at, student_id, name, relationship, email, phone, version
+		FROM guardians
+		WHERE student_id = ANY($1)
+		ORDER BY student_id, id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, pq.Array(studentIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			m.ErrorLog.Println(err)
+		}
+	}()
+
+	guardians := map[int64][]*Guardian{}
+
+	for rows.Next() {
+		var g Guardian
+
+		err := rows.Scan(
+			&g.ID,
+			&g.CreatedAt,
+			&g.UpdatedAt,
+			&g.StudentID,
+			&g.Name,
+			&g.Relationship,
+			&g.Email,
+			&g.Phone,
+			&g.Version,
+		)
+		if err != nil {
+			return nil, err
+		}
+		guardians[g.StudentID] = append(guardians[g.StudentID], &g)
+	}
+
+	return guardians, rows.Err()
+}
+
+func (m GuardianModel) Update(guardian *Guardian) error {
+	query := `
+		UPDATE guardians
+		SET name = $1, relationship = $2, email = $3, phone = $4, updated_at = NOW(), version = version + 1
+		WHERE id = $5 AND student_id = $6 AND version = $7
+		RETURNING version, updated_at`
+
+	args := []interface{}{
+		guardian.Name,
+		guardian.Relationship,
+		guardian.Email,
+		guardian.Phone,
+		guardian.ID,
+		guardian.StudentID,
+		guardian.Version,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&guardian.Version, &guardian.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrEditConflict
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (m GuardianModel) Delete(studentID, id int64) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `
+		DELETE FROM guardians
+		WHERE id = $1 AND student_id = $2
+		`
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id, studentID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,225 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/validator"
+)
+
+const (
+	StudentApplicant = "applicant"
+	StudentEnrolled  = "enrolled"
+	StudentSuspended = "suspended"
+	StudentGraduated = "graduated"
+	StudentWithdrawn = "withdrawn"
+)
+
+var StudentStatuses = []string{StudentApplicant, StudentEnrolled, StudentSuspended, StudentGraduated, StudentWithdrawn}
+
+// InitialStudentStatuses are the states a student may be created in. Existing rosters
+// are imported as enrolled; everyone else starts as an applicant. A student created as
+// enrolled gets an applicant to enrolled transition, so every history starts from the
+// applicant state.
+var InitialStudentStatuses = []string{StudentApplicant, StudentEnrolled}
+
+// recordInitialStatus writes the transition for a student created past the applicant
+// state. It must be called with the transaction that inserted the student.
+func recordInitialStatus(ctx context.Context, tx execer, student *Student) error {
+	if student.Status == StudentApplicant {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO student_transitions (student_id, from_status, to_status, reason, actor)
+		VALUES ($1, $2, $3, 'created', 'system')`,
+		student.ID, StudentApplicant, student.Status,
+	)
+	return err
+}
+
+// studentTransitions lists the states reachable from each state. Graduated and
+// withdrawn are terminal.
+var studentTransitions = map[string][]string{
+	StudentApplicant: {StudentEnrolled, StudentWithdrawn},
+	StudentEnrolled:  {StudentSuspended, StudentGraduated, StudentWithdrawn},
+	StudentSuspended: {StudentEnrolled, StudentWithdrawn},
+	StudentGraduated: {},
+	StudentWithdrawn: {},
+}
+
+// transitionHook runs in the transaction that applies a transition, so its changes commit
+// or roll back with the status change. It returns any waitlisted enrollments it promoted.
+type transitionHook func(ctx context.Context, tx *sql.Tx, t *StudentTransition) ([]*Enrollment, error)
+
+// transitionHooks are keyed by the state being entered.
+var transitionHooks = map[string][]transitionHook{
+	StudentWithdrawn: {dropEnrollmentsHook},
+}
+
+// dropEnrollmentsHook releases a withdrawn student's seats and waitlist places in the
+// courses still in progress.
+func dropEnrollmentsHook(ctx context.Context, tx *sql.Tx, t *StudentTransition) ([]*Enrollment, error) {
+	return releaseEnrollments(ctx, tx, t.StudentID)
+}
+
+// AllowedTransitions returns the states a student in the given state may move to.
+func AllowedTransitions(from string) []string {
+	return slices.Clone(studentTransitions[from])
+}
+
+// CanTransition reports whether moving from one state to another is allowed.
+func CanTransition(from, to string) bool {
+	return slices.Contains(studentTransitions[from], to)
+}
+
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+type StudentTransition struct {
+	ID         int64     `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	StudentID  int64     `json:"student_id"`
+	FromStatus string    `json:"from"`
+	ToStatus   string    `json:"to"`
+	Reason     string    `json:"reason"`
+	Actor      string    `json:"actor"`
+}
+
+func ValidateStudentTransition(v *validator.Validator, t *StudentTransition) {
+	v.Check(validator.PermittedValue(t.ToStatus, StudentStatuses...), "to", "must be a known status")
+
+	v.Check(t.Reason != "", "reason", "must be provided")
+	v.Check(len(t.Reason) <= 1000, "reason", "must not be more than 1000 bytes long")
+
+	v.Check(t.Actor != "", "actor", "must be provided")
+	v.Check(len(t.Actor) <= 200, "actor", "must not be more than 200 bytes long")
+}
+
+type StudentTransitionModel struct {
+	DB       *sql.DB
+	InfoLog  *log.Logger
+	ErrorLog *log.Logger
+}
+
+// Apply moves the student from t.FromStatus to t.ToStatus and records the transition and
+// a student.updated outbox event, all in one transaction with the hooks for the new
+// state. The student row must still be at version and in t.FromStatus, otherwise
+// ErrEditConflict is returned. On success the student's status, version and updated_at
+// and the transition's id are filled in, and the enrollments promoted by the hooks are
+// returned; on any error nothing is changed.
+func (m StudentTransitionModel) Apply(student *Student, t *StudentTransition) ([]*Enrollment, error) {
+	if !CanTransition(t.FromStatus, t.ToStatus) {
+		return nil, ErrInvalidTransition
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// The change is made on copies so that student and t are left untouched if the
+	// transaction doesn't commit.
+	updated := *student
+	recorded := *t
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE students
+		SET status = $1, updated_at = NOW(), version = version + 1
+		WHERE id = $2 AND status = $3 AND version = $4
+		RETURNING version, updated_at`,
+		t.ToStatus, student.ID, t.FromStatus, student.Version,
+	).Scan(&updated.Version, &updated.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrEditConflict
+		}
+		return nil, err
+	}
+
+	updated.Status = t.ToStatus
+	recorded.StudentID = student.ID
+
+	if err := insertStudentEvent(ctx, tx, EventStudentUpdated, &updated); err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO student_transitions (student_id, from_status, to_status, reason, actor)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`,
+		recorded.StudentID, recorded.FromStatus, recorded.ToStatus, recorded.Reason, recorded.Actor,
+	).Scan(&recorded.ID, &recorded.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	promoted := []*Enrollment{}
+
+	for _, hook := range transitionHooks[recorded.ToStatus] {
+		p, err := hook(ctx, tx, &recorded)
+		if err != nil {
+			return nil, err
+		}
+		promoted = append(promoted, p...)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	*student = updated
+	*t = recorded
+	return promoted, nil
+}
+
+func (m StudentTransitionModel) ListForStudent(studentID int64) ([]*StudentTransition, error) {
+	query := `
+		SELECT id, created_at, student_id, from_status, to_status, reason, actor
+		FROM student_transitions
+		WHERE student_id = $1
+		ORDER BY id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			m.ErrorLog.Println(err)
+		}
+	}()
+
+	transitions := []*StudentTransition{}
+
+	for rows.Next() {
+		var t StudentTransition
+
+		err := rows.Scan(
+			&t.ID,
+			&t.CreatedAt,
+			&t.StudentID,
+			&t.FromStatus,
+			&t.ToStatus,
+			&t.Reason,
+			&t.Actor,
+		)
+		if err != nil {
+			return nil, err
+		}
+		transitions = append(transitions, &t)
+	}
+
+	return transitions, rows.Err()
+}
@@ -0,0 +1,101 @@
+package data_test
+
+import (
+	"testing"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+)
+
+func TestStudentModel_InitialTransition(t *testing.T) {
+	db := openTestDB(t)
+	truncate(t, db)
+
+	models := data.NewModels(db)
+
+	applicant := &data.Student{Name: "Ada", RollNo: 1, Status: data.StudentApplicant}
+	if err := models.Students.Insert(applicant); err != nil {
+		t.Fatal(err)
+	}
+
+	enrolled := []*data.Student{{Name: "Bob", RollNo: 2, Status: data.StudentEnrolled}}
+	if err := models.Students.InsertMany(enrolled); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := models.Transitions.ListForStudent(applicant.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 0 {
+		t.Errorf("applicant has transitions %+v", history)
+	}
+
+	history, err = models.Transitions.ListForStudent(enrolled[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].FromStatus != data.StudentApplicant || history[0].ToStatus != data.StudentEnrolled {
+		t.Errorf("expected one applicant to enrolled transition, got %+v", history)
+	}
+}
+
+func TestStudentTransitionModel_WithdrawalKeepsGradedEnrollments(t *testing.T) {
+	db := openTestDB(t)
+	truncate(t, db)
+
+	models := data.NewModels(db)
+
+	var students []*data.Student
+	for i := int32(1); i <= 2; i++ {
+		s := &data.Student{Name: "Student", RollNo: i, Status: data.StudentEnrolled}
+		if err := models.Students.Insert(s); err != nil {
+			t.Fatal(err)
+		}
+		students = append(students, s)
+	}
+	leaving, waiting := students[0], students[1]
+
+	past := &data.Course{Code: "CS101", Title: "Intro", Credits: 4, Capacity: 1, Term: "2024-fall"}
+	current := &data.Course{Code: "CS201", Title: "Systems", Credits: 4, Capacity: 1, Term: "2025-fall"}
+	for _, c := range []*data.Course{past, current} {
+		if err := models.Courses.Insert(c); err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range students {
+			if _, err := models.Enrollments.Enroll(c.ID, s.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// The past course has been graded, so it is over.
+	courses, err := models.Enrollments.ListForStudent(leaving.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sc := range courses {
+		if sc.Course.ID == past.ID {
+			if _, _, err := models.Grades.Set(sc.EnrollmentID, "A"); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	promoted, err := models.Transitions.Apply(leaving, &data.StudentTransition{
+		FromStatus: data.StudentEnrolled, ToStatus: data.StudentWithdrawn, Reason: "moved abroad", Actor: "registrar",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(promoted) != 1 || promoted[0].CourseID != current.ID || promoted[0].StudentID != waiting.ID {
+		t.Fatalf("expected the waiting student to be promoted into the current course only, got %+v", promoted)
+	}
+
+	transcript, err := models.Grades.TranscriptFor(leaving.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transcript) != 1 || transcript[0].Grade != "A" {
+		t.Fatalf("withdrawal changed the transcript: %+v", transcript)
+	}
+}
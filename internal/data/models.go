@@ -1,10 +1,12 @@
 package data
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"log"
 	"os"
+	"time"
 )
 
 var (
@@ -13,18 +15,98 @@ var (
 	ErrEditConflict = errors.New("edit conflict")
 )
 
+// QueryTimeout bounds each single-row or single-statement query made by the models.
+// Bulk operations such as imports and exports have their own, longer timeouts.
+var QueryTimeout = 3 * time.Second
+
 type Models struct {
-	Students StudentStore
+	Students    StudentStore
+	Courses     CourseStore
+	Enrollments EnrollmentStore
+	Grades      GradeStore
+	Attendance  AttendanceStore
+	Guardians   GuardianStore
+	Transitions StudentTransitionStore
+	Outbox      OutboxStore
+	Webhooks    WebhookStore
+
+	// ErrorLog receives errors that can't be returned to the caller, such as cache
+	// failures after a write has committed.
+	ErrorLog *log.Logger
 }
 
 type StudentStore interface {
 	Insert(*Student) error
 	Get(int64) (*Student, error)
 	ListAll() ([]*Student, error)
+	List(ctx context.Context, limit, offset int) ([]*Student, error)
+	ForEach(context.Context, func(*Student) error) error
+	InsertMany([]*Student) error
 	Update(*Student) error
 	Delete(int64) error
 }
 
+type CourseStore interface {
+	Insert(*Course) error
+	Get(int64) (*Course, error)
+	ListAll() ([]*Course, error)
+	Update(*Course) error
+	Delete(int64) error
+}
+
+type EnrollmentStore interface {
+	Enroll(courseID, studentID int64) (*Enrollment, error)
+	Drop(courseID, studentID int64) ([]*Enrollment, error)
+	ListForCourse(courseID int64) ([]*Enrollment, error)
+	ListForStudent(studentID int64) ([]*StudentCourse, error)
+}
+
+type GradeStore interface {
+	Set(enrollmentID int64, grade string) (*Grade, bool, error)
+	TranscriptFor(studentID int64) ([]*TranscriptEntry, error)
+}
+
+type AttendanceStore interface {
+	ResolveRollNos(courseID int64, rollNos []int32) (map[int32][]int64, error)
+	Mark(courseID int64, date Date, marks map[int64]string) (before, after map[int64]*AttendanceSummary, err error)
+	ListForStudent(studentID int64, from, to Date) ([]*AttendanceRecord, error)
+}
+
+type GuardianStore interface {
+	Insert(*Guardian) error
+	Get(studentID, id int64) (*Guardian, error)
+	ListForStudent(studentID int64) ([]*Guardian, error)
+	Update(*Guardian) error
+	Delete(studentID, id int64) error
+}
+
+type StudentTransitionStore interface {
+	Apply(*Student, *StudentTransition) ([]*Enrollment, error)
+	ListForStudent(studentID int64) ([]*StudentTransition, error)
+}
+
+type OutboxStore interface {
+	Process(ctx context.Context, limit int, lease time.Duration, fn func([]*OutboxEvent) error) (int, error)
+	Prune(ctx context.Context, olderThan time.Duration) (int64, error)
+	After(ctx context.Context, afterID int64, limit int) ([]*OutboxEvent, error)
+	LatestID(ctx context.Context) (int64, error)
+	Snapshot(ctx context.Context) (string, error)
+	SnapshotDone(ctx context.Context, snapshot string) (bool, error)
+}
+
+type WebhookStore interface {
+	Insert(*Webhook) error
+	Get(int64) (*Webhook, error)
+	ListAll() ([]*Webhook, error)
+	Update(*Webhook) error
+	Delete(int64) error
+	Enqueue(ctx context.Context, eventID int64, eventType string, payload []byte) (int, error)
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*WebhookDelivery, error)
+	RecordAttempt(ctx context.Context, delivery *WebhookDelivery) error
+	ListDeliveries(webhookID int64, limit int) ([]*WebhookDelivery, error)
+	Redeliver(webhookID, id int64) (*WebhookDelivery, error)
+}
+
 func NewModels(db *sql.DB) Models {
 	infoLog := log.New(os.Stdout, "INFO\t", log.Ldate|log.Ltime)
 	errorLog := log.New(os.Stderr, "Error\t", log.Ldate|log.Ltime|log.Lshortfile)
@@ -34,5 +116,46 @@ func NewModels(db *sql.DB) Models {
 			InfoLog:  infoLog,
 			ErrorLog: errorLog,
 		},
+		Courses: CourseModel{
+			DB:       db,
+			InfoLog:  infoLog,
+			ErrorLog: errorLog,
+		},
+		Enrollments: EnrollmentModel{
+			DB:       db,
+			InfoLog:  infoLog,
+			ErrorLog: errorLog,
+		},
+		Grades: GradeModel{
+			DB:       db,
+			InfoLog:  infoLog,
+			ErrorLog: errorLog,
+		},
+		Attendance: AttendanceModel{
+			DB:       db,
+			InfoLog:  infoLog,
+			ErrorLog: errorLog,
+		},
+		Guardians: GuardianModel{
+			DB:       db,
+			InfoLog:  infoLog,
+			ErrorLog: errorLog,
+		},
+		Transitions: StudentTransitionModel{
+			DB:       db,
+			InfoLog:  infoLog,
+			ErrorLog: errorLog,
+		},
+		Outbox: OutboxModel{
+			DB:       db,
+			InfoLog:  infoLog,
+			ErrorLog: errorLog,
+		},
+		Webhooks: WebhookModel{
+			DB:       db,
+			InfoLog:  infoLog,
+			ErrorLog: errorLog,
+		},
+		ErrorLog: errorLog,
 	}
 }
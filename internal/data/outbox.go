@@ -0,0 +1,216 @@
+package data
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Student event types written to the outbox.
+const (
+	EventStudentCreated = "student.created"
+	EventStudentUpdated = "student.updated"
+	EventStudentDeleted = "student.deleted"
+)
+
+var StudentEventTypes = []string{EventStudentCreated, EventStudentUpdated, EventStudentDeleted}
+
+// OutboxEvent is a domain event recorded in the same transaction as the change it
+// describes. Payload holds the JSON encoding of the aggregate after the change (before it,
+// for deletes).
+type OutboxEvent struct {
+	ID            int64           `json:"id"`
+	CreatedAt     time.Time       `json:"occurred_at"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   int64           `json:"aggregate_id"`
+	Type          string          `json:"type"`
+	Payload       json.RawMessage `json:"data"`
+}
+
+// execer is satisfied by *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// insertStudentEvent records a student event. It must be called with the transaction that
+// made the change so the event is only visible if the change commits.
+func insertStudentEvent(ctx context.Context, tx execer, eventType string, student *Student) error {
+	payload, err := json.Marshal(student)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO outbox (aggregate_type, aggregate_id, event_type, payload)
+		VALUES ('student', $1, $2, $3)`,
+		student.ID, eventType, payload,
+	)
+	return err
+}
+
+type OutboxModel struct {
+	DB       *sql.DB
+	InfoLog  *log.Logger
+	ErrorLog *log.Logger
+}
+
+// Process claims up to limit unpublished events in id order and passes them to fn. If fn
+// returns nil the events are marked published; otherwise the claim is released and they
+// are offered again on a later call.
+//
+// Claiming is a statement of its own that reserves the events for lease, so no transaction
+// is held open while fn talks to the sinks. Several API instances can relay concurrently
+// without handing out the same event twice, unless fn outlives the lease, in which case
+// another instance may publish the events as well.
+func (m OutboxModel) Process(ctx context.Context, limit int, lease time.Duration, fn func([]*OutboxEvent) error) (int, error) {
+	rows, err := m.DB.QueryContext(ctx, `
+		UPDATE outbox
+		SET claimed_until = NOW() + $2 * interval '1 millisecond'
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE published_at IS NULL AND (claimed_until IS NULL OR claimed_until < NOW())
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, created_at, aggregate_type, aggregate_id, event_type, payload`, limit, lease.Milliseconds())
+	if err != nil {
+		return 0, err
+	}
+
+	events := []*OutboxEvent{}
+	ids := []int64{}
+
+	for rows.Next() {
+		var e OutboxEvent
+
+		if err := rows.Scan(&e.ID, &e.CreatedAt, &e.AggregateType, &e.AggregateID, &e.Type, &e.Payload); err != nil {
+			_ = rows.Close()
+			return 0, err
+		}
+
+		events = append(events, &e)
+		ids = append(ids, e.ID)
+	}
+
+	if err := rows.Close(); err != nil {
+		m.ErrorLog.Println(err)
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	// RETURNING does not keep the order of the subquery.
+	slices.SortFunc(events, func(a, b *OutboxEvent) int { return cmp.Compare(a.ID, b.ID) })
+
+	if err := fn(events); err != nil {
+		// Use a fresh context so the claim is released even when ctx is what failed.
+		releaseCtx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+		defer cancel()
+
+		_, rerr := m.DB.ExecContext(releaseCtx, `UPDATE outbox SET claimed_until = NULL WHERE id = ANY($1)`, pq.Array(ids))
+		if rerr != nil {
+			m.ErrorLog.Println(rerr)
+		}
+		return 0, err
+	}
+
+	_, err = m.DB.ExecContext(ctx, `
+		UPDATE outbox
+		SET published_at = NOW(), claimed_until = NULL
+		WHERE id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return 0, err
+	}
+
+	return len(events), nil
+}
+
+// Prune deletes events that were published more than olderThan ago and returns how many
+// were removed.
+func (m OutboxModel) Prune(ctx context.Context, olderThan time.Duration) (int64, error) {
+	result, err := m.DB.ExecContext(ctx, `
+		DELETE FROM outbox
+		WHERE published_at < NOW() - $1 * interval '1 millisecond'`, olderThan.Milliseconds())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// After returns up to limit committed events with ids above afterID, in id order, whether
+// or not they have been published. Ids are taken when a transaction writes its event, not
+// when it commits, so a lower id can still appear after a higher one has been returned.
+func (m OutboxModel) After(ctx context.Context, afterID int64, limit int) ([]*OutboxEvent, error) {
+	rows, err := m.DB.QueryContext(ctx, `
+		SELECT id, created_at, aggregate_type, aggregate_id, event_type, payload
+		FROM outbox
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2`, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			m.ErrorLog.Println(err)
+		}
+	}()
+
+	events := []*OutboxEvent{}
+
+	for rows.Next() {
+		var e OutboxEvent
+
+		if err := rows.Scan(&e.ID, &e.CreatedAt, &e.AggregateType, &e.AggregateID, &e.Type, &e.Payload); err != nil {
+			return nil, err
+		}
+		events = append(events, &e)
+	}
+
+	return events, rows.Err()
+}
+
+// LatestID returns the highest event id written so far, or 0 when the outbox is empty.
+func (m OutboxModel) LatestID(ctx context.Context) (int64, error) {
+	var id int64
+
+	err := m.DB.QueryRowContext(ctx, `SELECT COALESCE(MAX(id), 0) FROM outbox`).Scan(&id)
+	return id, err
+}
+
+// Snapshot returns the current transaction snapshot, which lists the write transactions
+// in progress. A missing event id belongs to a transaction that was in progress when its
+// gap was seen, so the gap can't fill once they have all ended.
+func (m OutboxModel) Snapshot(ctx context.Context) (string, error) {
+	var snapshot string
+
+	err := m.DB.QueryRowContext(ctx, `SELECT pg_current_snapshot()::text`).Scan(&snapshot)
+	return snapshot, err
+}
+
+// SnapshotDone reports whether every write transaction in progress when snapshot was
+// taken has committed or rolled back.
+func (m OutboxModel) SnapshotDone(ctx context.Context, snapshot string) (bool, error) {
+	var done bool
+
+	err := m.DB.QueryRowContext(ctx, `
+		SELECT NOT EXISTS (
+			SELECT 1
+			FROM pg_snapshot_xip($1::pg_snapshot) AS xip(xid)
+			WHERE pg_xact_status(xid) = 'in progress')`, snapshot).Scan(&done)
+	return done, err
+}
@@ -0,0 +1,191 @@
+package data_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+)
+
+// outboxEvents returns the types of the events recorded for a student, in id order.
+func outboxEvents(t *testing.T, db *sql.DB, studentID int64) []string {
+	t.Helper()
+
+	rows, err := db.Query(`SELECT event_type FROM outbox WHERE aggregate_id = $1 ORDER BY id`, studentID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var types []string
+	for rows.Next() {
+		var typ string
+		if err := rows.Scan(&typ); err != nil {
+			t.Fatal(err)
+		}
+		types = append(types, typ)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return types
+}
+
+func TestOutbox_CommitsWithStudentWrite(t *testing.T) {
+	db := openTestDB(t)
+	truncate(t, db)
+
+	models := data.NewModels(db)
+
+	student := &data.Student{Name: "Ada", RollNo: 1, Status: data.StudentApplicant}
+	if err := models.Students.Insert(student); err != nil {
+		t.Fatal(err)
+	}
+
+	// A transition whose event commits with it, then one that conflicts and must leave
+	// neither an event nor a changed student behind.
+	if _, err := models.Transitions.Apply(student, &data.StudentTransition{
+		FromStatus: data.StudentApplicant, ToStatus: data.StudentEnrolled, Reason: "admitted", Actor: "registrar",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	stale := *student
+	stale.Version--
+
+	_, err := models.Transitions.Apply(&stale, &data.StudentTransition{
+		FromStatus: data.StudentEnrolled, ToStatus: data.StudentSuspended, Reason: "late fees", Actor: "registrar",
+	})
+	if !errors.Is(err, data.ErrEditConflict) {
+		t.Fatalf("expected ErrEditConflict, got %v", err)
+	}
+	if stale.Status != data.StudentEnrolled || stale.Version != student.Version-1 {
+		t.Fatalf("failed transition changed the student: %+v", stale)
+	}
+
+	// The second student violates the status check, so the whole batch rolls back,
+	// including the event written for the first.
+	batch := []*data.Student{
+		{Name: "Bob", RollNo: 2, Status: data.StudentApplicant},
+		{Name: "Cy", RollNo: 3, Status: "bogus"},
+	}
+	if err := models.Students.InsertMany(batch); err == nil {
+		t.Fatal("expected the batch insert to fail")
+	}
+
+	if got := outboxEvents(t, db, student.ID); len(got) != 2 || got[0] != data.EventStudentCreated || got[1] != data.EventStudentUpdated {
+		t.Fatalf("expected created and updated events, got %v", got)
+	}
+
+	var total int
+	if err := db.QueryRow(`SELECT count(*) FROM outbox`).Scan(&total); err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 {
+		t.Fatalf("expected only the committed writes to leave events, got %d", total)
+	}
+}
+
+func TestOutboxModel_ProcessAndPrune(t *testing.T) {
+	db := openTestDB(t)
+	truncate(t, db)
+
+	models := data.NewModels(db)
+
+	for i := int32(1); i <= 3; i++ {
+		if err := models.Students.Insert(&data.Student{Name: "Student", RollNo: i, Status: data.StudentApplicant}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := context.Background()
+
+	if latest, err := models.Outbox.LatestID(ctx); err != nil || latest != 3 {
+		t.Fatalf("expected latest id 3, got %d, %v", latest, err)
+	}
+	if events, err := models.Outbox.After(ctx, 1, 10); err != nil || len(events) != 2 || events[0].ID != 2 {
+		t.Fatalf("expected events 2 and 3 after 1, got %v, %v", events, err)
+	}
+
+	// A failed batch releases its claim, so the events are offered again straight away.
+	_, err := models.Outbox.Process(ctx, 10, time.Minute, func([]*data.OutboxEvent) error {
+		return errors.New("sink down")
+	})
+	if err == nil {
+		t.Fatal("expected the sink error")
+	}
+
+	// While a batch is claimed, a concurrent relay is handed the remaining events only.
+	var inner []int64
+	n, err := models.Outbox.Process(ctx, 2, time.Minute, func(events []*data.OutboxEvent) error {
+		_, err := models.Outbox.Process(ctx, 10, time.Minute, func(events []*data.OutboxEvent) error {
+			for _, e := range events {
+				inner = append(inner, e.ID)
+			}
+			return nil
+		})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 || len(inner) != 1 || inner[0] != 3 {
+		t.Fatalf("expected 2 events outside and event 3 inside, got %d and %v", n, inner)
+	}
+
+	if n, err := models.Outbox.Process(ctx, 10, time.Minute, func([]*data.OutboxEvent) error { return nil }); err != nil || n != 0 {
+		t.Fatalf("expected nothing left to publish, got %d, %v", n, err)
+	}
+
+	if n, err := models.Outbox.Prune(ctx, time.Hour); err != nil || n != 0 {
+		t.Fatalf("expected recent events to be kept, got %d, %v", n, err)
+	}
+	if _, err := db.Exec(`UPDATE outbox SET published_at = published_at - interval '2 hours'`); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := models.Outbox.Prune(ctx, time.Hour); err != nil || n != 3 {
+		t.Fatalf("expected 3 published events pruned, got %d, %v", n, err)
+	}
+}
+
+func TestOutboxModel_SnapshotDone(t *testing.T) {
+	db := openTestDB(t)
+	truncate(t, db)
+
+	models := data.NewModels(db)
+	ctx := context.Background()
+
+	// A transaction holding an event id, as a gap in the ids would be.
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.Exec(`
+		INSERT INTO outbox (aggregate_type, aggregate_id, event_type, payload)
+		VALUES ('student', 1, 'student.created', '{}')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := models.Outbox.Snapshot(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if done, err := models.Outbox.SnapshotDone(ctx, snapshot); err != nil || done {
+		t.Fatalf("expected the open transaction to keep the snapshot pending, got %v, %v", done, err)
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	if done, err := models.Outbox.SnapshotDone(ctx, snapshot); err != nil || !done {
+		t.Fatalf("expected the snapshot to be done after the rollback, got %v, %v", done, err)
+	}
+}
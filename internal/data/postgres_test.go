@@ -0,0 +1,51 @@
+package data_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/migrate"
+	"github.com/sai29/one2n_sre_bootcamp/migrations"
+
+	_ "github.com/lib/pq"
+)
+
+// openTestDB connects to the database TEST_DATABASE_URL points at and migrates it,
+// skipping the test when the variable is not set. The database is truncated by the
+// tests, so it must not hold anything worth keeping.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	m, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+// truncate empties the students, courses and outbox tables and everything that refers
+// to them.
+func truncate(t *testing.T, db *sql.DB) {
+	t.Helper()
+
+	if _, err := db.Exec(`TRUNCATE students, courses, outbox RESTART IDENTITY CASCADE`); err != nil {
+		t.Fatal(err)
+	}
+}
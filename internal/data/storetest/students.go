@@ -0,0 +1,299 @@
+// Package storetest holds conformance tests that every data store implementation must pass,
+// so the Postgres, SQLite and in-memory backends stay interchangeable.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+)
+
+// StudentStore runs the StudentStore conformance suite. newStore must return an empty store
+// for each call.
+func StudentStore(t *testing.T, newStore func(t *testing.T) data.StudentStore) {
+	t.Helper()
+
+	t.Run("InsertAndGet", func(t *testing.T) {
+		store := newStore(t)
+
+		dob, _ := data.ParseDate("2004-05-06")
+		in := &data.Student{
+			Name:        "Asha",
+			RollNo:      7,
+			Email:       "asha@example.com",
+			Phone:       "+919800000000",
+			DateOfBirth: &dob,
+			Address:     "12 Park Street",
+			Status:      data.StudentApplicant,
+		}
+
+		if err := store.Insert(in); err != nil {
+			t.Fatalf("Insert: %v", err)
+		}
+
+		if in.ID < 1 || in.Version != 1 || in.CreatedAt.IsZero() || in.UpdatedAt.IsZero() {
+			t.Fatalf("Insert did not fill generated fields: %+v", in)
+		}
+
+		got, err := store.Get(in.ID)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+
+		assertSameStudent(t, got, in)
+	})
+
+	t.Run("GetMissing", func(t *testing.T) {
+		store := newStore(t)
+
+		if _, err := store.Get(42); !errors.Is(err, data.ErrRecordNotFound) {
+			t.Fatalf("expected ErrRecordNotFound, got %v", err)
+		}
+	})
+
+	t.Run("ListAllOrderedByID", func(t *testing.T) {
+		store := newStore(t)
+
+		students, err := store.ListAll()
+		if err != nil {
+			t.Fatalf("ListAll: %v", err)
+		}
+		if students == nil || len(students) != 0 {
+			t.Fatalf("expected an empty, non-nil slice, got %#v", students)
+		}
+
+		insert(t, store, "A", 1)
+		insert(t, store, "B", 2)
+		insert(t, store, "C", 3)
+
+		students, err = store.ListAll()
+		if err != nil {
+			t.Fatalf("ListAll: %v", err)
+		}
+
+		if len(students) != 3 {
+			t.Fatalf("expected 3 students, got %d", len(students))
+		}
+		for i := 1; i < len(students); i++ {
+			if students[i-1].ID >= students[i].ID {
+				t.Fatalf("students not in id order: %d before %d", students[i-1].ID, students[i].ID)
+			}
+		}
+	})
+
+	t.Run("ListPages", func(t *testing.T) {
+		store := newStore(t)
+
+		a := insert(t, store, "A", 1)
+		b := insert(t, store, "B", 2)
+		c := insert(t, store, "C", 3)
+
+		tests := []struct {
+			limit, offset int
+			want          []int64
+		}{
+			{2, 0, []int64{a.ID, b.ID}},
+			{2, 2, []int64{c.ID}},
+			{5, 1, []int64{b.ID, c.ID}},
+			{2, 3, []int64{}},
+		}
+
+		for _, tt := range tests {
+			students, err := store.List(context.Background(), tt.limit, tt.offset)
+			if err != nil {
+				t.Fatalf("List(%d, %d): %v", tt.limit, tt.offset, err)
+			}
+			if students == nil {
+				t.Fatalf("List(%d, %d) returned a nil slice", tt.limit, tt.offset)
+			}
+
+			got := make([]int64, len(students))
+			for i, s := range students {
+				got[i] = s.ID
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("List(%d, %d) = %v, want %v", tt.limit, tt.offset, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("ForEachStopsOnError", func(t *testing.T) {
+		store := newStore(t)
+
+		insert(t, store, "A", 1)
+		insert(t, store, "B", 2)
+
+		stop := errors.New("stop")
+		calls := 0
+
+		err := store.ForEach(context.Background(), func(*data.Student) error {
+			calls++
+			return stop
+		})
+
+		if !errors.Is(err, stop) {
+			t.Fatalf("expected the callback error, got %v", err)
+		}
+		if calls != 1 {
+			t.Fatalf("expected 1 call, got %d", calls)
+		}
+	})
+
+	t.Run("InsertMany", func(t *testing.T) {
+		store := newStore(t)
+
+		students := []*data.Student{
+			{Name: "A", RollNo: 1, Status: data.StudentApplicant},
+			{Name: "B", RollNo: 2, Status: data.StudentEnrolled},
+		}
+
+		if err := store.InsertMany(students); err != nil {
+			t.Fatalf("InsertMany: %v", err)
+		}
+
+		for _, s := range students {
+			got, err := store.Get(s.ID)
+			if err != nil {
+				t.Fatalf("Get(%d): %v", s.ID, err)
+			}
+			assertSameStudent(t, got, s)
+		}
+	})
+
+	t.Run("UpdateBumpsVersionAndKeepsStatus", func(t *testing.T) {
+		store := newStore(t)
+
+		s := insert(t, store, "Old", 1)
+
+		s.Name = "New"
+		s.Status = data.StudentGraduated
+
+		if err := store.Update(s); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		if s.Version != 2 {
+			t.Fatalf("expected version 2, got %d", s.Version)
+		}
+		if s.Status != data.StudentApplicant {
+			t.Fatalf("Update must not change status, got %q", s.Status)
+		}
+
+		got, err := store.Get(s.ID)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		assertSameStudent(t, got, s)
+	})
+
+	t.Run("UpdateConflicts", func(t *testing.T) {
+		store := newStore(t)
+
+		s := insert(t, store, "A", 1)
+
+		stale := *s
+		if err := store.Update(s); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+
+		if err := store.Update(&stale); !errors.Is(err, data.ErrEditConflict) {
+			t.Fatalf("expected ErrEditConflict for a stale version, got %v", err)
+		}
+
+		missing := &data.Student{ID: s.ID + 100, Name: "X", RollNo: 1, Version: 1}
+		if err := store.Update(missing); !errors.Is(err, data.ErrEditConflict) {
+			t.Fatalf("expected ErrEditConflict for a missing record, got %v", err)
+		}
+	})
+
+	t.Run("ConcurrentUpdatesOneWins", func(t *testing.T) {
+		store := newStore(t)
+
+		s := insert(t, store, "A", 1)
+
+		const writers = 8
+
+		var (
+			wg        sync.WaitGroup
+			mu        sync.Mutex
+			succeeded int
+		)
+
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				c := *s
+				err := store.Update(&c)
+
+				mu.Lock()
+				defer mu.Unlock()
+				switch {
+				case err == nil:
+					succeeded++
+				case !errors.Is(err, data.ErrEditConflict):
+					t.Errorf("unexpected error: %v", err)
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		if succeeded != 1 {
+			t.Fatalf("expected exactly one update to succeed, got %d", succeeded)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		store := newStore(t)
+
+		s := insert(t, store, "A", 1)
+
+		if err := store.Delete(s.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+
+		if _, err := store.Get(s.ID); !errors.Is(err, data.ErrRecordNotFound) {
+			t.Fatalf("expected ErrRecordNotFound after delete, got %v", err)
+		}
+
+		if err := store.Delete(s.ID); !errors.Is(err, data.ErrRecordNotFound) {
+			t.Fatalf("expected ErrRecordNotFound deleting twice, got %v", err)
+		}
+	})
+}
+
+func insert(t *testing.T, store data.StudentStore, name string, rollNo int32) *data.Student {
+	t.Helper()
+
+	s := &data.Student{Name: name, RollNo: rollNo, Status: data.StudentApplicant}
+	if err := store.Insert(s); err != nil {
+		t.Fatalf("Insert: %v", err)
+	}
+	return s
+}
+
+func assertSameStudent(t *testing.T, got, want *data.Student) {
+	t.Helper()
+
+	if got.ID != want.ID || got.Name != want.Name || got.RollNo != want.RollNo ||
+		got.Email != want.Email || got.Phone != want.Phone || got.Address != want.Address ||
+		got.Status != want.Status || got.Version != want.Version {
+		t.Fatalf("student mismatch:\n got  %+v\n want %+v", got, want)
+	}
+
+	if (got.DateOfBirth == nil) != (want.DateOfBirth == nil) ||
+		(got.DateOfBirth != nil && got.DateOfBirth.String() != want.DateOfBirth.String()) {
+		t.Fatalf("date_of_birth mismatch: got %v, want %v", got.DateOfBirth, want.DateOfBirth)
+	}
+
+	if !got.CreatedAt.Equal(want.CreatedAt) {
+		t.Fatalf("created_at mismatch: got %v, want %v", got.CreatedAt, want.CreatedAt)
+	}
+}
@@ -5,15 +5,150 @@ import (
 	"database/sql"
 	"errors"
 	"log"
+	"regexp"
+	"strings"
 	"time"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/validator"
 )
 
 type Student struct {
-	ID        int64     `json:"id"`
-	CreatedAt time.Time `json:"-"`
-	Name      string    `json:"name"`
-	RollNo    int32     `json:"rollno"`
-	Version   int32     `json:"version"`
+	ID          int64     `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Name        string    `json:"name"`
+	RollNo      int32     `json:"rollno"`
+	Email       string    `json:"email"`
+	Phone       string    `json:"phone"`
+	DateOfBirth *Date     `json:"date_of_birth"`
+	Address     string    `json:"address"`
+	Status      string    `json:"status"`
+	Version     int32     `json:"version"`
+}
+
+func ValidateStudent(v *validator.Validator, student *Student) {
+	v.Check(student.Name != "", "name", "must be provided")
+	v.Check(len(student.Name) <= 500, "name", "must not be more than 500 bytes long")
+
+	v.Check(student.RollNo > 0, "rollno", "must be a positive integer")
+
+	ValidateContact(v, student.Email, student.Phone)
+
+	if student.DateOfBirth != nil {
+		v.Check(!student.DateOfBirth.After(time.Now()), "date_of_birth", "must not be in the future")
+		v.Check(student.DateOfBirth.Year() >= 1900, "date_of_birth", "must not be before 1900")
+	}
+
+	v.Check(len(student.Address) <= 1000, "address", "must not be more than 1000 bytes long")
+
+	v.Check(validator.PermittedValue(student.Status, StudentStatuses...), "status", "must be one of "+strings.Join(StudentStatuses, ", "))
+}
+
+// ValidateNewStudent applies ValidateStudent and also checks that the student starts
+// in one of the initial lifecycle states.
+func ValidateNewStudent(v *validator.Validator, student *Student) {
+	ValidateStudent(v, student)
+
+	v.Check(validator.PermittedValue(student.Status, InitialStudentStatuses...), "status", "new students must start as one of "+strings.Join(InitialStudentStatuses, ", "))
+}
+
+// StudentInput holds the student fields of a create or update request, whichever API it
+// came through. A nil field was not given. DateOfBirth is a YYYY-MM-DD string; an empty
+// one means no date.
+type StudentInput struct {
+	Name        *string
+	RollNo      *int32
+	Email       *string
+	Phone       *string
+	DateOfBirth *string
+	Address     *string
+	Status      *string
+}
+
+// NewStudent builds a student from the input and checks it with ValidateNewStudent. A
+// student given no status starts as an applicant.
+func (in StudentInput) NewStudent(v *validator.Validator) *Student {
+	student := &Student{Status: StudentApplicant}
+
+	if in.Status != nil && *in.Status != "" {
+		student.Status = *in.Status
+	}
+
+	in.copyProfile(v, student)
+	ValidateNewStudent(v, student)
+
+	return student
+}
+
+// ApplyTo copies the fields that were given onto student and checks the result with
+// ValidateStudent. Status is refused; it only changes through StudentTransitionModel.Apply.
+func (in StudentInput) ApplyTo(v *validator.Validator, student *Student) {
+	v.Check(in.Status == nil, "status", "can only be changed through a status transition")
+
+	in.copyProfile(v, student)
+	ValidateStudent(v, student)
+}
+
+func (in StudentInput) copyProfile(v *validator.Validator, student *Student) {
+	if in.Name != nil {
+		student.Name = *in.Name
+	}
+
+	if in.RollNo != nil {
+		student.RollNo = *in.RollNo
+	}
+
+	if in.Email != nil {
+		student.Email = *in.Email
+	}
+
+	if in.Phone != nil {
+		student.Phone = *in.Phone
+	}
+
+	if in.Address != nil {
+		student.Address = *in.Address
+	}
+
+	if in.DateOfBirth != nil {
+		student.DateOfBirth = nil
+
+		if *in.DateOfBirth != "" {
+			d, err := ParseDate(*in.DateOfBirth)
+			if err != nil {
+				v.AddError("date_of_birth", "must be a date in the form YYYY-MM-DD")
+			} else {
+				student.DateOfBirth = &d
+			}
+		}
+	}
+}
+
+// PhoneRX matches an E.164 style number once spaces, dashes, dots and brackets have been
+// removed by NormalizePhone.
+var PhoneRX = regexp.MustCompile(`^\+?[1-9][0-9]{6,14}$`)
+
+// NormalizePhone strips the separators people commonly type in phone numbers.
+func NormalizePhone(phone string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, phone)
+}
+
+// ValidateContact checks optional email and phone fields; empty values are allowed.
+func ValidateContact(v *validator.Validator, email, phone string) {
+	if email != "" {
+		v.Check(len(email) <= 254, "email", "must not be more than 254 bytes long")
+		v.Check(validator.Matches(email, validator.EmailRX), "email", "must be a valid email address")
+	}
+
+	if phone != "" {
+		v.Check(validator.Matches(NormalizePhone(phone), PhoneRX), "phone", "must be a valid phone number")
+	}
 }
 
 type StudentModel struct {
@@ -22,40 +157,123 @@ type StudentModel struct {
 	ErrorLog *log.Logger
 }
 
+// studentColumns is the select list understood by scanStudent.
+const studentColumns = `id, created_at, updated_at, name, rollno, email, phone, date_of_birth, address, status, version`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanStudent(row rowScanner, s *Student) error {
+	return row.Scan(
+		&s.ID,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+		&s.Name,
+		&s.RollNo,
+		&s.Email,
+		&s.Phone,
+		&s.DateOfBirth,
+		&s.Address,
+		&s.Status,
+		&s.Version,
+	)
+}
+
+// Insert writes the student, its student.created outbox event and, for a student that
+// does not start as an applicant, its initial transition in one transaction.
 func (m StudentModel) Insert(student *Student) error {
 	query := `
-	INSERT INTO students (name, rollno)
-	VALUES ($1, $2)
-	RETURNING id, created_at, version`
+	INSERT INTO students (name, rollno, email, phone, date_of_birth, address, status)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING id, created_at, updated_at, version`
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	err = tx.QueryRowContext(ctx, query, studentArgs(student)...).Scan(&student.ID, &student.CreatedAt, &student.UpdatedAt, &student.Version)
+	if err != nil {
+		return err
+	}
+
+	if err := insertStudentEvent(ctx, tx, EventStudentCreated, student); err != nil {
+		return err
+	}
+
+	if err := recordInitialStatus(ctx, tx, student); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// InsertMany inserts all students, with one student.created event and any initial
+// transition each, in a single transaction, so either every row is written or none are.
+func (m StudentModel) InsertMany(students []*Student) error {
+	query := `
+	INSERT INTO students (name, rollno, email, phone, date_of_birth, address, status)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	RETURNING id, created_at, updated_at, version`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	args := []interface{}{student.Name, student.RollNo}
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for _, student := range students {
+		err := stmt.QueryRowContext(ctx, studentArgs(student)...).Scan(&student.ID, &student.CreatedAt, &student.UpdatedAt, &student.Version)
+		if err != nil {
+			return err
+		}
+
+		if err := insertStudentEvent(ctx, tx, EventStudentCreated, student); err != nil {
+			return err
+		}
+
+		if err := recordInitialStatus(ctx, tx, student); err != nil {
+			return err
+		}
+	}
 
-	return m.DB.QueryRowContext(ctx, query, args...).Scan(&student.ID, &student.CreatedAt, &student.Version)
+	return tx.Commit()
+}
+
+func studentArgs(s *Student) []interface{} {
+	return []interface{}{s.Name, s.RollNo, s.Email, s.Phone, s.DateOfBirth, s.Address, s.Status}
 }
 
 func (m StudentModel) Get(id int64) (*Student, error) {
 	query := `
-	SELECT id, created_at, name, rollno, version 
-	FROM students 
+	SELECT ` + studentColumns + `
+	FROM students
 	WHERE id = $1`
 
 	var student Student
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
 	defer cancel()
 
-	err := m.DB.QueryRowContext(ctx, query, id).Scan(
-		&student.ID,
-		&student.CreatedAt,
-		&student.Name,
-		&student.RollNo,
-		&student.Version,
-	)
-
+	err := scanStudent(m.DB.QueryRowContext(ctx, query, id), &student)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
 		return nil, err
 	}
 
@@ -64,12 +282,32 @@ func (m StudentModel) Get(id int64) (*Student, error) {
 
 func (m StudentModel) ListAll() ([]*Student, error) {
 	query := `
-		SELECT * FROM students ORDER BY id`
+		SELECT ` + studentColumns + `
+		FROM students
+		ORDER BY id`
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
 	defer cancel()
 
-	rows, err := m.DB.QueryContext(ctx, query)
+	return m.query(ctx, query)
+}
+
+// List returns up to limit students in id order, skipping the first offset.
+func (m StudentModel) List(ctx context.Context, limit, offset int) ([]*Student, error) {
+	query := `
+		SELECT ` + studentColumns + `
+		FROM students
+		ORDER BY id
+		LIMIT $1 OFFSET $2`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	return m.query(ctx, query, limit, offset)
+}
+
+func (m StudentModel) query(ctx context.Context, query string, args ...any) ([]*Student, error) {
+	rows, err := m.DB.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -85,47 +323,81 @@ func (m StudentModel) ListAll() ([]*Student, error) {
 	for rows.Next() {
 		var s Student
 
-		err := rows.Scan(
-			&s.ID,
-			&s.CreatedAt,
-			&s.Name,
-			&s.RollNo,
-			&s.Version,
-		)
-		if err != nil {
+		if err := scanStudent(rows, &s); err != nil {
 			return nil, err
 		}
 		students = append(students, &s)
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
 	return students, rows.Err()
+}
+
+// ForEach streams every student in id order to fn, reading rows straight from the
+// cursor rather than building a slice. Iteration stops at the first error returned by fn.
+func (m StudentModel) ForEach(ctx context.Context, fn func(*Student) error) error {
+	query := `
+		SELECT ` + studentColumns + `
+		FROM students
+		ORDER BY id`
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			m.ErrorLog.Println(err)
+		}
+	}()
+
+	for rows.Next() {
+		var s Student
 
+		if err := scanStudent(rows, &s); err != nil {
+			return err
+		}
+
+		if err := fn(&s); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
 }
 
+// Update saves profile changes using optimistic locking on version and records a
+// student.updated event. Status is not written here; it only changes through
+// StudentTransitionModel.Apply.
 func (m StudentModel) Update(student *Student) error {
 	query := `
 		UPDATE students
-		SET name = $1, rollno = $2, version = version + 1
-		WHERE id = $3 AND version = $4
-		RETURNING version, created_at`
+		SET name = $1, rollno = $2, email = $3, phone = $4, date_of_birth = $5, address = $6,
+			updated_at = NOW(), version = version + 1
+		WHERE id = $7 AND version = $8
+		RETURNING version, created_at, updated_at, status`
 
 	args := []interface{}{
 		student.Name,
 		student.RollNo,
+		student.Email,
+		student.Phone,
+		student.DateOfBirth,
+		student.Address,
 		student.ID,
 		student.Version,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
 	defer cancel()
 
-	var newVersion int32
-	var createdAt time.Time
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
 
-	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&newVersion, &createdAt)
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&student.Version, &student.CreatedAt, &student.UpdatedAt, &student.Status)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return ErrEditConflict
@@ -133,11 +405,15 @@ func (m StudentModel) Update(student *Student) error {
 		return err
 	}
 
-	student.Version = newVersion
-	student.CreatedAt = createdAt
-	return nil
+	if err := insertStudentEvent(ctx, tx, EventStudentUpdated, student); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
+// Delete removes the student and records a student.deleted event carrying the row as it
+// was before deletion.
 func (m StudentModel) Delete(id int64) error {
 	if id < 1 {
 		return ErrRecordNotFound
@@ -146,24 +422,30 @@ func (m StudentModel) Delete(id int64) error {
 	query := `
 		DELETE FROM students
 		WHERE id = $1
-		`
+		RETURNING ` + studentColumns
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
 	defer cancel()
 
-	result, err := m.DB.ExecContext(ctx, query, id)
+	tx, err := m.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer func() { _ = tx.Rollback() }()
+
+	var student Student
 
-	rowsAffected, err := result.RowsAffected()
+	err = scanStudent(tx.QueryRowContext(ctx, query, id), &student)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrRecordNotFound
+		}
 		return err
 	}
 
-	if rowsAffected == 0 {
-		return ErrRecordNotFound
+	if err := insertStudentEvent(ctx, tx, EventStudentDeleted, &student); err != nil {
+		return err
 	}
 
-	return nil
+	return tx.Commit()
 }
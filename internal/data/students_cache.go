@@ -0,0 +1,238 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/cache"
+)
+
+// CachingStudentStore wraps a StudentStore with a read-through cache for Get. Writes go
+// straight to the wrapped store and then invalidate the cached entry.
+//
+// Invalidation alone is racy: a Get that read the old row just before an Update can write
+// it back into the cache just after. To refuse such entries every write also records a
+// version watermark for the student, and cached or about-to-be-cached copies older than
+// the watermark are ignored. Deleted students get a watermark no version can reach.
+type CachingStudentStore struct {
+	StudentStore
+
+	Cache    cache.Backend
+	TTL      time.Duration
+	Recorder cache.Recorder
+	ErrorLog *log.Logger
+}
+
+// deletedWatermark is recorded for deleted students so that no cached copy is accepted.
+const deletedWatermark = math.MaxInt32
+
+// WithStudentCache returns a copy of m whose Students store, and Transitions store if it
+// has one, share a cache in c. Transitions are wrapped because applying one bumps the
+// student's version and status outside of StudentStore. Cache errors go to m.ErrorLog,
+// or the standard logger if it is nil.
+//
+// Losing a watermark would let a stale copy back in, so a backend that evicts entries
+// should keep the keys matched by IsCacheWatermark; see cache.LRU.NeverEvict.
+func WithStudentCache(m Models, c cache.Backend, ttl time.Duration, recorder cache.Recorder) Models {
+	if recorder == nil {
+		recorder = cache.NopRecorder{}
+	}
+
+	errorLog := m.ErrorLog
+	if errorLog == nil {
+		errorLog = log.Default()
+	}
+
+	students := &CachingStudentStore{
+		StudentStore: m.Students,
+		Cache:        c,
+		TTL:          ttl,
+		Recorder:     recorder,
+		ErrorLog:     errorLog,
+	}
+
+	m.Students = students
+
+	if m.Transitions != nil {
+		m.Transitions = cachingTransitionStore{StudentTransitionStore: m.Transitions, students: students}
+	}
+
+	return m
+}
+
+func studentKey(id int64) string {
+	return fmt.Sprintf("student:%d", id)
+}
+
+func studentWatermarkKey(id int64) string {
+	return fmt.Sprintf("student:%d:version", id)
+}
+
+// IsCacheWatermark reports whether key holds a version watermark rather than a cached
+// student.
+func IsCacheWatermark(key string) bool {
+	return strings.HasPrefix(key, "student:") && strings.HasSuffix(key, ":version")
+}
+
+func (s *CachingStudentStore) Get(id int64) (*Student, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	watermark := s.watermark(ctx, id)
+
+	if cached, ok := s.cached(ctx, id); ok {
+		if cached.Version >= watermark {
+			s.Recorder.Hit()
+			return cached, nil
+		}
+
+		s.delete(ctx, studentKey(id))
+	}
+
+	s.Recorder.Miss()
+
+	student, err := s.StudentStore.Get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if student.Version >= watermark {
+		s.store(ctx, student)
+	}
+
+	return student, nil
+}
+
+func (s *CachingStudentStore) Insert(student *Student) error {
+	if err := s.StudentStore.Insert(student); err != nil {
+		return err
+	}
+
+	s.invalidate(student.ID, student.Version)
+	return nil
+}
+
+func (s *CachingStudentStore) InsertMany(students []*Student) error {
+	if err := s.StudentStore.InsertMany(students); err != nil {
+		return err
+	}
+
+	for _, student := range students {
+		s.invalidate(student.ID, student.Version)
+	}
+	return nil
+}
+
+func (s *CachingStudentStore) Update(student *Student) error {
+	if err := s.StudentStore.Update(student); err != nil {
+		return err
+	}
+
+	s.invalidate(student.ID, student.Version)
+	return nil
+}
+
+func (s *CachingStudentStore) Delete(id int64) error {
+	if err := s.StudentStore.Delete(id); err != nil {
+		return err
+	}
+
+	s.invalidate(id, deletedWatermark)
+	return nil
+}
+
+// invalidate raises the watermark to version and drops the cached entry. The write has
+// already been committed, so cache failures are logged rather than returned; the entry
+// then lives until its TTL runs out.
+func (s *CachingStudentStore) invalidate(id int64, version int32) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	err := s.Cache.Set(ctx, studentWatermarkKey(id), []byte(strconv.Itoa(int(version))), s.watermarkTTL())
+	if err != nil {
+		s.ErrorLog.Println(err)
+	}
+
+	s.delete(ctx, studentKey(id))
+}
+
+// watermarkTTL outlives any entry that could have been cached before the watermark was set.
+func (s *CachingStudentStore) watermarkTTL() time.Duration {
+	return 2 * s.TTL
+}
+
+func (s *CachingStudentStore) watermark(ctx context.Context, id int64) int32 {
+	value, ok, err := s.Cache.Get(ctx, studentWatermarkKey(id))
+	if err != nil {
+		s.ErrorLog.Println(err)
+		return 0
+	}
+	if !ok {
+		return 0
+	}
+
+	version, err := strconv.ParseInt(string(value), 10, 32)
+	if err != nil {
+		return 0
+	}
+
+	return int32(version)
+}
+
+func (s *CachingStudentStore) cached(ctx context.Context, id int64) (*Student, bool) {
+	value, ok, err := s.Cache.Get(ctx, studentKey(id))
+	if err != nil {
+		s.ErrorLog.Println(err)
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+
+	var student Student
+	if err := json.Unmarshal(value, &student); err != nil {
+		s.ErrorLog.Println(err)
+		return nil, false
+	}
+
+	return &student, true
+}
+
+func (s *CachingStudentStore) store(ctx context.Context, student *Student) {
+	value, err := json.Marshal(student)
+	if err != nil {
+		s.ErrorLog.Println(err)
+		return
+	}
+
+	if err := s.Cache.Set(ctx, studentKey(student.ID), value, s.TTL); err != nil {
+		s.ErrorLog.Println(err)
+	}
+}
+
+func (s *CachingStudentStore) delete(ctx context.Context, key string) {
+	if err := s.Cache.Delete(ctx, key); err != nil {
+		s.ErrorLog.Println(err)
+	}
+}
+
+type cachingTransitionStore struct {
+	StudentTransitionStore
+	students *CachingStudentStore
+}
+
+func (t cachingTransitionStore) Apply(student *Student, transition *StudentTransition) ([]*Enrollment, error) {
+	promoted, err := t.StudentTransitionStore.Apply(student, transition)
+	if err != nil {
+		return nil, err
+	}
+
+	t.students.invalidate(student.ID, student.Version)
+	return promoted, nil
+}
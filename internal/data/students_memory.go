@@ -0,0 +1,168 @@
+package data
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStudentModel is a StudentStore that keeps students in a map. It is safe for
+// concurrent use and behaves like StudentModel, which makes it useful for demos and tests
+// that should not need a database.
+type MemoryStudentModel struct {
+	mu       sync.RWMutex
+	nextID   int64
+	students map[int64]Student
+}
+
+func NewMemoryStudentModel() *MemoryStudentModel {
+	return &MemoryStudentModel{students: make(map[int64]Student)}
+}
+
+// now matches the second precision of the timestamp(0) columns in Postgres.
+func (m *MemoryStudentModel) now() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+func (m *MemoryStudentModel) Insert(student *Student) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.insert(student)
+	return nil
+}
+
+func (m *MemoryStudentModel) InsertMany(students []*Student) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, student := range students {
+		m.insert(student)
+	}
+	return nil
+}
+
+func (m *MemoryStudentModel) insert(student *Student) {
+	m.nextID++
+
+	student.ID = m.nextID
+	student.CreatedAt = m.now()
+	student.UpdatedAt = student.CreatedAt
+	student.Version = 1
+
+	m.students[student.ID] = cloneStudent(student)
+}
+
+// cloneStudent copies s so the stored record shares no memory with the caller's.
+func cloneStudent(s *Student) Student {
+	c := *s
+	if s.DateOfBirth != nil {
+		dob := *s.DateOfBirth
+		c.DateOfBirth = &dob
+	}
+	return c
+}
+
+func (m *MemoryStudentModel) Get(id int64) (*Student, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	student, ok := m.students[id]
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+
+	student = cloneStudent(&student)
+	return &student, nil
+}
+
+func (m *MemoryStudentModel) ListAll() ([]*Student, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.sorted(), nil
+}
+
+func (m *MemoryStudentModel) List(_ context.Context, limit, offset int) ([]*Student, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	students := m.sorted()
+
+	start := min(offset, len(students))
+	end := min(start+limit, len(students))
+
+	return students[start:end], nil
+}
+
+// ForEach iterates over a snapshot taken when it is called, so fn may call back into the
+// store without deadlocking.
+func (m *MemoryStudentModel) ForEach(ctx context.Context, fn func(*Student) error) error {
+	m.mu.RLock()
+	students := m.sorted()
+	m.mu.RUnlock()
+
+	for _, student := range students {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(student); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *MemoryStudentModel) sorted() []*Student {
+	students := make([]*Student, 0, len(m.students))
+	for _, s := range m.students {
+		c := cloneStudent(&s)
+		students = append(students, &c)
+	}
+
+	sort.Slice(students, func(i, j int) bool {
+		return students[i].ID < students[j].ID
+	})
+
+	return students
+}
+
+// Update mirrors StudentModel.Update: it checks the version, leaves status alone and
+// returns ErrEditConflict when the record is missing or has changed.
+func (m *MemoryStudentModel) Update(student *Student) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.students[student.ID]
+	if !ok || stored.Version != student.Version {
+		return ErrEditConflict
+	}
+
+	stored.Name = student.Name
+	stored.RollNo = student.RollNo
+	stored.Email = student.Email
+	stored.Phone = student.Phone
+	stored.DateOfBirth = student.DateOfBirth
+	stored.Address = student.Address
+	stored.UpdatedAt = m.now()
+	stored.Version++
+
+	m.students[stored.ID] = cloneStudent(&stored)
+	*student = stored
+
+	return nil
+}
+
+func (m *MemoryStudentModel) Delete(id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.students[id]; !ok {
+		return ErrRecordNotFound
+	}
+
+	delete(m.students, id)
+	return nil
+}
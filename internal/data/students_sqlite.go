@@ -0,0 +1,275 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteStudentSchema is the SQLite equivalent of the students table built up by the
+// Postgres migrations. SQLite is only used for demos and tests, so it is created in place
+// rather than versioned.
+const sqliteStudentSchema = `
+	CREATE TABLE IF NOT EXISTS students (
+		id integer PRIMARY KEY AUTOINCREMENT,
+		created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		name text NOT NULL,
+		rollno integer NOT NULL,
+		email text NOT NULL DEFAULT '',
+		phone text NOT NULL DEFAULT '',
+		date_of_birth date,
+		address text NOT NULL DEFAULT '',
+		status text NOT NULL DEFAULT 'applicant',
+		version integer NOT NULL DEFAULT 1
+	)`
+
+// OpenSQLite opens the SQLite database at dsn and creates the students table if needed.
+// The driver needs cgo, so this fails at runtime in binaries built with CGO_ENABLED=0.
+func OpenSQLite(dsn string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite serialises writers, and ":memory:" databases exist per connection, so a
+	// single connection avoids both "database is locked" errors and vanishing tables.
+	db.SetMaxOpenConns(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := db.ExecContext(ctx, sqliteStudentSchema); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// SQLiteStudentModel is a StudentStore backed by SQLite.
+type SQLiteStudentModel struct {
+	DB       *sql.DB
+	InfoLog  *log.Logger
+	ErrorLog *log.Logger
+}
+
+func NewSQLiteStudentModel(db *sql.DB) SQLiteStudentModel {
+	return SQLiteStudentModel{
+		DB:       db,
+		InfoLog:  log.New(os.Stdout, "INFO\t", log.Ldate|log.Ltime),
+		ErrorLog: log.New(os.Stderr, "Error\t", log.Ldate|log.Ltime|log.Lshortfile),
+	}
+}
+
+func (m SQLiteStudentModel) Insert(student *Student) error {
+	query := `
+	INSERT INTO students (name, rollno, email, phone, date_of_birth, address, status)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	RETURNING id, created_at, updated_at, version`
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	return m.DB.QueryRowContext(ctx, query, studentArgs(student)...).Scan(&student.ID, &student.CreatedAt, &student.UpdatedAt, &student.Version)
+}
+
+func (m SQLiteStudentModel) InsertMany(students []*Student) error {
+	query := `
+	INSERT INTO students (name, rollno, email, phone, date_of_birth, address, status)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	RETURNING id, created_at, updated_at, version`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, student := range students {
+		err := tx.QueryRowContext(ctx, query, studentArgs(student)...).Scan(&student.ID, &student.CreatedAt, &student.UpdatedAt, &student.Version)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (m SQLiteStudentModel) Get(id int64) (*Student, error) {
+	query := `
+	SELECT ` + studentColumns + `
+	FROM students
+	WHERE id = ?`
+
+	var student Student
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	err := scanStudent(m.DB.QueryRowContext(ctx, query, id), &student)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &student, nil
+}
+
+func (m SQLiteStudentModel) ListAll() ([]*Student, error) {
+	students := []*Student{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	err := m.ForEach(ctx, func(s *Student) error {
+		students = append(students, s)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return students, nil
+}
+
+func (m SQLiteStudentModel) List(ctx context.Context, limit, offset int) ([]*Student, error) {
+	query := `
+		SELECT ` + studentColumns + `
+		FROM students
+		ORDER BY id
+		LIMIT ? OFFSET ?`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			m.ErrorLog.Println(err)
+		}
+	}()
+
+	students := []*Student{}
+
+	for rows.Next() {
+		var s Student
+
+		if err := scanStudent(rows, &s); err != nil {
+			return nil, err
+		}
+		students = append(students, &s)
+	}
+
+	return students, rows.Err()
+}
+
+// ForEach streams students in id order from an open cursor. The database has a single
+// connection, which the cursor holds until ForEach returns, so fn must not use the store.
+func (m SQLiteStudentModel) ForEach(ctx context.Context, fn func(*Student) error) error {
+	query := `
+		SELECT ` + studentColumns + `
+		FROM students
+		ORDER BY id`
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			m.ErrorLog.Println(err)
+		}
+	}()
+
+	for rows.Next() {
+		var s Student
+
+		if err := scanStudent(rows, &s); err != nil {
+			return err
+		}
+
+		if err := fn(&s); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (m SQLiteStudentModel) Update(student *Student) error {
+	query := `
+		UPDATE students
+		SET name = ?, rollno = ?, email = ?, phone = ?, date_of_birth = ?, address = ?,
+			updated_at = CURRENT_TIMESTAMP, version = version + 1
+		WHERE id = ? AND version = ?
+		RETURNING version, created_at, updated_at, status`
+
+	args := []interface{}{
+		student.Name,
+		student.RollNo,
+		student.Email,
+		student.Phone,
+		student.DateOfBirth,
+		student.Address,
+		student.ID,
+		student.Version,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&student.Version, &student.CreatedAt, &student.UpdatedAt, &student.Status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrEditConflict
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (m SQLiteStudentModel) Delete(id int64) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `
+		DELETE FROM students
+		WHERE id = ?`
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package data_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/cache"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data/storetest"
+	"github.com/sai29/one2n_sre_bootcamp/internal/validator"
+)
+
+func TestMemoryStudentModel(t *testing.T) {
+	storetest.StudentStore(t, func(t *testing.T) data.StudentStore {
+		return data.NewMemoryStudentModel()
+	})
+}
+
+func TestSQLiteStudentModel(t *testing.T) {
+	storetest.StudentStore(t, func(t *testing.T) data.StudentStore {
+		db, err := data.OpenSQLite(filepath.Join(t.TempDir(), "students.db"))
+		if err != nil {
+			t.Skipf("sqlite unavailable: %v", err)
+		}
+		t.Cleanup(func() { _ = db.Close() })
+
+		return data.NewSQLiteStudentModel(db)
+	})
+}
+
+// TestStudentModel runs the suite against Postgres when TEST_DATABASE_URL is set.
+func TestStudentModel(t *testing.T) {
+	db := openTestDB(t)
+
+	storetest.StudentStore(t, func(t *testing.T) data.StudentStore {
+		truncate(t, db)
+		return data.NewModels(db).Students
+	})
+}
+
+func TestCachingStudentStore(t *testing.T) {
+	storetest.StudentStore(t, func(t *testing.T) data.StudentStore {
+		return data.WithStudentCache(data.Models{Students: data.NewMemoryStudentModel()}, cache.NewLRU(100, nil), time.Minute, nil).Students
+	})
+}
+
+func TestCachingStudentStore_RefusesStaleEntries(t *testing.T) {
+	backend := cache.NewLRU(100, nil)
+	store := data.WithStudentCache(data.Models{Students: data.NewMemoryStudentModel()}, backend, time.Minute, nil).Students
+
+	s := &data.Student{Name: "Old", RollNo: 1, Status: data.StudentApplicant}
+	if err := store.Insert(s); err != nil {
+		t.Fatal(err)
+	}
+
+	// Cache version 1, as a Get racing with the update below would.
+	stale, err := store.Get(s.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, _ := json.Marshal(stale)
+
+	s.Name = "New"
+	if err := store.Update(s); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	key := fmt.Sprintf("student:%d", s.ID)
+	_ = backend.Set(ctx, key, encoded, time.Minute)
+
+	got, err := store.Get(s.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "New" || got.Version != 2 {
+		t.Fatalf("expected the stale cached copy to be refused, got %+v", got)
+	}
+
+	if err := store.Delete(s.ID); err != nil {
+		t.Fatal(err)
+	}
+	_ = backend.Set(ctx, key, encoded, time.Minute)
+
+	if _, err := store.Get(s.ID); !errors.Is(err, data.ErrRecordNotFound) {
+		t.Fatalf("expected ErrRecordNotFound after delete, got %v", err)
+	}
+}
+
+func TestCachingStudentStore_WatermarksSurviveEviction(t *testing.T) {
+	backend := cache.NewLRU(1, nil)
+	backend.NeverEvict(data.IsCacheWatermark)
+	store := data.WithStudentCache(data.Models{Students: data.NewMemoryStudentModel()}, backend, time.Minute, nil).Students
+
+	s := &data.Student{Name: "Old", RollNo: 1, Status: data.StudentApplicant}
+	if err := store.Insert(s); err != nil {
+		t.Fatal(err)
+	}
+
+	stale, err := store.Get(s.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, _ := json.Marshal(stale)
+
+	s.Name = "New"
+	if err := store.Update(s); err != nil {
+		t.Fatal(err)
+	}
+
+	// Fill the cache past its capacity of one entry.
+	for i := int32(2); i <= 4; i++ {
+		other := &data.Student{Name: "Other", RollNo: i, Status: data.StudentApplicant}
+		if err := store.Insert(other); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.Get(other.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_ = backend.Set(context.Background(), fmt.Sprintf("student:%d", s.ID), encoded, time.Minute)
+
+	got, err := store.Get(s.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "New" {
+		t.Fatalf("expected the stale copy to be refused after evictions, got %+v", got)
+	}
+}
+
+func TestStudentInput(t *testing.T) {
+	name, rollNo, dob, status := "Ada", int32(7), "2001-02-03", ""
+
+	v := validator.New()
+	student := data.StudentInput{Name: &name, RollNo: &rollNo, DateOfBirth: &dob, Status: &status}.NewStudent(v)
+	if !v.Valid() {
+		t.Fatalf("unexpected errors %v", v.Errors)
+	}
+	if student.Status != data.StudentApplicant || student.DateOfBirth == nil || student.DateOfBirth.String() != dob {
+		t.Fatalf("unexpected student %+v", student)
+	}
+
+	// Only the given fields change; an empty date clears it and status is refused.
+	newName, noDate, enrolled := "Grace", "", data.StudentEnrolled
+
+	v = validator.New()
+	data.StudentInput{Name: &newName, DateOfBirth: &noDate, Status: &enrolled}.ApplyTo(v, student)
+	if _, ok := v.Errors["status"]; !ok || len(v.Errors) != 1 {
+		t.Fatalf("expected only a status error, got %v", v.Errors)
+	}
+	if student.Name != newName || student.RollNo != rollNo || student.DateOfBirth != nil {
+		t.Fatalf("unexpected student %+v", student)
+	}
+
+	badDate := "03/02/2001"
+
+	v = validator.New()
+	data.StudentInput{DateOfBirth: &badDate}.ApplyTo(v, student)
+	if _, ok := v.Errors["date_of_birth"]; !ok {
+		t.Fatalf("expected a date_of_birth error, got %v", v.Errors)
+	}
+}
@@ -0,0 +1,438 @@
+package data
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sai29/one2n_sre_bootcamp/internal/validator"
+)
+
+// Webhook delivery states. A pending delivery is retried until it succeeds or runs out of
+// attempts, when it is parked as dead until someone redelivers it.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryDead      = "dead"
+)
+
+// Webhook is an integrator's subscription to a set of event types. Secret is only shown
+// in the response that creates the webhook.
+type Webhook struct {
+	ID         int64     `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret,omitempty"`
+	Active     bool      `json:"active"`
+	Version    int32     `json:"version"`
+}
+
+func ValidateWebhook(v *validator.Validator, webhook *Webhook) {
+	v.Check(webhook.URL != "", "url", "must be provided")
+	v.Check(len(webhook.URL) <= 2000, "url", "must not be more than 2000 bytes long")
+
+	if u, err := url.Parse(webhook.URL); err == nil {
+		v.Check(u.Scheme == "http" || u.Scheme == "https", "url", "must be an http or https URL")
+		v.Check(u.Host != "", "url", "must be an absolute URL")
+	} else {
+		v.AddError("url", "must be a valid URL")
+	}
+
+	v.Check(len(webhook.EventTypes) > 0, "event_types", "must contain at least one event type")
+	v.Check(validator.Unique(webhook.EventTypes), "event_types", "must not contain duplicate values")
+
+	for _, t := range webhook.EventTypes {
+		v.Check(validator.PermittedValue(t, StudentEventTypes...), "event_types", "must only contain "+strings.Join(StudentEventTypes, ", "))
+	}
+}
+
+// NewWebhookSecret returns a random signing secret for a new webhook.
+func NewWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// WebhookDelivery is one event queued for one webhook, along with the outcome of its most
+// recent attempt. URL and Secret are filled in when a delivery is claimed for sending.
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	WebhookID      int64           `json:"webhook_id"`
+	EventID        int64           `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at"`
+	ResponseStatus *int32          `json:"response_status"`
+	LastError      *string         `json:"last_error"`
+
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+
+	// Claim identifies the claim the delivery was returned by. Every claim and every
+	// redelivery moves it on, so an attempt can only be recorded by its latest claim.
+	Claim int64 `json:"-"`
+}
+
+type WebhookModel struct {
+	DB       *sql.DB
+	InfoLog  *log.Logger
+	ErrorLog *log.Logger
+}
+
+func (m WebhookModel) Insert(webhook *Webhook) error {
+	query := `
+	INSERT INTO webhooks (url, event_types, secret, active)
+	VALUES ($1, $2, $3, $4)
+	RETURNING id, created_at, updated_at, version`
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	args := []interface{}{webhook.URL, pq.Array(webhook.EventTypes), webhook.Secret, webhook.Active}
+
+	return m.DB.QueryRowContext(ctx, query, args...).Scan(&webhook.ID, &webhook.CreatedAt, &webhook.UpdatedAt, &webhook.Version)
+}
+
+// webhookColumns is the select list understood by scanWebhook. The secret is left out;
+// only the delivery worker reads it.
+const webhookColumns = `id, created_at, updated_at, url, event_types, active, version`
+
+func scanWebhook(row rowScanner, w *Webhook) error {
+	return row.Scan(
+		&w.ID,
+		&w.CreatedAt,
+		&w.UpdatedAt,
+		&w.URL,
+		pq.Array(&w.EventTypes),
+		&w.Active,
+		&w.Version,
+	)
+}
+
+func (m WebhookModel) Get(id int64) (*Webhook, error) {
+	if id < 1 {
+		return nil, ErrRecordNotFound
+	}
+
+	query := `
+	SELECT ` + webhookColumns + `
+	FROM webhooks
+	WHERE id = $1`
+
+	var webhook Webhook
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	err := scanWebhook(m.DB.QueryRowContext(ctx, query, id), &webhook)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+func (m WebhookModel) ListAll() ([]*Webhook, error) {
+	query := `
+		SELECT ` + webhookColumns + `
+		FROM webhooks
+		ORDER BY id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			m.ErrorLog.Println(err)
+		}
+	}()
+
+	webhooks := []*Webhook{}
+
+	for rows.Next() {
+		var w Webhook
+
+		if err := scanWebhook(rows, &w); err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, &w)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// Update saves the URL, event types and active flag using optimistic locking on version.
+// The secret never changes.
+func (m WebhookModel) Update(webhook *Webhook) error {
+	query := `
+		UPDATE webhooks
+		SET url = $1, event_types = $2, active = $3, updated_at = NOW(), version = version + 1
+		WHERE id = $4 AND version = $5
+		RETURNING version, updated_at`
+
+	args := []interface{}{
+		webhook.URL,
+		pq.Array(webhook.EventTypes),
+		webhook.Active,
+		webhook.ID,
+		webhook.Version,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&webhook.Version, &webhook.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrEditConflict
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Delete removes the webhook along with its delivery log.
+func (m WebhookModel) Delete(id int64) error {
+	if id < 1 {
+		return ErrRecordNotFound
+	}
+
+	query := `
+		DELETE FROM webhooks
+		WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Enqueue queues the event for every active webhook subscribed to its type and returns
+// how many deliveries were created. Queuing the same event twice is a no-op, so the
+// outbox relay can safely retry a batch.
+func (m WebhookModel) Enqueue(ctx context.Context, eventID int64, eventType string, payload []byte) (int, error) {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload)
+		SELECT id, $1, $2, $3
+		FROM webhooks
+		WHERE active AND $2 = ANY(event_types)
+		ON CONFLICT (webhook_id, event_id) DO NOTHING`
+
+	result, err := m.DB.ExecContext(ctx, query, eventID, eventType, payload)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+// deliveryColumns is the select list understood by scanDelivery, qualified so it can be
+// used in joins.
+const deliveryColumns = `d.id, d.created_at, d.updated_at, d.webhook_id, d.event_id, d.event_type, d.payload,
+	d.status, d.attempts, d.next_attempt_at, d.last_attempt_at, d.response_status, d.last_error`
+
+func scanDelivery(row rowScanner, d *WebhookDelivery, extra ...any) error {
+	dest := []any{
+		&d.ID,
+		&d.CreatedAt,
+		&d.UpdatedAt,
+		&d.WebhookID,
+		&d.EventID,
+		&d.EventType,
+		&d.Payload,
+		&d.Status,
+		&d.Attempts,
+		&d.NextAttemptAt,
+		&d.LastAttemptAt,
+		&d.ResponseStatus,
+		&d.LastError,
+	}
+
+	return row.Scan(append(dest, extra...)...)
+}
+
+// ClaimDeliveries returns up to limit pending deliveries that are due, with the URL and
+// secret of their webhook. Each claimed delivery has its next attempt pushed back by lease,
+// so other instances skip it while it is being sent; if the sender stops before recording
+// the outcome, the delivery becomes due again once the lease runs out.
+func (m WebhookModel) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*WebhookDelivery, error) {
+	query := `
+		WITH due AS (
+			SELECT id
+			FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at, id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = NOW() + $2::float8 * interval '1 second', claim = d.claim + 1
+		FROM due, webhooks w
+		WHERE d.id = due.id AND w.id = d.webhook_id
+		RETURNING ` + deliveryColumns + `, d.claim, w.url, w.secret`
+
+	rows, err := m.DB.QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			m.ErrorLog.Println(err)
+		}
+	}()
+
+	deliveries := []*WebhookDelivery{}
+
+	for rows.Next() {
+		var d WebhookDelivery
+
+		if err := scanDelivery(rows, &d, &d.Claim, &d.URL, &d.Secret); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, &d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// RecordAttempt saves the outcome of an attempt: the status, attempt count, next attempt
+// time, response status and error set on the delivery by the sender. The row must still
+// be at the delivery's Claim. If it has been claimed again since, because the lease ran
+// out, or redelivered, ErrEditConflict is returned and nothing is written.
+func (m WebhookModel) RecordAttempt(ctx context.Context, delivery *WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, next_attempt_at = $3, last_attempt_at = $4,
+			response_status = $5, last_error = $6, updated_at = NOW()
+		WHERE id = $7 AND claim = $8
+		RETURNING updated_at`
+
+	args := []interface{}{
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastAttemptAt,
+		delivery.ResponseStatus,
+		delivery.LastError,
+		delivery.ID,
+		delivery.Claim,
+	}
+
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&delivery.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrEditConflict
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ListDeliveries returns the webhook's most recent deliveries, newest first.
+func (m WebhookModel) ListDeliveries(webhookID int64, limit int) ([]*WebhookDelivery, error) {
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries d
+		WHERE d.webhook_id = $1
+		ORDER BY d.id DESC
+		LIMIT $2`
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, webhookID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			m.ErrorLog.Println(err)
+		}
+	}()
+
+	deliveries := []*WebhookDelivery{}
+
+	for rows.Next() {
+		var d WebhookDelivery
+
+		if err := scanDelivery(rows, &d); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, &d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// Redeliver puts a delivery of the given webhook back in the queue with a fresh set of
+// attempts, whatever its current state. An attempt in flight can no longer be recorded.
+// The outcome of the previous attempt is kept until the next one replaces it.
+func (m WebhookModel) Redeliver(webhookID, id int64) (*WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries d
+		SET status = 'pending', attempts = 0, next_attempt_at = NOW(), updated_at = NOW(),
+			claim = d.claim + 1
+		WHERE d.id = $1 AND d.webhook_id = $2
+		RETURNING ` + deliveryColumns
+
+	var d WebhookDelivery
+
+	ctx, cancel := context.WithTimeout(context.Background(), QueryTimeout)
+	defer cancel()
+
+	err := scanDelivery(m.DB.QueryRowContext(ctx, query, id, webhookID), &d)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &d, nil
+}
@@ -0,0 +1,239 @@
+// Package gpa computes grade point averages from letter grades and course credits.
+// Registrars audit these numbers, so the rules are kept deliberately small and explicit:
+//
+//   - A grade that maps to points on the Scale counts towards GPA with the course's credits.
+//   - A grade listed as excluded (for example W or P) is accepted but ignored by GPA.
+//   - An entry with no grade is in progress and is ignored entirely.
+//   - GPAs are quality points divided by counted credits, rounded half-up to two decimals.
+package gpa
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Scale maps letter grades to grade points.
+type Scale struct {
+	Points   map[string]float64
+	Excluded map[string]bool
+}
+
+// DefaultScale returns the common US 4.0 scale, with W (withdrawn), I (incomplete) and
+// P (pass) accepted but excluded from GPA.
+func DefaultScale() Scale {
+	return Scale{
+		Points: map[string]float64{
+			"A+": 4.0, "A": 4.0, "A-": 3.7,
+			"B+": 3.3, "B": 3.0, "B-": 2.7,
+			"C+": 2.3, "C": 2.0, "C-": 1.7,
+			"D+": 1.3, "D": 1.0, "D-": 0.7,
+			"F": 0.0,
+		},
+		Excluded: map[string]bool{"W": true, "I": true, "P": true},
+	}
+}
+
+// ParseScale parses a comma separated list of grade=points pairs, such as
+// "A=4,A-=3.7,B=3,F=0,W=-". A points value of "-" marks the grade as excluded.
+func ParseScale(s string) (Scale, error) {
+	scale := Scale{
+		Points:   make(map[string]float64),
+		Excluded: make(map[string]bool),
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		grade, value, ok := strings.Cut(pair, "=")
+		grade = strings.ToUpper(strings.TrimSpace(grade))
+		value = strings.TrimSpace(value)
+
+		if !ok || grade == "" {
+			return Scale{}, fmt.Errorf("invalid grading scale entry %q", pair)
+		}
+
+		if _, dup := scale.Points[grade]; dup || scale.Excluded[grade] {
+			return Scale{}, fmt.Errorf("grade %q is defined more than once", grade)
+		}
+
+		if value == "-" {
+			scale.Excluded[grade] = true
+			continue
+		}
+
+		points, err := strconv.ParseFloat(value, 64)
+		if err != nil || points < 0 {
+			return Scale{}, fmt.Errorf("invalid points %q for grade %q", value, grade)
+		}
+
+		scale.Points[grade] = points
+	}
+
+	if len(scale.Points) == 0 {
+		return Scale{}, errors.New("grading scale must define at least one graded value")
+	}
+
+	return scale, nil
+}
+
+// Valid reports whether grade is part of the scale, either graded or excluded.
+func (s Scale) Valid(grade string) bool {
+	_, ok := s.Points[grade]
+	return ok || s.Excluded[grade]
+}
+
+// Grades returns every grade on the scale, highest points first and excluded grades last.
+func (s Scale) Grades() []string {
+	grades := make([]string, 0, len(s.Points)+len(s.Excluded))
+	for g := range s.Points {
+		grades = append(grades, g)
+	}
+
+	sort.Slice(grades, func(i, j int) bool {
+		if s.Points[grades[i]] != s.Points[grades[j]] {
+			return s.Points[grades[i]] > s.Points[grades[j]]
+		}
+		return grades[i] < grades[j]
+	})
+
+	excluded := make([]string, 0, len(s.Excluded))
+	for g := range s.Excluded {
+		excluded = append(excluded, g)
+	}
+	sort.Strings(excluded)
+
+	return append(grades, excluded...)
+}
+
+// String formats the scale in the form accepted by ParseScale.
+func (s Scale) String() string {
+	parts := make([]string, 0, len(s.Points)+len(s.Excluded))
+	for _, g := range s.Grades() {
+		if s.Excluded[g] {
+			parts = append(parts, g+"=-")
+			continue
+		}
+		parts = append(parts, g+"="+strconv.FormatFloat(s.Points[g], 'f', -1, 64))
+	}
+	return strings.Join(parts, ",")
+}
+
+// MarshalText implements encoding.TextMarshaler using the String form.
+func (s Scale) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler using ParseScale.
+func (s *Scale) UnmarshalText(text []byte) error {
+	scale, err := ParseScale(string(text))
+	if err != nil {
+		return err
+	}
+	*s = scale
+	return nil
+}
+
+// Entry is one graded (or not yet graded) course on a transcript.
+type Entry struct {
+	Term    string
+	Credits int32
+	Grade   string
+}
+
+// TermSummary holds the totals for a single term and the cumulative GPA at its end.
+type TermSummary struct {
+	Term             string  `json:"term"`
+	CreditsAttempted int32   `json:"credits_attempted"`
+	CreditsEarned    int32   `json:"credits_earned"`
+	QualityPoints    float64 `json:"quality_points"`
+	GPA              float64 `json:"gpa"`
+	CumulativeGPA    float64 `json:"cumulative_gpa"`
+}
+
+// Result is the outcome of Compute.
+type Result struct {
+	Terms            []TermSummary `json:"terms"`
+	CreditsAttempted int32         `json:"credits_attempted"`
+	CreditsEarned    int32         `json:"credits_earned"`
+	QualityPoints    float64       `json:"quality_points"`
+	CumulativeGPA    float64       `json:"cumulative_gpa"`
+}
+
+// Compute summarises entries by term, in the order each term first appears. Attempted
+// credits are those with a graded (non-excluded) value; earned credits are the subset
+// with more than zero points.
+func (s Scale) Compute(entries []Entry) (Result, error) {
+	var result Result
+
+	index := make(map[string]int)
+
+	for _, e := range entries {
+		i, ok := index[e.Term]
+		if !ok {
+			i = len(result.Terms)
+			index[e.Term] = i
+			result.Terms = append(result.Terms, TermSummary{Term: e.Term})
+		}
+
+		if e.Grade == "" || s.Excluded[e.Grade] {
+			continue
+		}
+
+		points, ok := s.Points[e.Grade]
+		if !ok {
+			return Result{}, fmt.Errorf("grade %q is not on the grading scale", e.Grade)
+		}
+
+		if e.Credits < 0 {
+			return Result{}, fmt.Errorf("credits must not be negative, got %d", e.Credits)
+		}
+
+		t := &result.Terms[i]
+		t.CreditsAttempted += e.Credits
+		t.QualityPoints += points * float64(e.Credits)
+		if points > 0 {
+			t.CreditsEarned += e.Credits
+		}
+	}
+
+	for i := range result.Terms {
+		t := &result.Terms[i]
+
+		result.CreditsAttempted += t.CreditsAttempted
+		result.CreditsEarned += t.CreditsEarned
+		result.QualityPoints += t.QualityPoints
+
+		t.GPA = average(t.QualityPoints, t.CreditsAttempted)
+		t.QualityPoints = round(t.QualityPoints)
+		t.CumulativeGPA = average(result.QualityPoints, result.CreditsAttempted)
+	}
+
+	result.CumulativeGPA = average(result.QualityPoints, result.CreditsAttempted)
+	result.QualityPoints = round(result.QualityPoints)
+
+	if result.Terms == nil {
+		result.Terms = []TermSummary{}
+	}
+
+	return result, nil
+}
+
+func average(points float64, credits int32) float64 {
+	if credits == 0 {
+		return 0
+	}
+	return round(points / float64(credits))
+}
+
+// round rounds half-up to two decimal places. The small epsilon absorbs binary floating
+// point error so that, for example, 3.345 rounds to 3.35 rather than 3.34.
+func round(x float64) float64 {
+	return math.Floor(x*100+0.5+1e-9) / 100
+}
@@ -0,0 +1,197 @@
+package gpa
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompute(t *testing.T) {
+	scale := DefaultScale()
+
+	tests := []struct {
+		name    string
+		entries []Entry
+		want    Result
+	}{
+		{
+			name:    "no entries",
+			entries: nil,
+			want:    Result{Terms: []TermSummary{}},
+		},
+		{
+			name: "single term straight A",
+			entries: []Entry{
+				{Term: "2024-fall", Credits: 4, Grade: "A"},
+				{Term: "2024-fall", Credits: 3, Grade: "A+"},
+			},
+			want: Result{
+				Terms: []TermSummary{
+					{Term: "2024-fall", CreditsAttempted: 7, CreditsEarned: 7, QualityPoints: 28, GPA: 4, CumulativeGPA: 4},
+				},
+				CreditsAttempted: 7, CreditsEarned: 7, QualityPoints: 28, CumulativeGPA: 4,
+			},
+		},
+		{
+			name: "credit weighted average",
+			entries: []Entry{
+				{Term: "2024-fall", Credits: 4, Grade: "A"},
+				{Term: "2024-fall", Credits: 1, Grade: "C"},
+			},
+			want: Result{
+				Terms: []TermSummary{
+					{Term: "2024-fall", CreditsAttempted: 5, CreditsEarned: 5, QualityPoints: 18, GPA: 3.6, CumulativeGPA: 3.6},
+				},
+				CreditsAttempted: 5, CreditsEarned: 5, QualityPoints: 18, CumulativeGPA: 3.6,
+			},
+		},
+		{
+			name: "failing grade counts as attempted but not earned",
+			entries: []Entry{
+				{Term: "2024-fall", Credits: 3, Grade: "B"},
+				{Term: "2024-fall", Credits: 3, Grade: "F"},
+			},
+			want: Result{
+				Terms: []TermSummary{
+					{Term: "2024-fall", CreditsAttempted: 6, CreditsEarned: 3, QualityPoints: 9, GPA: 1.5, CumulativeGPA: 1.5},
+				},
+				CreditsAttempted: 6, CreditsEarned: 3, QualityPoints: 9, CumulativeGPA: 1.5,
+			},
+		},
+		{
+			name: "excluded and ungraded entries are ignored",
+			entries: []Entry{
+				{Term: "2024-fall", Credits: 3, Grade: "B+"},
+				{Term: "2024-fall", Credits: 4, Grade: "W"},
+				{Term: "2024-fall", Credits: 2, Grade: "P"},
+				{Term: "2024-fall", Credits: 3, Grade: ""},
+			},
+			want: Result{
+				Terms: []TermSummary{
+					{Term: "2024-fall", CreditsAttempted: 3, CreditsEarned: 3, QualityPoints: 9.9, GPA: 3.3, CumulativeGPA: 3.3},
+				},
+				CreditsAttempted: 3, CreditsEarned: 3, QualityPoints: 9.9, CumulativeGPA: 3.3,
+			},
+		},
+		{
+			name: "cumulative across terms in order of appearance",
+			entries: []Entry{
+				{Term: "2024-fall", Credits: 3, Grade: "A"},
+				{Term: "2024-fall", Credits: 3, Grade: "B"},
+				{Term: "2025-spring", Credits: 4, Grade: "C"},
+			},
+			want: Result{
+				Terms: []TermSummary{
+					{Term: "2024-fall", CreditsAttempted: 6, CreditsEarned: 6, QualityPoints: 21, GPA: 3.5, CumulativeGPA: 3.5},
+					{Term: "2025-spring", CreditsAttempted: 4, CreditsEarned: 4, QualityPoints: 8, GPA: 2, CumulativeGPA: 2.9},
+				},
+				CreditsAttempted: 10, CreditsEarned: 10, QualityPoints: 29, CumulativeGPA: 2.9,
+			},
+		},
+		{
+			name: "term with only in-progress courses",
+			entries: []Entry{
+				{Term: "2024-fall", Credits: 3, Grade: "A-"},
+				{Term: "2025-spring", Credits: 3, Grade: ""},
+			},
+			want: Result{
+				Terms: []TermSummary{
+					{Term: "2024-fall", CreditsAttempted: 3, CreditsEarned: 3, QualityPoints: 11.1, GPA: 3.7, CumulativeGPA: 3.7},
+					{Term: "2025-spring", CumulativeGPA: 3.7},
+				},
+				CreditsAttempted: 3, CreditsEarned: 3, QualityPoints: 11.1, CumulativeGPA: 3.7,
+			},
+		},
+		{
+			name: "rounds half up to two decimals",
+			entries: []Entry{
+				{Term: "2024-fall", Credits: 1, Grade: "A-"},
+				{Term: "2024-fall", Credits: 1, Grade: "B-"},
+				{Term: "2024-fall", Credits: 1, Grade: "B-"},
+			},
+			// (3.7 + 2.7 + 2.7) / 3 = 3.0333...
+			want: Result{
+				Terms: []TermSummary{
+					{Term: "2024-fall", CreditsAttempted: 3, CreditsEarned: 3, QualityPoints: 9.1, GPA: 3.03, CumulativeGPA: 3.03},
+				},
+				CreditsAttempted: 3, CreditsEarned: 3, QualityPoints: 9.1, CumulativeGPA: 3.03,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := scale.Compute(tt.entries)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeRejectsUnknownGrade(t *testing.T) {
+	_, err := DefaultScale().Compute([]Entry{{Term: "2024-fall", Credits: 3, Grade: "Z"}})
+	if err == nil {
+		t.Fatal("expected an error for a grade that is not on the scale")
+	}
+}
+
+func TestParseScale(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Scale
+		wantErr bool
+	}{
+		{
+			name:  "points and excluded grades",
+			input: "A=4, b=3.0 ,F=0,W=-",
+			want: Scale{
+				Points:   map[string]float64{"A": 4, "B": 3, "F": 0},
+				Excluded: map[string]bool{"W": true},
+			},
+		},
+		{name: "missing points", input: "A=4,B", wantErr: true},
+		{name: "negative points", input: "A=-1", wantErr: true},
+		{name: "not a number", input: "A=four", wantErr: true},
+		{name: "duplicate grade", input: "A=4,A=3.9", wantErr: true},
+		{name: "only excluded grades", input: "W=-", wantErr: true},
+		{name: "empty", input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseScale(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScaleStringRoundTrips(t *testing.T) {
+	scale := DefaultScale()
+
+	parsed, err := ParseScale(scale.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(parsed, scale) {
+		t.Fatalf("got %+v, want %+v", parsed, scale)
+	}
+}
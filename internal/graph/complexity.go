@@ -0,0 +1,456 @@
+package graph
+
+import (
+	"strconv"
+	"strings"
+	"text/scanner"
+)
+
+// defaultListSize is the assumed length of list fields that take no limit argument.
+const defaultListSize = 20
+
+// The complexity check runs before graphql-go sees the query, and graphql-go keeps its
+// query parser internal, so the operation is read here with a scanner that only keeps
+// what costing needs: operations, fragments, fields, their integer arguments and
+// variable defaults. It assumes nothing about validity; graphql-go still validates the
+// query and enforces MaxDepth.
+
+// document is a query reduced to what costing needs.
+type document struct {
+	operations []*operation
+	fragments  map[string][]*selection
+}
+
+type operation struct {
+	name     string
+	mutation bool
+	defaults map[string]string
+	set      []*selection
+}
+
+// selection is a field, an inline fragment (name empty) or a fragment spread.
+type selection struct {
+	name   string
+	spread string
+	args   map[string]argument
+	set    []*selection
+}
+
+// argument holds an integer literal or the name of a variable; anything else is dropped.
+type argument struct {
+	raw      string
+	variable bool
+}
+
+// operation returns the operation graphql-go would run for name, or nil when it would
+// report an error instead.
+func (d *document) operation(name string) *operation {
+	if name == "" {
+		if len(d.operations) != 1 {
+			return nil
+		}
+		return d.operations[0]
+	}
+
+	for _, op := range d.operations {
+		if op.name == name {
+			return op
+		}
+	}
+	return nil
+}
+
+// parseDocument reads the query. ok is false when it isn't a well-formed document.
+func parseDocument(query string) (doc *document, ok bool) {
+	p := &queryParser{}
+	p.s.Init(strings.NewReader(query))
+	p.s.Mode = scanner.ScanIdents | scanner.ScanInts | scanner.ScanFloats | scanner.ScanStrings
+	p.s.IsIdentRune = func(ch rune, i int) bool {
+		return ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || i > 0 && ch >= '0' && ch <= '9'
+	}
+	p.s.Error = func(*scanner.Scanner, string) { p.failed = true }
+	p.next()
+
+	doc = &document{fragments: map[string][]*selection{}}
+
+	for p.tok != scanner.EOF && !p.failed {
+		switch {
+		case p.tok == '{':
+			doc.operations = append(doc.operations, &operation{set: p.selectionSet()})
+
+		case p.text == "query" || p.text == "mutation" || p.text == "subscription":
+			doc.operations = append(doc.operations, p.operation())
+
+		case p.text == "fragment":
+			p.next()
+			name := p.name()
+			if p.text != "on" {
+				p.failed = true
+				break
+			}
+			p.next()
+			p.name()
+			p.directives()
+			doc.fragments[name] = p.selectionSet()
+
+		default:
+			p.failed = true
+		}
+	}
+
+	return doc, !p.failed
+}
+
+type queryParser struct {
+	s      scanner.Scanner
+	tok    rune
+	text   string
+	failed bool
+}
+
+// next moves to the next token, skipping commas and comments, and reading "..." and
+// block strings as single tokens.
+func (p *queryParser) next() {
+	for {
+		p.tok = p.s.Scan()
+		p.text = p.s.TokenText()
+
+		switch p.tok {
+		case ',':
+			continue
+
+		case '#':
+			for ch := p.s.Peek(); ch != '\n' && ch != '\r' && ch != scanner.EOF; ch = p.s.Peek() {
+				p.s.Next()
+			}
+			continue
+
+		case '.':
+			if p.s.Next() != '.' || p.s.Next() != '.' {
+				p.failed = true
+			}
+			p.text = "..."
+
+		case scanner.String:
+			// "" followed by " opens a block string, which ends at the next unescaped """.
+			if p.text == `""` && p.s.Peek() == '"' {
+				p.s.Next()
+				p.blockString()
+			}
+		}
+
+		if p.failed {
+			p.tok = scanner.EOF
+		}
+		return
+	}
+}
+
+func (p *queryParser) blockString() {
+	quotes := 0
+
+	for {
+		switch ch := p.s.Next(); ch {
+		case scanner.EOF:
+			p.failed = true
+			return
+		case '\\':
+			p.s.Next()
+			quotes = 0
+		case '"':
+			if quotes++; quotes == 3 {
+				return
+			}
+		default:
+			quotes = 0
+		}
+	}
+}
+
+func (p *queryParser) expect(tok rune) {
+	if p.tok != tok {
+		p.failed = true
+		return
+	}
+	p.next()
+}
+
+func (p *queryParser) name() string {
+	if p.tok != scanner.Ident {
+		p.failed = true
+		return ""
+	}
+
+	name := p.text
+	p.next()
+	return name
+}
+
+func (p *queryParser) operation() *operation {
+	op := &operation{mutation: p.text == "mutation", defaults: map[string]string{}}
+	p.next()
+
+	if p.tok == scanner.Ident {
+		op.name = p.name()
+	}
+
+	if p.tok == '(' {
+		p.next()
+		for p.tok != ')' && !p.failed {
+			p.expect('$')
+			variable := p.name()
+			p.expect(':')
+			p.typeRef()
+
+			if p.tok == '=' {
+				p.next()
+				if v := p.value(); !v.variable {
+					op.defaults[variable] = v.raw
+				}
+			}
+			p.directives()
+		}
+		p.expect(')')
+	}
+
+	p.directives()
+	op.set = p.selectionSet()
+	return op
+}
+
+func (p *queryParser) typeRef() {
+	if p.tok == '[' {
+		p.next()
+		p.typeRef()
+		p.expect(']')
+	} else {
+		p.name()
+	}
+
+	if p.tok == '!' {
+		p.next()
+	}
+}
+
+func (p *queryParser) selectionSet() []*selection {
+	p.expect('{')
+
+	var set []*selection
+
+	for p.tok != '}' && !p.failed {
+		if p.text == "..." {
+			p.next()
+
+			if p.tok == scanner.Ident && p.text != "on" {
+				set = append(set, &selection{spread: p.name()})
+				p.directives()
+				continue
+			}
+
+			if p.text == "on" {
+				p.next()
+				p.name()
+			}
+			p.directives()
+			set = append(set, &selection{set: p.selectionSet()})
+			continue
+		}
+
+		field := &selection{name: p.name()}
+		if p.tok == ':' {
+			p.next()
+			field.name = p.name()
+		}
+
+		if p.tok == '(' {
+			field.args = p.arguments()
+		}
+		p.directives()
+
+		if p.tok == '{' {
+			field.set = p.selectionSet()
+		}
+		set = append(set, field)
+	}
+
+	p.expect('}')
+	return set
+}
+
+func (p *queryParser) arguments() map[string]argument {
+	args := map[string]argument{}
+	p.expect('(')
+
+	for p.tok != ')' && !p.failed {
+		name := p.name()
+		p.expect(':')
+		args[name] = p.value()
+	}
+
+	p.expect(')')
+	return args
+}
+
+func (p *queryParser) directives() {
+	for p.tok == '@' && !p.failed {
+		p.next()
+		p.name()
+		if p.tok == '(' {
+			p.arguments()
+		}
+	}
+}
+
+// value reads any input value, keeping it only if it is an integer or a variable.
+func (p *queryParser) value() argument {
+	switch p.tok {
+	case '$':
+		p.next()
+		return argument{raw: p.name(), variable: true}
+
+	case '-':
+		p.next()
+		if p.tok != scanner.Int {
+			p.value()
+			return argument{}
+		}
+		v := argument{raw: "-" + p.text}
+		p.next()
+		return v
+
+	case scanner.Int:
+		v := argument{raw: p.text}
+		p.next()
+		return v
+
+	case '[':
+		p.next()
+		for p.tok != ']' && !p.failed {
+			p.value()
+		}
+		p.expect(']')
+
+	case '{':
+		p.next()
+		for p.tok != '}' && !p.failed {
+			p.name()
+			p.expect(':')
+			p.value()
+		}
+		p.expect('}')
+
+	case scanner.Float, scanner.String, scanner.Ident:
+		p.next()
+
+	default:
+		p.failed = true
+	}
+
+	return argument{}
+}
+
+// complexity estimates the cost of an operation before it runs. Each field costs one, and
+// the cost of a list field's selection is multiplied by the number of elements it may
+// return: its limit argument for students, defaultListSize for the other lists. The result
+// is capped at ceiling+1, which is enough to tell that a query is over the limit.
+func complexity(doc *document, op *operation, vars map[string]interface{}, ceiling int) int {
+	c := &costing{doc: doc, op: op, vars: vars, ceiling: ceiling, active: map[string]bool{}}
+	return c.selectionSet(op.set)
+}
+
+type costing struct {
+	doc     *document
+	op      *operation
+	vars    map[string]interface{}
+	ceiling int
+
+	// active holds the fragments being expanded, so a cyclic spread (which validation
+	// rejects later) can't recurse forever.
+	active map[string]bool
+}
+
+func (c *costing) selectionSet(set []*selection) int {
+	total := 0
+
+	for _, sel := range set {
+		switch {
+		case sel.spread != "":
+			frag, ok := c.doc.fragments[sel.spread]
+			if !ok || c.active[sel.spread] {
+				continue
+			}
+
+			c.active[sel.spread] = true
+			total += c.selectionSet(frag)
+			delete(c.active, sel.spread)
+
+		case sel.name == "":
+			total += c.selectionSet(sel.set)
+
+		default:
+			cost := 1
+			if len(sel.set) > 0 {
+				cost += c.listSize(sel) * c.selectionSet(sel.set)
+			}
+			total += cost
+		}
+
+		if total > c.ceiling {
+			return c.ceiling + 1
+		}
+	}
+
+	return total
+}
+
+func (c *costing) listSize(field *selection) int {
+	switch field.name {
+	case "students":
+		if n, ok := c.intArgument(field, "limit"); ok {
+			return min(max(n, 1), c.ceiling+1)
+		}
+		return 100
+
+	case "courses", "guardians":
+		return defaultListSize
+	}
+
+	return 1
+}
+
+func (c *costing) intArgument(field *selection, name string) (int, bool) {
+	arg, ok := field.args[name]
+	if !ok {
+		return 0, false
+	}
+
+	raw := arg.raw
+	if arg.variable {
+		if v, ok := c.vars[arg.raw]; ok {
+			return toInt(v)
+		}
+
+		if raw, ok = c.op.defaults[arg.raw]; !ok {
+			return 0, false
+		}
+	}
+
+	n, err := strconv.Atoi(raw)
+	return n, err == nil
+}
+
+// toInt converts a JSON-decoded variable to an int.
+func toInt(v interface{}) (int, bool) {
+	switch v := v.(type) {
+	case float64:
+		return int(v), true
+	case int:
+		return v, true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), true
+	}
+
+	return 0, false
+}
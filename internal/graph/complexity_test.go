@@ -0,0 +1,62 @@
+package graph
+
+import (
+	"testing"
+)
+
+func TestComplexity(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		vars  map[string]interface{}
+		want  int
+	}{
+		{"scalar fields", `{ student(id: "1") { id name } }`, nil, 3},
+		{"list with limit", `{ students(limit: 10) { id name } }`, nil, 21},
+		{"list without limit", `{ students { id } }`, nil, 101},
+		{"limit from variable", `query($n: Int) { students(limit: $n) { id } }`, map[string]interface{}{"n": float64(3)}, 4},
+		{"variable default", `query($n: Int = 4) { students(limit: $n) { id } }`, nil, 5},
+		{"nested lists", `{ students(limit: 2) { guardians { id } } }`, nil, 1 + 2*(1+defaultListSize)},
+		{"fragments", `{ students(limit: 2) { ...f } } fragment f on Student { id name }`, nil, 5},
+		{"cyclic fragment", `{ students(limit: 1) { ...f } } fragment f on Student { id ...f }`, nil, 2},
+		{"capped", `{ students(limit: 1000000) { id guardians { id } } }`, nil, 1001},
+		{"aliases, directives and other arguments", `query Q($s: String = "a, \"b\"") {
+			first: students(limit: 2, filter: {name: $s, tags: ["x" "y"]}) @include(if: true) { id }
+			... on Query { courses { id } } # students(limit: 500)
+		}`, nil, 3 + 1 + defaultListSize},
+		{"block string", `{ students(limit: 2, note: """ { students(limit: 900) } """) { id } }`, nil, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, ok := parseDocument(tt.query)
+			if !ok {
+				t.Fatal("query did not parse")
+			}
+
+			if got := complexity(doc, doc.operations[0], tt.vars, 1000); got != tt.want {
+				t.Fatalf("complexity = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDocument(t *testing.T) {
+	doc, ok := parseDocument(`query A { students { id } } mutation B($id: ID!) { deleteStudent(id: $id) }`)
+	if !ok {
+		t.Fatal("query did not parse")
+	}
+
+	if op := doc.operation(""); op != nil {
+		t.Fatal("expected no default operation when there are two")
+	}
+	if op := doc.operation("B"); op == nil || !op.mutation {
+		t.Fatalf("expected B to be a mutation, got %+v", op)
+	}
+
+	for _, query := range []string{`{ students { id }`, `{ students(limit: ) { id } }`, "{ `x` }", `fragment f Student { id }`} {
+		if _, ok := parseDocument(query); ok {
+			t.Errorf("expected %q not to parse", query)
+		}
+	}
+}
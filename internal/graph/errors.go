@@ -0,0 +1,56 @@
+package graph
+
+import (
+	"errors"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/jsonlog"
+)
+
+// Error codes reported in the "code" extension of GraphQL errors.
+const (
+	codeNotFound         = "NOT_FOUND"
+	codeEditConflict     = "EDIT_CONFLICT"
+	codeValidationFailed = "VALIDATION_FAILED"
+	codeUnavailable      = "UNAVAILABLE"
+	codeTooComplex       = "QUERY_TOO_COMPLEX"
+	codeBadRequest       = "BAD_REQUEST"
+	codeInternal         = "INTERNAL"
+)
+
+// resolverError is an error that graphql-go reports with extensions.
+type resolverError struct {
+	message    string
+	extensions map[string]interface{}
+}
+
+func (e *resolverError) Error() string { return e.message }
+
+func (e *resolverError) Extensions() map[string]interface{} { return e.extensions }
+
+func newError(code, message string) *resolverError {
+	return &resolverError{message: message, extensions: map[string]interface{}{"code": code}}
+}
+
+func validationError(fields map[string]string) *resolverError {
+	err := newError(codeValidationFailed, "the input is invalid")
+	err.extensions["fields"] = fields
+	return err
+}
+
+var errUnavailable = newError(codeUnavailable, "this field is not available with the configured storage backend")
+
+// mapError turns model errors into client-facing GraphQL errors. Anything unexpected is
+// logged and replaced with a generic message, as the REST handlers do.
+func mapError(logger *jsonlog.Logger, err error) error {
+	switch {
+	case errors.Is(err, data.ErrRecordNotFound):
+		return newError(codeNotFound, "the requested resource could not be found")
+	case errors.Is(err, data.ErrEditConflict):
+		return newError(codeEditConflict, "unable to update the record due to an edit conflict, please try again")
+	}
+
+	logger.PrintError(err, map[string]string{"component": "graphql"})
+
+	return newError(codeInternal, "the server encountered a problem and could not process your request")
+}
@@ -0,0 +1,182 @@
+// Package graph serves a GraphQL schema over the data models. Queries read from the
+// models the caller picks for reads (a replica, for instance) and mutations go to the
+// primary. Student.guardians and Student.courses are batched per request so that listing
+// students with their guardians costs one guardian query, not one per student.
+package graph
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/jsonlog"
+)
+
+//go:embed schema.graphql
+var schemaSource string
+
+// Options limits what a single request may ask for. Zero means no limit.
+type Options struct {
+	MaxDepth      int
+	MaxComplexity int
+	Logger        *jsonlog.Logger
+}
+
+// Server executes GraphQL requests.
+type Server struct {
+	schema *graphql.Schema
+	opts   Options
+}
+
+// Request is a GraphQL request as sent over HTTP.
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+func New(opts Options) (*Server, error) {
+	schema, err := graphql.ParseSchema(schemaSource, &resolver{logger: opts.Logger},
+		graphql.UseStringDescriptions(),
+		graphql.MaxDepth(opts.MaxDepth),
+		graphql.MaxParallelism(20),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Server{schema: schema, opts: opts}, nil
+}
+
+// Execute runs the request. Queries use read and mutations use primary. mutation
+// reports whether the operation was a mutation.
+func (s *Server) Execute(ctx context.Context, primary, read data.Models, req Request) (res *graphql.Response, mutation bool) {
+	doc, ok := parseDocument(req.Query)
+	if !ok {
+		// Report graphql-go's errors in its usual format. A query it accepts but this
+		// package can't read is refused, since neither its operation type nor its cost is
+		// known.
+		if errs := s.schema.Validate(req.Query); len(errs) > 0 {
+			return &graphql.Response{Errors: errs}, false
+		}
+
+		return refuse("query could not be analysed"), false
+	}
+
+	op := doc.operation(req.OperationName)
+	if op == nil {
+		if req.OperationName == "" {
+			return refuse("operationName must name one of the query's operations"), false
+		}
+		return refuse(fmt.Sprintf("no operation named %q", req.OperationName)), false
+	}
+
+	if s.opts.MaxComplexity > 0 && complexity(doc, op, req.Variables, s.opts.MaxComplexity) > s.opts.MaxComplexity {
+		return &graphql.Response{Errors: []*gqlerrors.QueryError{{
+			Message:    fmt.Sprintf("query is too complex: the limit is %d", s.opts.MaxComplexity),
+			Extensions: map[string]interface{}{"code": codeTooComplex},
+		}}}, false
+	}
+
+	models := read
+	mutation = op.mutation
+	if mutation {
+		models = primary
+	}
+
+	state := newRequestState(models)
+	state.mutation = mutation
+
+	return s.schema.Exec(withRequest(ctx, state), req.Query, req.OperationName, req.Variables), mutation
+}
+
+func refuse(message string) *graphql.Response {
+	return &graphql.Response{Errors: []*gqlerrors.QueryError{{
+		Message:    message,
+		Extensions: map[string]interface{}{"code": codeBadRequest},
+	}}}
+}
+
+var errNotMutation = errors.New("graph: mutation resolver called outside a mutation")
+
+// writeModels returns the models a mutation resolver writes to. Execute picks the models
+// from its own reading of the query, so this makes a mutation it took for a query fail
+// rather than write with the read models.
+func writeModels(ctx context.Context) (data.Models, error) {
+	state := requestFrom(ctx)
+	if !state.mutation {
+		return data.Models{}, errNotMutation
+	}
+	return state.models, nil
+}
+
+// requestState is what resolvers need for one request: the models to use and the batch
+// loaders, which must not outlive the request.
+type requestState struct {
+	models    data.Models
+	mutation  bool
+	guardians *loader[int64, []*data.Guardian]
+	courses   *loader[int64, []*data.StudentCourse]
+}
+
+type requestKey struct{}
+
+func withRequest(ctx context.Context, state *requestState) context.Context {
+	return context.WithValue(ctx, requestKey{}, state)
+}
+
+func requestFrom(ctx context.Context) *requestState {
+	return ctx.Value(requestKey{}).(*requestState)
+}
+
+// guardianBatcher and courseBatcher are implemented by the Postgres models. Stores without
+// them are queried once per student.
+type guardianBatcher interface {
+	ListForStudents(studentIDs []int64) (map[int64][]*data.Guardian, error)
+}
+
+type courseBatcher interface {
+	ListForStudents(studentIDs []int64) (map[int64][]*data.StudentCourse, error)
+}
+
+func newRequestState(models data.Models) *requestState {
+	state := &requestState{models: models}
+
+	if models.Guardians != nil {
+		state.guardians = newLoader(func(ids []int64) (map[int64][]*data.Guardian, error) {
+			if b, ok := models.Guardians.(guardianBatcher); ok {
+				return b.ListForStudents(ids)
+			}
+			return fetchEach(ids, models.Guardians.ListForStudent)
+		})
+	}
+
+	if models.Enrollments != nil {
+		state.courses = newLoader(func(ids []int64) (map[int64][]*data.StudentCourse, error) {
+			if b, ok := models.Enrollments.(courseBatcher); ok {
+				return b.ListForStudents(ids)
+			}
+			return fetchEach(ids, models.Enrollments.ListForStudent)
+		})
+	}
+
+	return state
+}
+
+func fetchEach[V any](ids []int64, fetch func(int64) (V, error)) (map[int64]V, error) {
+	results := make(map[int64]V, len(ids))
+
+	for _, id := range ids {
+		v, err := fetch(id)
+		if err != nil {
+			return nil, err
+		}
+		results[id] = v
+	}
+
+	return results, nil
+}
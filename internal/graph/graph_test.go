@@ -0,0 +1,213 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/jsonlog"
+)
+
+// batchGuardians is a GuardianStore that records how it was called.
+type batchGuardians struct {
+	data.GuardianStore
+
+	mu         sync.Mutex
+	batchCalls [][]int64
+	singleCall int
+}
+
+func (g *batchGuardians) ListForStudent(studentID int64) ([]*data.Guardian, error) {
+	g.mu.Lock()
+	g.singleCall++
+	g.mu.Unlock()
+
+	return []*data.Guardian{{ID: studentID * 10, StudentID: studentID, Name: "Guardian"}}, nil
+}
+
+func (g *batchGuardians) ListForStudents(ids []int64) (map[int64][]*data.Guardian, error) {
+	g.mu.Lock()
+	g.batchCalls = append(g.batchCalls, ids)
+	g.mu.Unlock()
+
+	out := map[int64][]*data.Guardian{}
+	for _, id := range ids {
+		out[id] = []*data.Guardian{{ID: id * 10, StudentID: id, Name: "Guardian"}}
+	}
+	return out, nil
+}
+
+func newTestServer(t *testing.T, opts Options) (*Server, data.Models, *batchGuardians) {
+	t.Helper()
+
+	opts.Logger = jsonlog.NewLogger(io.Discard, jsonlog.LevelOff)
+
+	s, err := New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	guardians := &batchGuardians{}
+	models := data.Models{Students: data.NewMemoryStudentModel(), Guardians: guardians}
+
+	for i := int32(1); i <= 5; i++ {
+		err := models.Students.Insert(&data.Student{Name: "Student", RollNo: i, Status: data.StudentApplicant})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return s, models, guardians
+}
+
+func execute(t *testing.T, s *Server, models data.Models, query string, vars map[string]interface{}) map[string]interface{} {
+	t.Helper()
+
+	res, _ := s.Execute(context.Background(), models, models, Request{Query: query, Variables: vars})
+
+	b, err := json.Marshal(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out map[string]interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	return out
+}
+
+func errorCode(out map[string]interface{}) string {
+	errs, _ := out["errors"].([]interface{})
+	if len(errs) == 0 {
+		return ""
+	}
+
+	ext, _ := errs[0].(map[string]interface{})["extensions"].(map[string]interface{})
+	code, _ := ext["code"].(string)
+	return code
+}
+
+func TestGuardiansAreBatched(t *testing.T) {
+	s, models, guardians := newTestServer(t, Options{})
+
+	out := execute(t, s, models, `{ students { id guardians { id } } }`, nil)
+
+	if out["errors"] != nil {
+		t.Fatalf("unexpected errors: %v", out["errors"])
+	}
+
+	students := out["data"].(map[string]interface{})["students"].([]interface{})
+	if len(students) != 5 {
+		t.Fatalf("expected 5 students, got %d", len(students))
+	}
+
+	if guardians.singleCall != 0 || len(guardians.batchCalls) != 1 || len(guardians.batchCalls[0]) != 5 {
+		t.Fatalf("expected one batch of 5 students, got %v batches and %d single calls", guardians.batchCalls, guardians.singleCall)
+	}
+}
+
+func TestErrorMapping(t *testing.T) {
+	s, models, _ := newTestServer(t, Options{})
+
+	tests := []struct {
+		name  string
+		query string
+		code  string
+	}{
+		{"missing student", `{ student(id: "99") { id } }`, codeNotFound},
+		{"malformed student id", `{ student(id: "x") { id } }`, codeNotFound},
+		{"update missing student", `mutation { updateStudent(id: "99", input: {name: "x"}) { id } }`, codeNotFound},
+		{"stale version", `mutation { updateStudent(id: "1", version: 7, input: {name: "x"}) { id } }`, codeEditConflict},
+		{"delete missing student", `mutation { deleteStudent(id: "99") }`, codeNotFound},
+		{"invalid input", `mutation { createStudent(input: {name: "", rollno: 1}) { id } }`, codeValidationFailed},
+		{"no course store", `{ courses { id } }`, codeUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := execute(t, s, models, tt.query, nil)
+
+			if got := errorCode(out); got != tt.code {
+				t.Fatalf("expected code %q, got %q (%v)", tt.code, got, out["errors"])
+			}
+		})
+	}
+}
+
+func TestMutations(t *testing.T) {
+	s, models, _ := newTestServer(t, Options{})
+
+	out := execute(t, s, models, `mutation($in: CreateStudentInput!) { createStudent(input: $in) { id status dateOfBirth } }`, map[string]interface{}{
+		"in": map[string]interface{}{"name": "Ada", "rollno": 42, "dateOfBirth": "2001-02-03"},
+	})
+
+	created := out["data"].(map[string]interface{})["createStudent"].(map[string]interface{})
+	if created["status"] != data.StudentApplicant || created["dateOfBirth"] != "2001-02-03" {
+		t.Fatalf("unexpected student %v", created)
+	}
+
+	out = execute(t, s, models, `mutation { updateStudent(id: "`+created["id"].(string)+`", version: 1, input: {address: "1 Main St"}) { version address } }`, nil)
+
+	updated := out["data"].(map[string]interface{})["updateStudent"].(map[string]interface{})
+	if updated["version"] != float64(2) || updated["address"] != "1 Main St" {
+		t.Fatalf("unexpected update %v", out)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	s, models, _ := newTestServer(t, Options{MaxDepth: 2, MaxComplexity: 50})
+
+	out := execute(t, s, models, `{ students(limit: 5) { id } }`, nil)
+	if out["errors"] != nil {
+		t.Fatalf("expected a cheap query to pass, got %v", out["errors"])
+	}
+
+	out = execute(t, s, models, `query($n: Int) { students(limit: $n) { id name } }`, map[string]interface{}{"n": float64(1000)})
+	if got := errorCode(out); got != codeTooComplex {
+		t.Fatalf("expected %s, got %v", codeTooComplex, out["errors"])
+	}
+
+	out = execute(t, s, models, `{ student(id: "1") { courses { course { id } } } }`, nil)
+	if errs, _ := out["errors"].([]interface{}); len(errs) == 0 || !strings.Contains(errs[0].(map[string]interface{})["message"].(string), "depth") {
+		t.Fatalf("expected a depth error, got %v", out["errors"])
+	}
+}
+
+func TestUnknownOperationIsRefused(t *testing.T) {
+	s, models, _ := newTestServer(t, Options{})
+
+	query := `query A { students { id } } mutation B { deleteStudent(id: "1") }`
+
+	for _, name := range []string{"", "C"} {
+		res, mutation := s.Execute(context.Background(), models, models, Request{Query: query, OperationName: name})
+		if mutation || len(res.Errors) != 1 || res.Errors[0].Extensions["code"] != codeBadRequest {
+			t.Fatalf("operationName %q: expected a %s error, got %v", name, codeBadRequest, res.Errors)
+		}
+	}
+
+	if _, err := models.Students.Get(1); err != nil {
+		t.Fatalf("refused mutation deleted the student: %v", err)
+	}
+}
+
+func TestMutationResolversNeedAMutation(t *testing.T) {
+	s, models, _ := newTestServer(t, Options{})
+
+	r := &resolver{logger: s.opts.Logger}
+	ctx := withRequest(context.Background(), newRequestState(models))
+
+	if _, err := r.DeleteStudent(ctx, struct{ ID graphql.ID }{"1"}); err == nil {
+		t.Fatal("expected a mutation resolver to fail outside a mutation")
+	}
+
+	if _, err := models.Students.Get(1); err != nil {
+		t.Fatalf("mutation resolver deleted the student: %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// loader batches the keys requested by concurrently running resolvers into a single fetch,
+// in the style of DataLoader. graphql-go resolves the elements of a list in parallel, so
+// the Student.guardians resolvers for a page of students all call Load within a moment of
+// each other and share one query. Results are cached for the lifetime of the loader, which
+// is one request.
+type loader[K comparable, V any] struct {
+	fetch    func(keys []K) (map[K]V, error)
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	pending *batch[K, V]
+	done    map[K]*batch[K, V]
+}
+
+type batch[K comparable, V any] struct {
+	keys    []K
+	ready   chan struct{}
+	results map[K]V
+	err     error
+}
+
+func newLoader[K comparable, V any](fetch func([]K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		fetch:    fetch,
+		wait:     2 * time.Millisecond,
+		maxBatch: 500,
+		done:     make(map[K]*batch[K, V]),
+	}
+}
+
+// Load returns the value for key, fetching it together with the other keys requested
+// during the same short window. Keys missing from the fetch result get the zero value.
+func (l *loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+
+	b, ok := l.done[key]
+	if !ok {
+		if l.pending == nil {
+			l.pending = &batch[K, V]{ready: make(chan struct{})}
+			go l.dispatchAfterWait(l.pending)
+		}
+
+		b = l.pending
+		b.keys = append(b.keys, key)
+		l.done[key] = b
+
+		if len(b.keys) >= l.maxBatch {
+			l.pending = nil
+			go l.run(b)
+		}
+	}
+
+	l.mu.Unlock()
+
+	select {
+	case <-b.ready:
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+
+	return b.results[key], b.err
+}
+
+func (l *loader[K, V]) dispatchAfterWait(b *batch[K, V]) {
+	time.Sleep(l.wait)
+
+	l.mu.Lock()
+	if l.pending != b {
+		// The batch filled up and was dispatched already.
+		l.mu.Unlock()
+		return
+	}
+	l.pending = nil
+	l.mu.Unlock()
+
+	l.run(b)
+}
+
+func (l *loader[K, V]) run(b *batch[K, V]) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			b.err = fmt.Errorf("loader: %v", rec)
+		}
+		close(b.ready)
+	}()
+
+	b.results, b.err = l.fetch(b.keys)
+}
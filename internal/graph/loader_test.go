@@ -0,0 +1,65 @@
+package graph
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoaderBatchesConcurrentLoads(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]int64
+
+	l := newLoader(func(keys []int64) (map[int64]int64, error) {
+		mu.Lock()
+		batches = append(batches, keys)
+		mu.Unlock()
+
+		out := make(map[int64]int64, len(keys))
+		for _, k := range keys {
+			out[k] = k * 2
+		}
+		return out, nil
+	})
+	l.maxBatch = 3
+	// Only full batches are dispatched, however slowly the goroutines start.
+	l.wait = time.Minute
+
+	var wg sync.WaitGroup
+	for i := int64(1); i <= 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			v, err := l.Load(context.Background(), i)
+			if err != nil || v != i*2 {
+				t.Errorf("Load(%d) = %d, %v", i, v, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(batches) != 2 {
+		t.Fatalf("expected 2 batches of 3, got %v", batches)
+	}
+
+	// Cached keys are not fetched again.
+	if _, err := l.Load(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(batches) != 2 {
+		t.Fatalf("expected cached load, got %v", batches)
+	}
+}
+
+func TestLoaderRecoversFromPanics(t *testing.T) {
+	l := newLoader(func(keys []int64) (map[int64]int64, error) {
+		panic("boom")
+	})
+
+	if _, err := l.Load(context.Background(), 1); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -0,0 +1,326 @@
+package graph
+
+import (
+	"context"
+	"strconv"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/jsonlog"
+	"github.com/sai29/one2n_sre_bootcamp/internal/validator"
+)
+
+// resolver is the root resolver for Query and Mutation.
+type resolver struct {
+	logger *jsonlog.Logger
+}
+
+func parseID(id graphql.ID) (int64, bool) {
+	n, err := strconv.ParseInt(string(id), 10, 64)
+	return n, err == nil && n > 0
+}
+
+func formatID(id int64) graphql.ID {
+	return graphql.ID(strconv.FormatInt(id, 10))
+}
+
+// === Queries ===
+
+func (r *resolver) Student(ctx context.Context, args struct{ ID graphql.ID }) (*studentResolver, error) {
+	id, ok := parseID(args.ID)
+	if !ok {
+		return nil, mapError(r.logger, data.ErrRecordNotFound)
+	}
+
+	student, err := requestFrom(ctx).models.Students.Get(id)
+	if err != nil {
+		return nil, mapError(r.logger, err)
+	}
+
+	return &studentResolver{r, student}, nil
+}
+
+func (r *resolver) Students(ctx context.Context, args struct {
+	Limit  int32
+	Offset int32
+}) ([]*studentResolver, error) {
+	v := validator.New()
+
+	v.Check(args.Limit >= 1 && args.Limit <= 1000, "limit", "must be between 1 and 1000")
+	v.Check(args.Offset >= 0, "offset", "must not be negative")
+
+	if !v.Valid() {
+		return nil, validationError(v.Errors)
+	}
+
+	students, err := requestFrom(ctx).models.Students.List(ctx, int(args.Limit), int(args.Offset))
+	if err != nil {
+		return nil, mapError(r.logger, err)
+	}
+
+	resolvers := make([]*studentResolver, len(students))
+	for i, s := range students {
+		resolvers[i] = &studentResolver{r, s}
+	}
+
+	return resolvers, nil
+}
+
+func (r *resolver) Course(ctx context.Context, args struct{ ID graphql.ID }) (*courseResolver, error) {
+	courses := requestFrom(ctx).models.Courses
+	if courses == nil {
+		return nil, errUnavailable
+	}
+
+	id, ok := parseID(args.ID)
+	if !ok {
+		return nil, mapError(r.logger, data.ErrRecordNotFound)
+	}
+
+	course, err := courses.Get(id)
+	if err != nil {
+		return nil, mapError(r.logger, err)
+	}
+
+	return &courseResolver{course}, nil
+}
+
+func (r *resolver) Courses(ctx context.Context) ([]*courseResolver, error) {
+	store := requestFrom(ctx).models.Courses
+	if store == nil {
+		return nil, errUnavailable
+	}
+
+	courses, err := store.ListAll()
+	if err != nil {
+		return nil, mapError(r.logger, err)
+	}
+
+	resolvers := make([]*courseResolver, len(courses))
+	for i, c := range courses {
+		resolvers[i] = &courseResolver{c}
+	}
+
+	return resolvers, nil
+}
+
+// === Mutations ===
+
+type createStudentInput struct {
+	Name        string
+	Rollno      int32
+	Email       *string
+	Phone       *string
+	DateOfBirth *string
+	Address     *string
+	Status      *string
+}
+
+func (r *resolver) CreateStudent(ctx context.Context, args struct{ Input createStudentInput }) (*studentResolver, error) {
+	in := args.Input
+	v := validator.New()
+
+	student := data.StudentInput{
+		Name:        &in.Name,
+		RollNo:      &in.Rollno,
+		Email:       in.Email,
+		Phone:       in.Phone,
+		DateOfBirth: in.DateOfBirth,
+		Address:     in.Address,
+		Status:      in.Status,
+	}.NewStudent(v)
+	if !v.Valid() {
+		return nil, validationError(v.Errors)
+	}
+
+	models, err := writeModels(ctx)
+	if err != nil {
+		return nil, mapError(r.logger, err)
+	}
+
+	if err := models.Students.Insert(student); err != nil {
+		return nil, mapError(r.logger, err)
+	}
+
+	return &studentResolver{r, student}, nil
+}
+
+type updateStudentInput struct {
+	Name        *string
+	Rollno      *int32
+	Email       *string
+	Phone       *string
+	DateOfBirth *string
+	Address     *string
+}
+
+func (r *resolver) UpdateStudent(ctx context.Context, args struct {
+	ID      graphql.ID
+	Version *int32
+	Input   updateStudentInput
+}) (*studentResolver, error) {
+	id, ok := parseID(args.ID)
+	if !ok {
+		return nil, mapError(r.logger, data.ErrRecordNotFound)
+	}
+
+	models, err := writeModels(ctx)
+	if err != nil {
+		return nil, mapError(r.logger, err)
+	}
+
+	store := models.Students
+
+	student, err := store.Get(id)
+	if err != nil {
+		return nil, mapError(r.logger, err)
+	}
+
+	if args.Version != nil && *args.Version != student.Version {
+		return nil, mapError(r.logger, data.ErrEditConflict)
+	}
+
+	in := args.Input
+	v := validator.New()
+
+	input := data.StudentInput{
+		Name:        in.Name,
+		RollNo:      in.Rollno,
+		Email:       in.Email,
+		Phone:       in.Phone,
+		DateOfBirth: in.DateOfBirth,
+		Address:     in.Address,
+	}
+
+	if input.ApplyTo(v, student); !v.Valid() {
+		return nil, validationError(v.Errors)
+	}
+
+	if err := store.Update(student); err != nil {
+		return nil, mapError(r.logger, err)
+	}
+
+	return &studentResolver{r, student}, nil
+}
+
+func (r *resolver) DeleteStudent(ctx context.Context, args struct{ ID graphql.ID }) (graphql.ID, error) {
+	id, ok := parseID(args.ID)
+	if !ok {
+		return "", mapError(r.logger, data.ErrRecordNotFound)
+	}
+
+	models, err := writeModels(ctx)
+	if err != nil {
+		return "", mapError(r.logger, err)
+	}
+
+	if err := models.Students.Delete(id); err != nil {
+		return "", mapError(r.logger, err)
+	}
+
+	return args.ID, nil
+}
+
+// === Types ===
+
+type studentResolver struct {
+	root *resolver
+	s    *data.Student
+}
+
+func (r *studentResolver) ID() graphql.ID          { return formatID(r.s.ID) }
+func (r *studentResolver) Name() string            { return r.s.Name }
+func (r *studentResolver) Rollno() int32           { return r.s.RollNo }
+func (r *studentResolver) Email() string           { return r.s.Email }
+func (r *studentResolver) Phone() string           { return r.s.Phone }
+func (r *studentResolver) Address() string         { return r.s.Address }
+func (r *studentResolver) Status() string          { return r.s.Status }
+func (r *studentResolver) Version() int32          { return r.s.Version }
+func (r *studentResolver) CreatedAt() graphql.Time { return graphql.Time{Time: r.s.CreatedAt} }
+func (r *studentResolver) UpdatedAt() graphql.Time { return graphql.Time{Time: r.s.UpdatedAt} }
+
+func (r *studentResolver) DateOfBirth() *string {
+	if r.s.DateOfBirth == nil {
+		return nil
+	}
+
+	s := r.s.DateOfBirth.String()
+	return &s
+}
+
+func (r *studentResolver) Guardians(ctx context.Context) ([]*guardianResolver, error) {
+	l := requestFrom(ctx).guardians
+	if l == nil {
+		return nil, errUnavailable
+	}
+
+	guardians, err := l.Load(ctx, r.s.ID)
+	if err != nil {
+		return nil, mapError(r.root.logger, err)
+	}
+
+	resolvers := make([]*guardianResolver, len(guardians))
+	for i, g := range guardians {
+		resolvers[i] = &guardianResolver{g}
+	}
+
+	return resolvers, nil
+}
+
+func (r *studentResolver) Courses(ctx context.Context) ([]*studentCourseResolver, error) {
+	l := requestFrom(ctx).courses
+	if l == nil {
+		return nil, errUnavailable
+	}
+
+	courses, err := l.Load(ctx, r.s.ID)
+	if err != nil {
+		return nil, mapError(r.root.logger, err)
+	}
+
+	resolvers := make([]*studentCourseResolver, len(courses))
+	for i, c := range courses {
+		resolvers[i] = &studentCourseResolver{c}
+	}
+
+	return resolvers, nil
+}
+
+type studentCourseResolver struct {
+	sc *data.StudentCourse
+}
+
+func (r *studentCourseResolver) EnrollmentID() graphql.ID { return formatID(r.sc.EnrollmentID) }
+func (r *studentCourseResolver) Status() string           { return r.sc.Status }
+func (r *studentCourseResolver) Course() *courseResolver  { return &courseResolver{r.sc.Course} }
+
+func (r *studentCourseResolver) WaitlistPosition() *int32 {
+	if r.sc.Status != data.EnrollmentWaitlisted {
+		return nil
+	}
+
+	pos := int32(r.sc.WaitlistPosition)
+	return &pos
+}
+
+type courseResolver struct {
+	c *data.Course
+}
+
+func (r *courseResolver) ID() graphql.ID  { return formatID(r.c.ID) }
+func (r *courseResolver) Code() string    { return r.c.Code }
+func (r *courseResolver) Title() string   { return r.c.Title }
+func (r *courseResolver) Credits() int32  { return r.c.Credits }
+func (r *courseResolver) Capacity() int32 { return r.c.Capacity }
+func (r *courseResolver) Term() string    { return r.c.Term }
+func (r *courseResolver) Version() int32  { return r.c.Version }
+
+type guardianResolver struct {
+	g *data.Guardian
+}
+
+func (r *guardianResolver) ID() graphql.ID       { return formatID(r.g.ID) }
+func (r *guardianResolver) Name() string         { return r.g.Name }
+func (r *guardianResolver) Relationship() string { return r.g.Relationship }
+func (r *guardianResolver) Email() string        { return r.g.Email }
+func (r *guardianResolver) Phone() string        { return r.g.Phone }
@@ -0,0 +1,315 @@
+// Package grpcapi implements students.v1.StudentService over the data models. It is the
+// gRPC counterpart of the /v1/students handlers and follows the same rules: reads may go
+// to a replica, writes go to the primary, and status changes are left to transitions.
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/jsonlog"
+	"github.com/sai29/one2n_sre_bootcamp/internal/stream"
+	"github.com/sai29/one2n_sre_bootcamp/internal/validator"
+	studentsv1 "github.com/sai29/one2n_sre_bootcamp/pkg/pb/students/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+// StudentService serves students.v1.StudentService.
+type StudentService struct {
+	studentsv1.UnimplementedStudentServiceServer
+
+	// Models is used for writes.
+	Models data.Models
+
+	// ReadModels picks the models for a read. When nil, reads use Models.
+	ReadModels func() data.Models
+
+	// Streams feeds WatchStudents; when nil the call fails with UNAVAILABLE.
+	Streams *stream.Hub
+
+	Logger *jsonlog.Logger
+}
+
+func (s *StudentService) readModels() data.Models {
+	if s.ReadModels == nil {
+		return s.Models
+	}
+	return s.ReadModels()
+}
+
+func (s *StudentService) CreateStudent(_ context.Context, req *studentsv1.CreateStudentRequest) (*studentsv1.Student, error) {
+	v := validator.New()
+
+	student := data.StudentInput{
+		Name:        &req.Name,
+		RollNo:      &req.Rollno,
+		Email:       &req.Email,
+		Phone:       &req.Phone,
+		DateOfBirth: &req.DateOfBirth,
+		Address:     &req.Address,
+		Status:      &req.Status,
+	}.NewStudent(v)
+	if !v.Valid() {
+		return nil, validationError(v.Errors)
+	}
+
+	if err := s.Models.Students.Insert(student); err != nil {
+		return nil, s.internalError(err)
+	}
+
+	return toProto(student), nil
+}
+
+func (s *StudentService) GetStudent(_ context.Context, req *studentsv1.GetStudentRequest) (*studentsv1.Student, error) {
+	if req.GetId() < 1 {
+		return nil, errNotFound
+	}
+
+	student, err := s.readModels().Students.Get(req.GetId())
+	if err != nil {
+		return nil, s.storeError(err)
+	}
+
+	return toProto(student), nil
+}
+
+// ListStudents pages through the students in id order. The page token is the offset of
+// the next page.
+func (s *StudentService) ListStudents(ctx context.Context, req *studentsv1.ListStudentsRequest) (*studentsv1.ListStudentsResponse, error) {
+	v := validator.New()
+
+	size := int(req.GetPageSize())
+	if size == 0 {
+		size = defaultPageSize
+	}
+	v.Check(size >= 1 && size <= maxPageSize, "page_size", "must be between 1 and 1000")
+
+	offset := 0
+	if token := req.GetPageToken(); token != "" {
+		n, err := strconv.Atoi(token)
+		v.Check(err == nil && n >= 0, "page_token", "is not a token returned by ListStudents")
+		offset = n
+	}
+
+	if !v.Valid() {
+		return nil, validationError(v.Errors)
+	}
+
+	// One extra row tells whether there is a next page.
+	students, err := s.readModels().Students.List(ctx, size+1, offset)
+	if err != nil {
+		return nil, s.internalError(err)
+	}
+
+	more := len(students) > size
+	if more {
+		students = students[:size]
+	}
+
+	resp := &studentsv1.ListStudentsResponse{
+		Students: make([]*studentsv1.Student, 0, len(students)),
+	}
+
+	for _, student := range students {
+		resp.Students = append(resp.Students, toProto(student))
+	}
+
+	if more {
+		resp.NextPageToken = strconv.Itoa(offset + size)
+	}
+
+	return resp, nil
+}
+
+func (s *StudentService) UpdateStudent(_ context.Context, req *studentsv1.UpdateStudentRequest) (*studentsv1.Student, error) {
+	if req.GetId() < 1 {
+		return nil, errNotFound
+	}
+
+	student, err := s.Models.Students.Get(req.GetId())
+	if err != nil {
+		return nil, s.storeError(err)
+	}
+
+	if req.Version != nil && req.GetVersion() != student.Version {
+		return nil, errEditConflict
+	}
+
+	v := validator.New()
+
+	input := data.StudentInput{
+		Name:        req.Name,
+		RollNo:      req.Rollno,
+		Email:       req.Email,
+		Phone:       req.Phone,
+		DateOfBirth: req.DateOfBirth,
+		Address:     req.Address,
+	}
+
+	if input.ApplyTo(v, student); !v.Valid() {
+		return nil, validationError(v.Errors)
+	}
+
+	if err := s.Models.Students.Update(student); err != nil {
+		return nil, s.storeError(err)
+	}
+
+	return toProto(student), nil
+}
+
+func (s *StudentService) DeleteStudent(_ context.Context, req *studentsv1.DeleteStudentRequest) (*emptypb.Empty, error) {
+	if req.GetId() < 1 {
+		return nil, errNotFound
+	}
+
+	if err := s.Models.Students.Delete(req.GetId()); err != nil {
+		return nil, s.storeError(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// WatchStudents streams the events the hub receives from the outbox relay, after replaying
+// the buffered events that follow last_event_id. It returns when the client goes away,
+// falls too far behind, or the server shuts down; clients resume with the last id they saw.
+func (s *StudentService) WatchStudents(req *studentsv1.WatchStudentsRequest, srv studentsv1.StudentService_WatchStudentsServer) error {
+	if s.Streams == nil {
+		return status.Error(codes.Unavailable, "student events need the postgres backend")
+	}
+
+	if req.GetLastEventId() < 0 {
+		return validationError(map[string]string{"last_event_id": "must not be negative"})
+	}
+
+	sub, replay, complete := s.Streams.Subscribe(req.GetLastEventId())
+	defer s.Streams.Unsubscribe(sub)
+
+	if !complete {
+		if err := srv.Send(&studentsv1.StudentEvent{Type: "reset"}); err != nil {
+			return err
+		}
+	}
+
+	for _, event := range replay {
+		if err := s.sendEvent(srv, event); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-srv.Context().Done():
+			return nil
+
+		case event, ok := <-sub.C:
+			if !ok {
+				return nil
+			}
+
+			if err := s.sendEvent(srv, event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *StudentService) sendEvent(srv studentsv1.StudentService_WatchStudentsServer, event *data.OutboxEvent) error {
+	var student data.Student
+	if err := json.Unmarshal(event.Payload, &student); err != nil {
+		return s.internalError(err)
+	}
+
+	return srv.Send(&studentsv1.StudentEvent{
+		Id:         event.ID,
+		Type:       event.Type,
+		OccurredAt: timestamppb.New(event.CreatedAt),
+		Student:    toProto(&student),
+	})
+}
+
+// === Conversions ===
+
+func toProto(s *data.Student) *studentsv1.Student {
+	pb := &studentsv1.Student{
+		Id:        s.ID,
+		CreatedAt: timestamppb.New(s.CreatedAt),
+		UpdatedAt: timestamppb.New(s.UpdatedAt),
+		Name:      s.Name,
+		Rollno:    s.RollNo,
+		Email:     s.Email,
+		Phone:     s.Phone,
+		Address:   s.Address,
+		Status:    s.Status,
+		Version:   s.Version,
+	}
+
+	if s.DateOfBirth != nil {
+		pb.DateOfBirth = s.DateOfBirth.String()
+	}
+
+	return pb
+}
+
+// === Errors ===
+
+var (
+	errNotFound     = status.Error(codes.NotFound, "the requested student could not be found")
+	errEditConflict = status.Error(codes.Aborted, "unable to update the record due to an edit conflict, please try again")
+)
+
+// storeError maps the data package's errors to status codes.
+func (s *StudentService) storeError(err error) error {
+	switch {
+	case errors.Is(err, data.ErrRecordNotFound):
+		return errNotFound
+	case errors.Is(err, data.ErrEditConflict):
+		return errEditConflict
+	default:
+		return s.internalError(err)
+	}
+}
+
+// internalError logs err and hides it from the client.
+func (s *StudentService) internalError(err error) error {
+	s.Logger.PrintError(err, map[string]string{"service": "students.v1.StudentService"})
+	return status.Error(codes.Internal, "the server encountered a problem and could not process your request")
+}
+
+// validationError reports failed validation as INVALID_ARGUMENT with a BadRequest detail
+// holding one violation per field.
+func validationError(fields map[string]string) error {
+	st := status.New(codes.InvalidArgument, "validation failed")
+
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+
+	details := &errdetails.BadRequest{}
+	for _, field := range names {
+		details.FieldViolations = append(details.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: fields[field],
+		})
+	}
+
+	if withDetails, err := st.WithDetails(details); err == nil {
+		st = withDetails
+	}
+
+	return st.Err()
+}
@@ -0,0 +1,234 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/jsonlog"
+	"github.com/sai29/one2n_sre_bootcamp/internal/stream"
+	studentsv1 "github.com/sai29/one2n_sre_bootcamp/pkg/pb/students/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func newTestService() *StudentService {
+	return &StudentService{
+		Models: data.Models{Students: data.NewMemoryStudentModel()},
+		Logger: jsonlog.NewLogger(io.Discard, jsonlog.LevelOff),
+	}
+}
+
+func TestStudentLifecycle(t *testing.T) {
+	s := newTestService()
+	ctx := context.Background()
+
+	created, err := s.CreateStudent(ctx, &studentsv1.CreateStudentRequest{Name: "Ada", Rollno: 1, DateOfBirth: "2001-02-03"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if created.GetStatus() != data.StudentApplicant || created.GetDateOfBirth() != "2001-02-03" || created.GetVersion() != 1 {
+		t.Fatalf("unexpected student %v", created)
+	}
+
+	got, err := s.GetStudent(ctx, &studentsv1.GetStudentRequest{Id: created.GetId()})
+	if err != nil || !proto.Equal(got, created) {
+		t.Fatalf("GetStudent = %v, %v", got, err)
+	}
+
+	updated, err := s.UpdateStudent(ctx, &studentsv1.UpdateStudentRequest{
+		Id:      created.GetId(),
+		Version: proto.Int32(1),
+		Address: proto.String("1 Main St"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if updated.GetAddress() != "1 Main St" || updated.GetVersion() != 2 || updated.GetName() != "Ada" {
+		t.Fatalf("unexpected update %v", updated)
+	}
+
+	if _, err := s.DeleteStudent(ctx, &studentsv1.DeleteStudentRequest{Id: created.GetId()}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.GetStudent(ctx, &studentsv1.GetStudentRequest{Id: created.GetId()}); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound after delete, got %v", err)
+	}
+}
+
+func TestStudentErrors(t *testing.T) {
+	s := newTestService()
+	ctx := context.Background()
+
+	if _, err := s.CreateStudent(ctx, &studentsv1.CreateStudentRequest{Name: "Ada", Rollno: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+		code codes.Code
+	}{
+		{"get missing", func() error {
+			_, err := s.GetStudent(ctx, &studentsv1.GetStudentRequest{Id: 99})
+			return err
+		}, codes.NotFound},
+		{"get invalid id", func() error {
+			_, err := s.GetStudent(ctx, &studentsv1.GetStudentRequest{Id: -1})
+			return err
+		}, codes.NotFound},
+		{"update stale version", func() error {
+			_, err := s.UpdateStudent(ctx, &studentsv1.UpdateStudentRequest{Id: 1, Version: proto.Int32(5), Name: proto.String("x")})
+			return err
+		}, codes.Aborted},
+		{"update missing", func() error {
+			_, err := s.UpdateStudent(ctx, &studentsv1.UpdateStudentRequest{Id: 99, Name: proto.String("x")})
+			return err
+		}, codes.NotFound},
+		{"delete missing", func() error {
+			_, err := s.DeleteStudent(ctx, &studentsv1.DeleteStudentRequest{Id: 99})
+			return err
+		}, codes.NotFound},
+		{"invalid page token", func() error {
+			_, err := s.ListStudents(ctx, &studentsv1.ListStudentsRequest{PageToken: "abc"})
+			return err
+		}, codes.InvalidArgument},
+		{"watch without streams", func() error {
+			return s.WatchStudents(&studentsv1.WatchStudentsRequest{}, nil)
+		}, codes.Unavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status.Code(tt.call()); got != tt.code {
+				t.Fatalf("expected %s, got %s", tt.code, got)
+			}
+		})
+	}
+}
+
+func TestCreateStudentValidation(t *testing.T) {
+	s := newTestService()
+
+	_, err := s.CreateStudent(context.Background(), &studentsv1.CreateStudentRequest{DateOfBirth: "yesterday"})
+
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument, got %v", err)
+	}
+
+	var fields []string
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			for _, v := range br.GetFieldViolations() {
+				fields = append(fields, v.GetField())
+			}
+		}
+	}
+
+	want := []string{"date_of_birth", "name", "rollno"}
+	if len(fields) != len(want) {
+		t.Fatalf("expected violations for %v, got %v", want, fields)
+	}
+	for i := range want {
+		if fields[i] != want[i] {
+			t.Fatalf("expected violations for %v, got %v", want, fields)
+		}
+	}
+}
+
+func TestListStudentsPagination(t *testing.T) {
+	s := newTestService()
+	ctx := context.Background()
+
+	for i := int32(1); i <= 5; i++ {
+		if _, err := s.CreateStudent(ctx, &studentsv1.CreateStudentRequest{Name: "Student", Rollno: i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var ids []int64
+	req := &studentsv1.ListStudentsRequest{PageSize: 2}
+
+	for pages := 1; ; pages++ {
+		resp, err := s.ListStudents(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, student := range resp.GetStudents() {
+			ids = append(ids, student.GetId())
+		}
+
+		if resp.GetNextPageToken() == "" {
+			if pages != 3 {
+				t.Fatalf("expected 3 pages, got %d", pages)
+			}
+			break
+		}
+
+		req.PageToken = resp.GetNextPageToken()
+	}
+
+	if len(ids) != 5 || ids[0] != 1 || ids[4] != 5 {
+		t.Fatalf("unexpected ids %v", ids)
+	}
+}
+
+// watchStream is a StudentService_WatchStudentsServer that collects what is sent.
+type watchStream struct {
+	grpc.ServerStream
+
+	ctx    context.Context
+	events chan *studentsv1.StudentEvent
+}
+
+func (w *watchStream) Context() context.Context              { return w.ctx }
+func (w *watchStream) SetHeader(metadata.MD) error           { return nil }
+func (w *watchStream) Send(e *studentsv1.StudentEvent) error { w.events <- e; return nil }
+
+func TestWatchStudents(t *testing.T) {
+	s := newTestService()
+	s.Streams = stream.NewHub(10)
+
+	event := func(id int64) *data.OutboxEvent {
+		payload, _ := json.Marshal(&data.Student{ID: 7, Name: "Ada", Version: int32(id)})
+		return &data.OutboxEvent{ID: id, CreatedAt: time.Now(), AggregateID: 7, Type: data.EventStudentUpdated, Payload: payload}
+	}
+
+	_ = s.Streams.Send(context.Background(), event(1))
+	_ = s.Streams.Send(context.Background(), event(2))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	srv := &watchStream{ctx: ctx, events: make(chan *studentsv1.StudentEvent, 10)}
+
+	done := make(chan error)
+	go func() { done <- s.WatchStudents(&studentsv1.WatchStudentsRequest{LastEventId: 1}, srv) }()
+
+	if e := <-srv.events; e.GetId() != 2 || e.GetStudent().GetVersion() != 2 {
+		t.Fatalf("expected replay of event 2, got %v", e)
+	}
+
+	// The replay is sent after subscribing, so event 3 reaches the live subscription.
+	_ = s.Streams.Send(context.Background(), event(3))
+
+	if e := <-srv.events; e.GetId() != 3 || e.GetType() != data.EventStudentUpdated || e.GetStudent().GetId() != 7 {
+		t.Fatalf("expected live event 3, got %v", e)
+	}
+
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Fatalf("expected a clean return, got %v", err)
+	}
+}
@@ -0,0 +1,402 @@
+// Package migrate applies the SQL migrations embedded in the binary. It keeps its state in
+// the same schema_migrations table that golang-migrate uses, so databases migrated with the
+// migrate CLI can be picked up without any conversion.
+//
+// Every migration runs in its own transaction together with the version bump, and the
+// whole run holds a Postgres advisory lock so that several replicas starting at once do
+// not race each other.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/jsonlog"
+)
+
+// lockKey is the pg_advisory_lock key held while migrations run.
+const lockKey int64 = 4_019_662_717
+
+var (
+	// ErrDirty is returned when a previous run left schema_migrations marked dirty.
+	ErrDirty = errors.New("migrate: database is dirty, fix the schema by hand and run force")
+
+	// ErrUnknownVersion is returned when a target version has no migration file.
+	ErrUnknownVersion = errors.New("migrate: unknown version")
+
+	// ErrNoDownMigration is returned when rolling back a version without a down file.
+	ErrNoDownMigration = errors.New("migrate: no down migration")
+)
+
+var filenameRX = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration is a single numbered schema change.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Load reads NNNNNN_name.up.sql and NNNNNN_name.down.sql files from the root of fsys and
+// returns them ordered by version. Other files are ignored.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		match := filenameRX.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version < 1 {
+			return nil, fmt.Errorf("migrate: invalid version in %q", entry.Name())
+		}
+
+		body, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migrate: version %d has files with different names (%q and %q)", version, m.Name, match[2])
+		}
+
+		switch match[3] {
+		case "up":
+			m.Up = string(body)
+		case "down":
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migrate: version %d has no up migration", m.Version)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return int(a.Version - b.Version)
+	})
+
+	return migrations, nil
+}
+
+// Migrator applies a fixed set of migrations to a database.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+
+	// Logger receives one entry per applied migration. It may be nil.
+	Logger *jsonlog.Logger
+}
+
+// New loads the migrations in fsys and returns a Migrator for db.
+func New(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Latest returns the highest known migration version, or 0 if there are none.
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Version returns the version recorded in schema_migrations and whether it is dirty. A
+// database that has never been migrated reports version 0.
+func (m *Migrator) Version(ctx context.Context) (int64, bool, error) {
+	return readVersion(ctx, m.db)
+}
+
+// MigrationState reports whether a single migration has been applied.
+type MigrationState struct {
+	Version int64  `json:"version"`
+	Name    string `json:"name"`
+	Applied bool   `json:"applied"`
+}
+
+// Status describes the database schema relative to the known migrations.
+type Status struct {
+	Version    int64            `json:"version"`
+	Dirty      bool             `json:"dirty"`
+	Latest     int64            `json:"latest"`
+	Migrations []MigrationState `json:"migrations"`
+}
+
+// Status returns the current version together with the applied state of every migration.
+func (m *Migrator) Status(ctx context.Context) (*Status, error) {
+	version, dirty, err := m.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{
+		Version:    version,
+		Dirty:      dirty,
+		Latest:     m.Latest(),
+		Migrations: make([]MigrationState, 0, len(m.migrations)),
+	}
+
+	for _, mig := range m.migrations {
+		status.Migrations = append(status.Migrations, MigrationState{
+			Version: mig.Version,
+			Name:    mig.Name,
+			Applied: mig.Version <= version,
+		})
+	}
+
+	return status, nil
+}
+
+// Up applies every pending migration.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.run(ctx, func(int64) (int64, error) {
+		return m.Latest(), nil
+	})
+}
+
+// Down rolls back the given number of applied migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.run(ctx, func(current int64) (int64, error) {
+		i := m.index(current)
+		if i < 0 {
+			return current, nil
+		}
+
+		target := i - steps
+		if target < 0 {
+			return 0, nil
+		}
+		return m.migrations[target].Version, nil
+	})
+}
+
+// Goto migrates up or down until the database is at version. Version 0 rolls back every
+// migration.
+func (m *Migrator) Goto(ctx context.Context, version int64) error {
+	if version != 0 && m.index(version) < 0 {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	return m.run(ctx, func(int64) (int64, error) {
+		return version, nil
+	})
+}
+
+// Force records version as the current version and clears the dirty flag without running
+// any SQL. It is meant for recovering after a failed migration has been fixed by hand.
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	if version != 0 && m.index(version) < 0 {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = tx.Rollback() }()
+
+		if err := setVersion(ctx, tx, version); err != nil {
+			return err
+		}
+
+		return tx.Commit()
+	})
+}
+
+// run takes the lock, works out the target version from the current one and applies the
+// migrations between them one transaction at a time.
+func (m *Migrator) run(ctx context.Context, target func(current int64) (int64, error)) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		current, dirty, err := readVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		if dirty {
+			return fmt.Errorf("%w (version %d)", ErrDirty, current)
+		}
+
+		to, err := target(current)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			if mig.Version <= current || mig.Version > to {
+				continue
+			}
+			if err := m.apply(ctx, conn, mig, "up", mig.Up, mig.Version); err != nil {
+				return err
+			}
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mig := m.migrations[i]
+			if mig.Version > current || mig.Version <= to {
+				continue
+			}
+			if mig.Down == "" {
+				return fmt.Errorf("%w for version %d", ErrNoDownMigration, mig.Version)
+			}
+
+			var previous int64
+			if i > 0 {
+				previous = m.migrations[i-1].Version
+			}
+			if err := m.apply(ctx, conn, mig, "down", mig.Down, previous); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// apply runs a single migration body and records the resulting version in the same
+// transaction, so a failure leaves both the schema and schema_migrations untouched.
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, mig Migration, direction, body string, version int64) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, body); err != nil {
+		return fmt.Errorf("migrate: %d_%s.%s.sql: %w", mig.Version, mig.Name, direction, err)
+	}
+
+	if err := setVersion(ctx, tx, version); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if m.Logger != nil {
+		m.Logger.PrintInfo("applied migration", map[string]string{
+			"version":   strconv.FormatInt(mig.Version, 10),
+			"name":      mig.Name,
+			"direction": direction,
+		})
+	}
+
+	return nil
+}
+
+// withLock runs fn on a dedicated connection holding the advisory lock. Session-level
+// advisory locks belong to a connection, so everything has to go through conn.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockKey); err != nil {
+		return fmt.Errorf("migrate: acquiring lock: %w", err)
+	}
+
+	defer func() {
+		_, _ = conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lockKey)
+	}()
+
+	if _, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version bigint NOT NULL PRIMARY KEY,
+			dirty boolean NOT NULL
+		)`); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+func (m *Migrator) index(version int64) int {
+	for i, mig := range m.migrations {
+		if mig.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func readVersion(ctx context.Context, q querier) (int64, bool, error) {
+	var exists bool
+	if err := q.QueryRowContext(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return 0, false, err
+	}
+
+	if !exists {
+		return 0, false, nil
+	}
+
+	var (
+		version int64
+		dirty   bool
+	)
+
+	err := q.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+
+	return version, dirty, nil
+}
+
+// setVersion replaces the single schema_migrations row. Version 0 leaves the table empty,
+// which is how golang-migrate represents a database with nothing applied.
+func setVersion(ctx context.Context, q querier, version int64) error {
+	if _, err := q.ExecContext(ctx, `DELETE FROM schema_migrations`); err != nil {
+		return err
+	}
+
+	if version == 0 {
+		return nil
+	}
+
+	_, err := q.ExecContext(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)`, version)
+	return err
+}
@@ -0,0 +1,95 @@
+package migrate
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/sai29/one2n_sre_bootcamp/migrations"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    fstest.MapFS
+		versions []int64
+		wantErr  bool
+	}{
+		{
+			name: "ordered by version",
+			files: fstest.MapFS{
+				"000010_c.up.sql":   {Data: []byte("SELECT 10")},
+				"000002_b.up.sql":   {Data: []byte("SELECT 2")},
+				"000002_b.down.sql": {Data: []byte("SELECT -2")},
+				"000001_a.up.sql":   {Data: []byte("SELECT 1")},
+				"README.md":         {Data: []byte("ignored")},
+			},
+			versions: []int64{1, 2, 10},
+		},
+		{
+			name: "down without up",
+			files: fstest.MapFS{
+				"000001_a.down.sql": {Data: []byte("SELECT 1")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "mismatched names",
+			files: fstest.MapFS{
+				"000001_a.up.sql":   {Data: []byte("SELECT 1")},
+				"000001_b.down.sql": {Data: []byte("SELECT 1")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "version zero",
+			files: fstest.MapFS{
+				"000000_a.up.sql": {Data: []byte("SELECT 1")},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Load(tt.files)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(got) != len(tt.versions) {
+				t.Fatalf("got %d migrations, want %d", len(got), len(tt.versions))
+			}
+			for i, v := range tt.versions {
+				if got[i].Version != v {
+					t.Errorf("migration %d: got version %d, want %d", i, got[i].Version, v)
+				}
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	got, err := Load(migrations.FS)
+	if err != nil {
+		t.Fatalf("loading embedded migrations: %v", err)
+	}
+
+	if len(got) == 0 {
+		t.Fatal("no embedded migrations")
+	}
+
+	for i, m := range got {
+		if m.Version != int64(i+1) {
+			t.Errorf("migration %d has version %d, versions should be contiguous", i, m.Version)
+		}
+		if m.Down == "" {
+			t.Errorf("migration %d_%s has no down file", m.Version, m.Name)
+		}
+	}
+}
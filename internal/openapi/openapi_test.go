@@ -0,0 +1,90 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type inner struct {
+	Note string `json:"note,omitempty"`
+}
+
+type sample struct {
+	inner
+	ID       int64             `json:"id"`
+	Name     string            `json:"name"`
+	Created  time.Time         `json:"created_at"`
+	Deleted  *time.Time        `json:"deleted_at,omitempty"`
+	Tags     []string          `json:"tags"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Payload  json.RawMessage   `json:"payload"`
+	Secret   string            `json:"-"`
+	internal string
+}
+
+func TestReflector(t *testing.T) {
+	s := (&Reflector{}).Schema(sample{})
+
+	tests := []struct {
+		field string
+		want  *Schema
+	}{
+		{"id", &Schema{Type: "integer", Format: "int64"}},
+		{"name", &Schema{Type: "string"}},
+		{"note", &Schema{Type: "string"}},
+		{"created_at", &Schema{Type: "string", Format: "date-time"}},
+		{"deleted_at", &Schema{Type: []string{"string", "null"}, Format: "date-time"}},
+		{"tags", &Schema{Type: []string{"array", "null"}, Items: &Schema{Type: "string"}}},
+		{"labels", &Schema{Type: "object", AdditionalProperties: &Schema{Type: "string"}}},
+		{"payload", &Schema{}},
+	}
+
+	for _, tt := range tests {
+		if got := s.Properties[tt.field]; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.field, got, tt.want)
+		}
+	}
+
+	if len(s.Properties) != len(tests) {
+		t.Errorf("expected %d properties, got %v", len(tests), s.Properties)
+	}
+
+	want := []string{"id", "name", "created_at", "tags", "payload"}
+	if !reflect.DeepEqual(s.Required, want) {
+		t.Errorf("required = %v, want %v", s.Required, want)
+	}
+}
+
+func TestReflectorOverride(t *testing.T) {
+	type date struct{ t time.Time }
+
+	r := &Reflector{Types: map[reflect.Type]*Schema{
+		reflect.TypeOf(date{}): {Type: "string", Format: "date"},
+	}}
+
+	got := r.Schema(struct {
+		Born *date `json:"born"`
+	}{}).Properties["born"]
+
+	want := &Schema{Type: []string{"string", "null"}, Format: "date"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestPathFromGin(t *testing.T) {
+	tests := map[string]string{
+		"/v1/students":                      "/v1/students",
+		"/v1/students/:id":                  "/v1/students/{id}",
+		"/v1/courses/:id/enrollments/:s_id": "/v1/courses/{id}/enrollments/{s_id}",
+		"/docs/*filepath":                   "/docs/*filepath",
+	}
+
+	for in, want := range tests {
+		if got := PathFromGin(in); got != want {
+			t.Errorf("PathFromGin(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
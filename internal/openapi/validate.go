@@ -0,0 +1,319 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// docURL is the location the document is registered under, so that the $refs to
+// #/components/schemas in its schemas resolve.
+const docURL = "openapi.json"
+
+// Validator checks HTTP requests and responses against the operations of a Document.
+type Validator struct {
+	routes map[string]*Route
+}
+
+// Route is a compiled operation.
+type Route struct {
+	OperationID string
+
+	params    []param
+	body      *jsonschema.Schema
+	required  bool
+	other     []string
+	responses map[string]*response
+	streams   bool
+}
+
+type param struct {
+	name     string
+	in       string
+	required bool
+	kind     string
+	schema   *jsonschema.Schema
+}
+
+type response struct {
+	// schema is nil when the response's body is not JSON, or it has none.
+	schema *jsonschema.Schema
+}
+
+// NewValidator compiles every schema in doc. It fails if a schema is invalid or has a
+// $ref that doesn't resolve.
+func NewValidator(doc *Document) (*Validator, error) {
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := jsonschema.UnmarshalJSON(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	c := jsonschema.NewCompiler()
+	c.DefaultDraft(jsonschema.Draft2020)
+
+	if err := c.AddResource(docURL, raw); err != nil {
+		return nil, err
+	}
+
+	compile := func(ptr string) (*jsonschema.Schema, error) {
+		return c.Compile(docURL + "#" + ptr)
+	}
+
+	v := &Validator{routes: make(map[string]*Route)}
+
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			base := "/paths/" + escapePointer(path) + "/" + method
+			rt := &Route{OperationID: op.OperationID, responses: make(map[string]*response)}
+
+			for i, p := range op.Parameters {
+				s, err := compile(fmt.Sprintf("%s/parameters/%d/schema", base, i))
+				if err != nil {
+					return nil, fmt.Errorf("%s parameter %s: %w", op.OperationID, p.Name, err)
+				}
+				rt.params = append(rt.params, param{p.Name, p.In, p.Required, primaryType(p.Schema), s})
+			}
+
+			if rb := op.RequestBody; rb != nil {
+				for mediaType := range rb.Content {
+					if mediaType != "application/json" {
+						rt.other = append(rt.other, mediaType)
+					}
+				}
+
+				if rb.Content["application/json"] != nil {
+					rt.body, err = compile(base + "/requestBody/content/application~1json/schema")
+					if err != nil {
+						return nil, fmt.Errorf("%s request body: %w", op.OperationID, err)
+					}
+					rt.required = rb.Required
+				}
+			}
+
+			for code, r := range op.Responses {
+				ptr := base + "/responses/" + code
+
+				if name, ok := strings.CutPrefix(r.Ref, "#/components/responses/"); ok {
+					if r = doc.Components.Responses[name]; r == nil {
+						return nil, fmt.Errorf("%s response %s: unknown response %q", op.OperationID, code, name)
+					}
+					ptr = "/components/responses/" + name
+				}
+
+				res := &response{}
+				if r.Content["application/json"] != nil {
+					res.schema, err = compile(ptr + "/content/application~1json/schema")
+					if err != nil {
+						return nil, fmt.Errorf("%s response %s: %w", op.OperationID, code, err)
+					}
+				} else if len(r.Content) > 0 && strings.HasPrefix(code, "2") {
+					rt.streams = true
+				}
+				rt.responses[code] = res
+			}
+
+			v.routes[strings.ToUpper(method)+" "+path] = rt
+		}
+	}
+
+	return v, nil
+}
+
+// Route returns the operation for method and path, which uses OpenAPI's {param} syntax,
+// or nil if the document doesn't describe it.
+func (v *Validator) Route(method, path string) *Route {
+	return v.routes[strings.ToUpper(method)+" "+path]
+}
+
+// Streams reports whether a successful response may be something other than JSON, such
+// as a file download or an event stream. Those bodies are not checked.
+func (rt *Route) Streams() bool {
+	return rt.streams
+}
+
+// ChecksBody reports whether CheckRequest validates a request body sent with the given
+// Content-Type. The handlers decode JSON whatever the header says, so only the operation's
+// other documented formats, such as a CSV import, are exempt.
+func (rt *Route) ChecksBody(header string) bool {
+	return rt.body != nil && !slices.Contains(rt.other, contentType(header))
+}
+
+// CheckRequest returns the ways r breaks the operation's contract. pathParams holds the
+// values of the path parameters and body the request body, which r.Body no longer has.
+func (rt *Route) CheckRequest(r *http.Request, pathParams map[string]string, body []byte) []string {
+	var problems []string
+
+	query := r.URL.Query()
+
+	for _, p := range rt.params {
+		var (
+			value   string
+			present bool
+		)
+
+		switch p.in {
+		case "path":
+			value, present = pathParams[p.name]
+		case "query":
+			present = query.Has(p.name)
+			value = query.Get(p.name)
+		case "header":
+			value = r.Header.Get(p.name)
+			present = value != ""
+		}
+
+		where := p.in + " parameter " + p.name
+
+		if !present {
+			if p.required {
+				problems = append(problems, where+" is required")
+			}
+			continue
+		}
+
+		v, err := coerce(value, p.kind)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %q is not a valid %s", where, value, p.kind))
+			continue
+		}
+
+		problems = append(problems, check(where, p.schema, v)...)
+	}
+
+	if !rt.ChecksBody(r.Header.Get("Content-Type")) {
+		return problems
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if rt.required {
+			problems = append(problems, "request body is required")
+		}
+		return problems
+	}
+
+	return append(problems, checkJSON("request body", rt.body, body)...)
+}
+
+// CheckResponse returns the ways a response breaks the operation's contract.
+func (rt *Route) CheckResponse(status int, header http.Header, body []byte) []string {
+	res, ok := rt.responses[strconv.Itoa(status)]
+	if !ok {
+		res, ok = rt.responses["default"]
+	}
+	if !ok {
+		return []string{fmt.Sprintf("status %d is not documented", status)}
+	}
+
+	if res.schema == nil {
+		return nil
+	}
+
+	if mediaType := contentType(header.Get("Content-Type")); mediaType != "application/json" {
+		return []string{fmt.Sprintf("response content type is %q, not application/json", mediaType)}
+	}
+
+	return checkJSON("response body", res.schema, body)
+}
+
+func checkJSON(where string, s *jsonschema.Schema, body []byte) []string {
+	v, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	if err != nil {
+		return []string{fmt.Sprintf("%s is not valid JSON: %v", where, err)}
+	}
+
+	return check(where, s, v)
+}
+
+// check validates v against s, returning one problem per failing leaf of the schema.
+func check(where string, s *jsonschema.Schema, v any) []string {
+	err := s.Validate(v)
+	if err == nil {
+		return nil
+	}
+
+	var verr *jsonschema.ValidationError
+	if !errors.As(err, &verr) {
+		return []string{where + ": " + err.Error()}
+	}
+
+	var problems []string
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			// A leaf's message reads "at '/pointer': reason".
+			problems = append(problems, where+" "+e.Error())
+			return
+		}
+		for _, cause := range e.Causes {
+			walk(cause)
+		}
+	}
+	walk(verr)
+
+	sort.Strings(problems)
+	return problems
+}
+
+// coerce converts a parameter's string value to the JSON type its schema expects.
+func coerce(value, kind string) (any, error) {
+	switch kind {
+	case "integer":
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return nil, err
+		}
+		return json.Number(value), nil
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return nil, err
+		}
+		return json.Number(value), nil
+	case "boolean":
+		return strconv.ParseBool(value)
+	}
+	return value, nil
+}
+
+// primaryType returns the first non-null type of s.
+func primaryType(s *Schema) string {
+	switch t := s.Type.(type) {
+	case string:
+		return t
+	case []string:
+		for _, name := range t {
+			if name != "null" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+func contentType(header string) string {
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return header
+	}
+	return mediaType
+}
+
+// escapePointer escapes a JSON Pointer token and makes it safe in a URL fragment.
+func escapePointer(token string) string {
+	token = strings.ReplaceAll(token, "~", "~0")
+	token = strings.ReplaceAll(token, "/", "~1")
+	token = strings.ReplaceAll(token, "{", "%7B")
+	return strings.ReplaceAll(token, "}", "%7D")
+}
@@ -0,0 +1,115 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func testValidator(t *testing.T) *Route {
+	t.Helper()
+
+	one := 1.0
+	doc := &Document{
+		OpenAPI: Version,
+		Components: Components{
+			Schemas: map[string]*Schema{
+				"Item": {Type: "object", Required: []string{"id"}, Properties: map[string]*Schema{
+					"id": {Type: "integer"},
+				}},
+			},
+			Responses: map[string]*Response{
+				"NotFound": {Description: "not found"},
+			},
+		},
+	}
+
+	doc.Add(http.MethodPut, "/items/{id}", &Operation{
+		OperationID: "putItem",
+		Parameters: []*Parameter{
+			{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "integer", Minimum: &one}},
+			{Name: "mode", In: "query", Schema: &Schema{Type: "string", Enum: []any{"a", "b"}}},
+			{Name: "dry_run", In: "query", Schema: &Schema{Type: "boolean"}},
+		},
+		RequestBody: &RequestBody{Required: true, Content: map[string]*MediaType{
+			"application/json": {Schema: Ref("Item")},
+		}},
+		Responses: map[string]*Response{
+			"200": {Description: "ok", Content: map[string]*MediaType{"application/json": {Schema: Ref("Item")}}},
+			"404": {Ref: "#/components/responses/NotFound"},
+		},
+	})
+
+	v, err := NewValidator(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rt := v.Route(http.MethodPut, "/items/{id}")
+	if rt == nil || rt.OperationID != "putItem" {
+		t.Fatalf("expected the putItem route, got %+v", rt)
+	}
+
+	return rt
+}
+
+func TestCheckRequest(t *testing.T) {
+	rt := testValidator(t)
+
+	tests := []struct {
+		name     string
+		id       string
+		query    string
+		body     string
+		problems int
+	}{
+		{"valid", "1", "?mode=a&dry_run=true", `{"id": 1}`, 0},
+		{"id not an integer", "x", "", `{"id": 1}`, 1},
+		{"id below minimum", "0", "", `{"id": 1}`, 1},
+		{"bad enum", "1", "?mode=c", `{"id": 1}`, 1},
+		{"bad boolean", "1", "?dry_run=maybe", `{"id": 1}`, 1},
+		{"missing body", "1", "", ``, 1},
+		{"malformed body", "1", "", `{`, 1},
+		{"wrong body type", "1", "", `{"id": "1"}`, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/items/"+tt.id+tt.query, nil)
+			r.Header.Set("Content-Type", "application/json")
+
+			problems := rt.CheckRequest(r, map[string]string{"id": tt.id}, []byte(tt.body))
+			if len(problems) != tt.problems {
+				t.Fatalf("expected %d problems, got %q", tt.problems, problems)
+			}
+		})
+	}
+}
+
+func TestCheckResponse(t *testing.T) {
+	rt := testValidator(t)
+	json := http.Header{"Content-Type": {"application/json; charset=utf-8"}}
+
+	tests := []struct {
+		name     string
+		status   int
+		header   http.Header
+		body     string
+		problems int
+	}{
+		{"valid", 200, json, `{"id": 1}`, 0},
+		{"missing field", 200, json, `{}`, 1},
+		{"wrong content type", 200, http.Header{"Content-Type": {"text/plain"}}, `{"id": 1}`, 1},
+		{"referenced response without a body", 404, json, `{"error": "not found"}`, 0},
+		{"undocumented status", 409, json, `{}`, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := rt.CheckResponse(tt.status, tt.header, []byte(tt.body))
+			if len(problems) != tt.problems {
+				t.Fatalf("expected %d problems, got %q", tt.problems, problems)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+// Package outbox relays domain events from the transactional outbox table to external
+// sinks. Events are written by the data models in the same transaction as the change they
+// describe; the Relay polls for unpublished events and hands them to every sink in order.
+//
+// Delivery is at least once. If any sink fails, the whole batch stays pending and is
+// offered to every sink again on the next poll, so sinks must tolerate duplicates; the
+// event id is stable and can be used to deduplicate. Published events are kept for
+// Retention and then pruned.
+//
+// A Tail follows the same events with a cursor of its own, for in-process sinks that must
+// not wait on the relay's.
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/jsonlog"
+)
+
+// Sink receives published events.
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, event *data.OutboxEvent) error
+}
+
+// DefaultLease is how long a batch stays claimed when Relay.Lease is not set. It should
+// comfortably exceed the time the sinks take to publish a batch.
+const DefaultLease = time.Minute
+
+// Relay moves events from the outbox to the sinks.
+type Relay struct {
+	Store     data.OutboxStore
+	Sinks     []Sink
+	BatchSize int
+	Interval  time.Duration
+	Logger    *jsonlog.Logger
+
+	// Lease is how long a claimed batch is reserved for this relay; DefaultLease if zero.
+	Lease time.Duration
+
+	// Retention is how long published events are kept before Run prunes them. Zero
+	// keeps them forever.
+	Retention time.Duration
+
+	prunedAt time.Time
+}
+
+// Run polls the outbox every Interval until stop is closed. It then drains whatever is
+// still pending, giving up after drainTimeout, so events written by requests that finished
+// during shutdown are not left behind.
+func (r *Relay) Run(stop <-chan struct{}, drainTimeout time.Duration) {
+	ticker := time.NewTicker(positive(r.Interval, time.Second))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+			defer cancel()
+
+			if _, err := r.Flush(ctx); err != nil {
+				r.Logger.PrintError(err, map[string]string{"component": "outbox relay", "stage": "drain"})
+			}
+			return
+
+		case <-ticker.C:
+			if _, err := r.Flush(context.Background()); err != nil {
+				r.Logger.PrintError(err, map[string]string{"component": "outbox relay"})
+			}
+
+			if err := r.prune(context.Background()); err != nil {
+				r.Logger.PrintError(err, map[string]string{"component": "outbox relay", "stage": "prune"})
+			}
+		}
+	}
+}
+
+// prune deletes events published more than Retention ago. It runs at most once every
+// tenth of Retention, so a short poll interval doesn't turn into a DELETE per tick.
+func (r *Relay) prune(ctx context.Context) error {
+	if r.Retention <= 0 || time.Since(r.prunedAt) < r.Retention/10 {
+		return nil
+	}
+
+	n, err := r.Store.Prune(ctx, r.Retention)
+	if err != nil {
+		return err
+	}
+	r.prunedAt = time.Now()
+
+	if n > 0 {
+		r.Logger.PrintInfo("pruned published outbox events", map[string]string{
+			"events":    strconv.FormatInt(n, 10),
+			"retention": r.Retention.String(),
+		})
+	}
+
+	return nil
+}
+
+// Flush publishes pending events in batches until none are left or a batch fails. It
+// returns the number of events published.
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	total := 0
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		batchSize := positive(r.BatchSize, 100)
+
+		n, err := r.Store.Process(ctx, batchSize, positive(r.Lease, DefaultLease), func(events []*data.OutboxEvent) error {
+			return r.publish(ctx, events)
+		})
+		total += n
+
+		if err != nil || n < batchSize {
+			return total, err
+		}
+	}
+}
+
+func (r *Relay) publish(ctx context.Context, events []*data.OutboxEvent) error {
+	for _, event := range events {
+		for _, sink := range r.Sinks {
+			if err := sink.Send(ctx, event); err != nil {
+				return fmt.Errorf("outbox: %s sink, event %d: %w", sink.Name(), event.ID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// LogSink writes each event to the application log.
+type LogSink struct {
+	Logger *jsonlog.Logger
+}
+
+func (s LogSink) Name() string { return "log" }
+
+func (s LogSink) Send(_ context.Context, event *data.OutboxEvent) error {
+	s.Logger.PrintInfo("domain event", map[string]string{
+		"event_id":     strconv.FormatInt(event.ID, 10),
+		"type":         event.Type,
+		"aggregate_id": strconv.FormatInt(event.AggregateID, 10),
+	})
+	return nil
+}
+
+// Broker is the publishing side of a message broker client. *nats.Conn satisfies it
+// directly, and a Kafka producer can be adapted with a few lines.
+type Broker interface {
+	Publish(subject string, data []byte) error
+}
+
+// BrokerSink publishes each event as JSON to the subject Prefix + event type, for example
+// "students_api.student.created".
+type BrokerSink struct {
+	Broker Broker
+	Prefix string
+}
+
+func (s BrokerSink) Name() string { return "nats" }
+
+func (s BrokerSink) Send(_ context.Context, event *data.OutboxEvent) error {
+	body, err := encode(event)
+	if err != nil {
+		return err
+	}
+
+	return s.Broker.Publish(s.Prefix+event.Type, body)
+}
@@ -0,0 +1,317 @@
+package outbox
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/jsonlog"
+)
+
+// memoryOutbox mimics OutboxModel.Process: events only move to published when fn
+// succeeds.
+type memoryOutbox struct {
+	mu        sync.Mutex
+	pending   []*data.OutboxEvent
+	published []*data.OutboxEvent
+	pruned    []time.Duration
+	lastID    int64
+
+	// open holds the ids taken by transactions that have not committed or rolled back.
+	open map[int64]bool
+}
+
+// begin takes an id for a transaction that stays open until commit or rollback.
+func (o *memoryOutbox) begin(id int64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.open == nil {
+		o.open = map[int64]bool{}
+	}
+	o.open[id] = true
+	o.lastID = max(o.lastID, id)
+}
+
+func (o *memoryOutbox) rollback(id int64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	delete(o.open, id)
+}
+
+func (o *memoryOutbox) add(n int) {
+	ids := make([]int64, n)
+	for i := range ids {
+		ids[i] = o.lastID + int64(i) + 1
+	}
+	o.commit(ids...)
+}
+
+// commit adds events with the given ids, which need not follow on from earlier ones, as a
+// transaction that took its ids early and committed late would.
+func (o *memoryOutbox) commit(ids ...int64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, id := range ids {
+		delete(o.open, id)
+		o.pending = append(o.pending, &data.OutboxEvent{ID: id, Type: data.EventStudentCreated, AggregateID: id})
+		o.lastID = max(o.lastID, id)
+	}
+	slices.SortFunc(o.pending, func(a, b *data.OutboxEvent) int { return cmp.Compare(a.ID, b.ID) })
+}
+
+func (o *memoryOutbox) Process(_ context.Context, limit int, _ time.Duration, fn func([]*data.OutboxEvent) error) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	batch := o.pending[:min(limit, len(o.pending))]
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	if err := fn(batch); err != nil {
+		return 0, err
+	}
+
+	o.published = append(o.published, batch...)
+	o.pending = o.pending[len(batch):]
+	return len(batch), nil
+}
+
+func (o *memoryOutbox) After(_ context.Context, afterID int64, limit int) ([]*data.OutboxEvent, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	all := slices.Concat(o.published, o.pending)
+	slices.SortFunc(all, func(a, b *data.OutboxEvent) int { return cmp.Compare(a.ID, b.ID) })
+
+	events := []*data.OutboxEvent{}
+	for _, e := range all {
+		if e.ID > afterID && len(events) < limit {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (o *memoryOutbox) LatestID(context.Context) (int64, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.lastID, nil
+}
+
+// Snapshot lists the open transactions' ids.
+func (o *memoryOutbox) Snapshot(context.Context) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var ids []string
+	for id := range o.open {
+		ids = append(ids, strconv.FormatInt(id, 10))
+	}
+	return "open:" + strings.Join(ids, ","), nil
+}
+
+func (o *memoryOutbox) SnapshotDone(_ context.Context, snapshot string) (bool, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, s := range strings.Split(strings.TrimPrefix(snapshot, "open:"), ",") {
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err == nil && o.open[id] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (o *memoryOutbox) Prune(_ context.Context, olderThan time.Duration) (int64, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	n := int64(len(o.published))
+	o.published = nil
+	o.pruned = append(o.pruned, olderThan)
+	return n, nil
+}
+
+type recordingSink struct {
+	mu   sync.Mutex
+	ids  []int64
+	fail bool
+}
+
+func (s *recordingSink) Name() string { return "recording" }
+
+func (s *recordingSink) Send(_ context.Context, event *data.OutboxEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fail {
+		return errors.New("sink down")
+	}
+	s.ids = append(s.ids, event.ID)
+	return nil
+}
+
+func newTestRelay(store data.OutboxStore, sinks ...Sink) *Relay {
+	return &Relay{
+		Store:     store,
+		Sinks:     sinks,
+		BatchSize: 2,
+		Interval:  time.Hour,
+		Logger:    jsonlog.NewLogger(io.Discard, jsonlog.LevelOff),
+	}
+}
+
+func TestFlushPublishesEveryBatch(t *testing.T) {
+	store := &memoryOutbox{}
+	store.add(5)
+
+	a, b := &recordingSink{}, &recordingSink{}
+	relay := newTestRelay(store, a, b)
+
+	n, err := relay.Flush(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 5 {
+		t.Fatalf("expected 5 events published, got %d", n)
+	}
+
+	for _, sink := range []*recordingSink{a, b} {
+		if len(sink.ids) != 5 || sink.ids[0] != 1 || sink.ids[4] != 5 {
+			t.Fatalf("expected events 1..5 in order, got %v", sink.ids)
+		}
+	}
+}
+
+func TestFlushKeepsEventsWhenASinkFails(t *testing.T) {
+	store := &memoryOutbox{}
+	store.add(3)
+
+	sink := &recordingSink{fail: true}
+	relay := newTestRelay(store, sink)
+
+	if _, err := relay.Flush(context.Background()); err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(store.pending) != 3 {
+		t.Fatalf("expected 3 events still pending, got %d", len(store.pending))
+	}
+
+	sink.fail = false
+
+	if n, err := relay.Flush(context.Background()); err != nil || n != 3 {
+		t.Fatalf("expected the retry to publish 3 events, got %d, %v", n, err)
+	}
+}
+
+func TestRunDrainsOnStop(t *testing.T) {
+	store := &memoryOutbox{}
+	sink := &recordingSink{}
+	relay := newTestRelay(store, sink)
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		relay.Run(stop, time.Second)
+		close(done)
+	}()
+
+	store.add(3)
+	close(stop)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("relay did not stop")
+	}
+
+	if len(sink.ids) != 3 {
+		t.Fatalf("expected pending events to be drained on stop, got %v", sink.ids)
+	}
+}
+
+func TestPruneHonoursRetention(t *testing.T) {
+	store := &memoryOutbox{}
+	store.add(2)
+
+	relay := newTestRelay(store, &recordingSink{})
+
+	if _, err := relay.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a retention nothing is pruned.
+	if err := relay.prune(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.pruned) != 0 {
+		t.Fatalf("expected no prune without a retention, got %v", store.pruned)
+	}
+
+	relay.Retention = time.Hour
+
+	for i := 0; i < 2; i++ {
+		if err := relay.prune(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(store.pruned) != 1 || store.pruned[0] != time.Hour {
+		t.Fatalf("expected a single prune of events older than 1h, got %v", store.pruned)
+	}
+	if len(store.published) != 0 {
+		t.Fatalf("expected published events to be pruned, got %d", len(store.published))
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var got data.OutboxEvent
+	var header http.Header
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		_ = json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	sink := WebhookSink{URL: srv.URL}
+	event := &data.OutboxEvent{ID: 9, Type: data.EventStudentUpdated, AggregateID: 3, Payload: json.RawMessage(`{"id":3}`)}
+
+	if err := sink.Send(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.ID != 9 || got.Type != data.EventStudentUpdated || string(got.Payload) != `{"id":3}` {
+		t.Fatalf("unexpected body: %+v", got)
+	}
+	if header.Get("X-Event-ID") != "9" {
+		t.Fatalf("expected X-Event-ID 9, got %q", header.Get("X-Event-ID"))
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+
+	if err := (WebhookSink{URL: failing.URL}).Send(context.Background(), event); err == nil {
+		t.Fatal("expected a non-2xx response to fail")
+	}
+}
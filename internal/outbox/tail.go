@@ -0,0 +1,170 @@
+package outbox
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/jsonlog"
+)
+
+// DefaultGapGrace is the longest a Tail waits for a missing event id when Tail.Grace is
+// not set. It covers the longest transaction that writes events, a bulk import, and only
+// applies when the store can't tell whether the gap's transaction is still open.
+const DefaultGapGrace = 30 * time.Second
+
+// Tail follows committed events with a cursor of its own and hands each to Sink once, in
+// id order. Unlike a Relay it neither claims nor publishes events, so it runs next to the
+// relay without affecting it: a failing relay sink doesn't hold the tail back, and a slow
+// tail sink doesn't hold the relay back. It suits in-process, best-effort sinks such as the
+// stream hub; a Sink error is logged and the event is skipped.
+//
+// Ids are taken when a transaction writes its event rather than when it commits, so an
+// event can become visible after a higher id. When the tail finds a gap in the ids it
+// takes a snapshot of the write transactions in progress, one of which must hold the
+// missing ids, and moves past the gap once they have all ended, since the missing ids
+// were then rolled back. A gap that is still open after Grace is skipped regardless, so a
+// transaction left open doesn't hold the tail back for good.
+type Tail struct {
+	Store     data.OutboxStore
+	Sink      Sink
+	BatchSize int
+	Interval  time.Duration
+	Grace     time.Duration
+	Logger    *jsonlog.Logger
+
+	cursor      int64
+	gapSince    time.Time
+	gapSnapshot string
+	now         func() time.Time
+}
+
+// Run starts after the latest event written so far and polls every Interval until stop is
+// closed.
+func (t *Tail) Run(stop <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		<-stop
+		cancel()
+	}()
+
+	cursor, err := t.Store.LatestID(ctx)
+	if err != nil && ctx.Err() == nil {
+		t.Logger.PrintError(err, map[string]string{"component": "outbox tail"})
+	}
+	t.cursor = cursor
+
+	ticker := time.NewTicker(positive(t.Interval, time.Second))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			if err := t.Poll(ctx); err != nil && ctx.Err() == nil {
+				t.Logger.PrintError(err, map[string]string{"component": "outbox tail"})
+			}
+		}
+	}
+}
+
+// Poll sends the events committed since the last poll, stopping early at a gap that may
+// still fill.
+func (t *Tail) Poll(ctx context.Context) error {
+	// This is checked before looking for events, so that an event whose transaction
+	// commits in between is found rather than skipped.
+	settled := t.gapSettled(ctx)
+
+	for {
+		events, err := t.Store.After(ctx, t.cursor, positive(t.BatchSize, 100))
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			if event.ID > t.cursor+1 && !t.skipGap(ctx, event.ID, settled) {
+				return nil
+			}
+
+			if err := t.Sink.Send(ctx, event); err != nil {
+				t.Logger.PrintError(err, map[string]string{
+					"component": "outbox tail",
+					"sink":      t.Sink.Name(),
+					"event_id":  strconv.FormatInt(event.ID, 10),
+				})
+			}
+
+			t.cursor = event.ID
+			t.gapSince = time.Time{}
+			t.gapSnapshot = ""
+			settled = false
+		}
+
+		if len(events) < positive(t.BatchSize, 100) {
+			return nil
+		}
+	}
+}
+
+// gapSettled reports whether every write transaction that was open when the current gap
+// was found has ended.
+func (t *Tail) gapSettled(ctx context.Context) bool {
+	if t.gapSnapshot == "" {
+		return false
+	}
+
+	done, err := t.Store.SnapshotDone(ctx, t.gapSnapshot)
+	if err != nil {
+		t.Logger.PrintError(err, map[string]string{"component": "outbox tail"})
+		return false
+	}
+
+	return done
+}
+
+// skipGap reports whether the tail should move past the ids missing between the cursor
+// and next: either the transactions that could fill them had ended when settled was
+// checked, or they have been missing for longer than the grace period.
+func (t *Tail) skipGap(ctx context.Context, next int64, settled bool) bool {
+	now := time.Now()
+	if t.now != nil {
+		now = t.now()
+	}
+
+	if t.gapSince.IsZero() {
+		t.gapSince = now
+
+		snapshot, err := t.Store.Snapshot(ctx)
+		if err != nil {
+			t.Logger.PrintError(err, map[string]string{"component": "outbox tail"})
+		}
+		t.gapSnapshot = snapshot
+
+		return false
+	}
+
+	if !settled && now.Sub(t.gapSince) < positive(t.Grace, DefaultGapGrace) {
+		return false
+	}
+
+	t.Logger.PrintInfo("skipped missing outbox events", map[string]string{
+		"component": "outbox tail",
+		"from_id":   strconv.FormatInt(t.cursor+1, 10),
+		"to_id":     strconv.FormatInt(next-1, 10),
+	})
+
+	return true
+}
+
+// positive returns v, or def when v is not positive.
+func positive[T int | time.Duration](v, def T) T {
+	if v <= 0 {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,97 @@
+package outbox
+
+import (
+	"context"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/jsonlog"
+)
+
+func newTestTail(store *memoryOutbox, sink Sink) (*Tail, *time.Time) {
+	now := time.Unix(1700000000, 0)
+
+	return &Tail{
+		Store:     store,
+		Sink:      sink,
+		BatchSize: 2,
+		Grace:     10 * time.Second,
+		Logger:    jsonlog.NewLogger(io.Discard, jsonlog.LevelOff),
+		now:       func() time.Time { return now },
+	}, &now
+}
+
+func TestTailIsNotHeldBackByRelaySinks(t *testing.T) {
+	store := &memoryOutbox{}
+	store.add(3)
+
+	if _, err := newTestRelay(store, &recordingSink{fail: true}).Flush(context.Background()); err == nil {
+		t.Fatal("expected the relay to fail")
+	}
+
+	streams := &recordingSink{}
+	tail, _ := newTestTail(store, streams)
+
+	if err := tail.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(streams.ids, []int64{1, 2, 3}) {
+		t.Fatalf("expected events 1..3 while the relay is failing, got %v", streams.ids)
+	}
+
+	// Nothing is sent twice.
+	if err := tail.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(streams.ids) != 3 {
+		t.Fatalf("expected no resends, got %v", streams.ids)
+	}
+}
+
+func TestTailWaitsForGaps(t *testing.T) {
+	store := &memoryOutbox{}
+	streams := &recordingSink{}
+	tail, now := newTestTail(store, streams)
+
+	poll := func(want ...int64) {
+		t.Helper()
+
+		if err := tail.Poll(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(streams.ids, want) {
+			t.Fatalf("expected %v, got %v", want, streams.ids)
+		}
+	}
+
+	// Event 2's transaction is still open when 1 and 3 commit.
+	store.begin(2)
+	store.commit(1, 3)
+	poll(1)
+	poll(1)
+
+	store.commit(2)
+	poll(1, 2, 3)
+
+	// Event 4 is rolled back. 5 is sent as soon as 4's transaction has ended, without
+	// waiting for the grace period.
+	store.begin(4)
+	store.commit(5)
+	poll(1, 2, 3)
+
+	store.rollback(4)
+	poll(1, 2, 3, 5)
+
+	// A transaction left open is only waited for until the grace period ends.
+	store.begin(6)
+	store.commit(7)
+	poll(1, 2, 3, 5)
+
+	*now = now.Add(5 * time.Second)
+	poll(1, 2, 3, 5)
+
+	*now = now.Add(6 * time.Second)
+	poll(1, 2, 3, 5, 7)
+}
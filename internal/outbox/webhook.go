@@ -0,0 +1,63 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+)
+
+// WebhookSink POSTs each event as JSON to a fixed URL. Any response other than 2xx is
+// treated as a failure and the event is retried on the next poll. The X-Event-ID header
+// carries the outbox id so receivers can drop duplicates.
+type WebhookSink struct {
+	URL    string
+	Client *http.Client
+}
+
+func (s WebhookSink) Name() string { return "webhook" }
+
+func (s WebhookSink) Send(ctx context.Context, event *data.OutboxEvent) error {
+	body, err := encode(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", strconv.FormatInt(event.ID, 10))
+	req.Header.Set("X-Event-Type", event.Type)
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+
+	return nil
+}
+
+// encode is the JSON envelope shared by the webhook and broker sinks.
+func encode(event *data.OutboxEvent) ([]byte, error) {
+	return json.Marshal(event)
+}
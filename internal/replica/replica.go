@@ -0,0 +1,108 @@
+// Package replica keeps track of a set of read replica connection pools. Reads are spread
+// across the healthy replicas in round-robin order, and a replica that fails its health
+// check is skipped until it passes again.
+package replica
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+	"time"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/jsonlog"
+)
+
+// Pool is a fixed set of replicas. It is safe for concurrent use.
+type Pool struct {
+	members []*member
+	next    atomic.Uint64
+
+	// Logger receives an entry whenever a replica changes health. It may be nil.
+	Logger *jsonlog.Logger
+}
+
+type member struct {
+	name    string
+	db      *sql.DB
+	healthy atomic.Bool
+}
+
+// New returns a Pool for dbs. names label the replicas in logs and metrics and must be the
+// same length as dbs. Every replica starts out healthy.
+func New(names []string, dbs []*sql.DB) *Pool {
+	p := &Pool{}
+
+	for i, db := range dbs {
+		m := &member{name: names[i], db: db}
+		m.healthy.Store(true)
+		p.members = append(p.members, m)
+	}
+
+	return p
+}
+
+// Len returns the number of replicas, healthy or not.
+func (p *Pool) Len() int {
+	return len(p.members)
+}
+
+// Name returns the label of replica i.
+func (p *Pool) Name(i int) string {
+	return p.members[i].name
+}
+
+// Healthy reports whether replica i passed its last health check.
+func (p *Pool) Healthy(i int) bool {
+	return p.members[i].healthy.Load()
+}
+
+// Pick returns the index of the next healthy replica, or false when none are healthy and
+// the caller should read from the primary instead.
+func (p *Pool) Pick() (int, bool) {
+	n := len(p.members)
+	if n == 0 {
+		return 0, false
+	}
+
+	start := p.next.Add(1) - 1
+
+	for i := 0; i < n; i++ {
+		idx := int((start + uint64(i)) % uint64(n))
+		if p.members[idx].healthy.Load() {
+			return idx, true
+		}
+	}
+
+	return 0, false
+}
+
+// Check pings every replica, each with its own timeout, and records the result.
+func (p *Pool) Check(ctx context.Context, timeout time.Duration) {
+	for _, m := range p.members {
+		pingCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := m.db.PingContext(pingCtx)
+		cancel()
+
+		healthy := err == nil
+		if m.healthy.Swap(healthy) == healthy || p.Logger == nil {
+			continue
+		}
+
+		if healthy {
+			p.Logger.PrintInfo("replica healthy", map[string]string{"replica": m.name})
+		} else {
+			p.Logger.PrintError(err, map[string]string{"replica": m.name, "state": "unhealthy"})
+		}
+	}
+}
+
+// Close closes every replica pool and returns the first error.
+func (p *Pool) Close() error {
+	var first error
+	for _, m := range p.members {
+		if err := m.db.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,47 @@
+package replica
+
+import "testing"
+
+func newTestPool(healthy ...bool) *Pool {
+	p := &Pool{}
+	for i, h := range healthy {
+		m := &member{name: string(rune('a' + i))}
+		m.healthy.Store(h)
+		p.members = append(p.members, m)
+	}
+	return p
+}
+
+func TestPick(t *testing.T) {
+	tests := []struct {
+		name    string
+		healthy []bool
+		want    []int
+		ok      bool
+	}{
+		{name: "no replicas", healthy: nil, ok: false},
+		{name: "round robin", healthy: []bool{true, true, true}, want: []int{0, 1, 2, 0, 1, 2}, ok: true},
+		{name: "skips unhealthy", healthy: []bool{true, false, true}, want: []int{0, 2, 2, 0}, ok: true},
+		{name: "all unhealthy", healthy: []bool{false, false}, ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTestPool(tt.healthy...)
+
+			if !tt.ok {
+				if _, ok := p.Pick(); ok {
+					t.Fatal("expected no replica to be picked")
+				}
+				return
+			}
+
+			for i, want := range tt.want {
+				got, ok := p.Pick()
+				if !ok || got != want {
+					t.Fatalf("pick %d: got (%d, %v), want %d", i, got, ok, want)
+				}
+			}
+		})
+	}
+}
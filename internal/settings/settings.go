@@ -0,0 +1,436 @@
+// Package settings fills a configuration struct from layered sources: a YAML or TOML
+// file, then environment variables. Callers apply their own defaults first and
+// command-line flags last.
+//
+// Each field is named by its yaml tag, and nested structs make dotted keys such as
+// "db.max_open_conns". Supported field types are strings, bools, integers, floats,
+// time.Duration (written like "5s"), string slices and encoding.TextUnmarshaler.
+//
+// A field tagged secret:"true" is masked by Marshal.
+package settings
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/pelletier/go-toml/v2"
+)
+
+var (
+	durationType        = reflect.TypeFor[time.Duration]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// LoadFile decodes the file at path onto dst, which must point to a struct. Files
+// ending in .toml are TOML and all others YAML. Only the settings present in the file
+// are changed. Unknown settings and values of the wrong type are errors, and all of
+// them are reported together.
+func LoadFile(path string, dst any) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]any{}
+
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		err = toml.Unmarshal(b, &values)
+	} else {
+		err = yaml.Unmarshal(b, &values)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	var errs []error
+	applyMap(structValue(dst), "", values, &errs)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%s: %w", path, errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// LoadEnv sets every field whose environment variable is set. A field's variable is
+// prefix followed by its key in upper case with dots as underscores, such as
+// STUDENTS_API_DB_MAX_OPEN_CONNS. A field may also name another variable in an env
+// tag, which is read when the prefixed one is not set. String slices are split on
+// whitespace.
+func LoadEnv(dst any, prefix string, lookup func(string) (string, bool)) error {
+	var errs []error
+
+	walk(structValue(dst), "", func(key string, field reflect.StructField, v reflect.Value) {
+		name := EnvName(prefix, key)
+
+		s, ok := lookup(name)
+		if !ok || s == "" {
+			if alias := field.Tag.Get("env"); alias != "" {
+				name = alias
+				s, ok = lookup(name)
+			}
+		}
+
+		if !ok || s == "" {
+			return
+		}
+
+		if err := setString(v, s); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	})
+
+	return errors.Join(errs...)
+}
+
+// EnvName returns the environment variable for key.
+func EnvName(prefix, key string) string {
+	return prefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// Marshal encodes src as YAML in field order, with secrets masked. URLs keep everything
+// but their password; other secrets are replaced entirely.
+func Marshal(src any) ([]byte, error) {
+	return yaml.Marshal(toMapSlice(structValue(src)))
+}
+
+// Changed returns the keys of the settings that differ between a and b, which must be
+// structs of the same type. Empty and nil lists are treated as equal.
+func Changed(a, b any) []string {
+	av, bv := structValue(a), structValue(b)
+	if av.Type() != bv.Type() {
+		panic(fmt.Sprintf("settings: cannot compare %s with %s", av.Type(), bv.Type()))
+	}
+
+	others := map[string]reflect.Value{}
+	walk(bv, "", func(key string, _ reflect.StructField, v reflect.Value) {
+		others[key] = v
+	})
+
+	var keys []string
+
+	walk(av, "", func(key string, _ reflect.StructField, v reflect.Value) {
+		w := others[key]
+
+		if v.Kind() == reflect.Slice && v.Len() == 0 && w.Len() == 0 {
+			return
+		}
+
+		if !reflect.DeepEqual(v.Interface(), w.Interface()) {
+			keys = append(keys, key)
+		}
+	})
+
+	return keys
+}
+
+func structValue(v any) reflect.Value {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("settings: %T is not a struct", v))
+	}
+	return rv
+}
+
+// name returns the key a struct field is known by, or "" if it is skipped.
+func name(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+
+	tag, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if tag == "-" {
+		return ""
+	}
+	if tag == "" {
+		return strings.ToLower(field.Name)
+	}
+	return tag
+}
+
+// isLeaf reports whether a value of type t is a single setting rather than a group.
+func isLeaf(t reflect.Type) bool {
+	return t.Kind() != reflect.Struct || reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// walk calls fn for every setting in v, depth first in field order.
+func walk(v reflect.Value, prefix string, fn func(key string, field reflect.StructField, v reflect.Value)) {
+	t := v.Type()
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+
+		n := name(field)
+		if n == "" {
+			continue
+		}
+
+		key := prefix + n
+
+		if isLeaf(field.Type) {
+			fn(key, field, v.Field(i))
+		} else {
+			walk(v.Field(i), key+".", fn)
+		}
+	}
+}
+
+func applyMap(v reflect.Value, prefix string, values map[string]any, errs *[]error) {
+	t := v.Type()
+	fields := map[string]int{}
+
+	for i := range t.NumField() {
+		if n := name(t.Field(i)); n != "" {
+			fields[n] = i
+		}
+	}
+
+	for k, value := range values {
+		key := prefix + k
+
+		i, ok := fields[k]
+		if !ok {
+			*errs = append(*errs, fmt.Errorf("%s: unknown setting", key))
+			continue
+		}
+
+		field := v.Field(i)
+
+		if !isLeaf(field.Type()) {
+			nested, ok := value.(map[string]any)
+			if !ok {
+				*errs = append(*errs, fmt.Errorf("%s: must be a group of settings", key))
+				continue
+			}
+			applyMap(field, key+".", nested, errs)
+			continue
+		}
+
+		if err := set(field, value); err != nil {
+			*errs = append(*errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+}
+
+// set assigns a value decoded from a file to v.
+func set(v reflect.Value, value any) error {
+	if s, ok := value.(string); ok {
+		return setString(v, s)
+	}
+
+	switch {
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		items, ok := value.([]any)
+		if !ok {
+			return errors.New("must be a list of strings")
+		}
+
+		out := make([]string, len(items))
+		for i, item := range items {
+			s, ok := item.(string)
+			if !ok {
+				return errors.New("must be a list of strings")
+			}
+			out[i] = s
+		}
+
+		v.Set(reflect.ValueOf(out).Convert(v.Type()))
+		return nil
+
+	case v.Kind() == reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return errors.New("must be true or false")
+		}
+		v.SetBool(b)
+		return nil
+
+	case isInt(v) && v.Type() != durationType:
+		n, ok := integer(value)
+		if !ok || v.OverflowInt(n) {
+			return errors.New("must be an integer")
+		}
+		v.SetInt(n)
+		return nil
+
+	case v.Kind() == reflect.Float64 || v.Kind() == reflect.Float32:
+		switch n := value.(type) {
+		case float64:
+			v.SetFloat(n)
+			return nil
+		case int64:
+			v.SetFloat(float64(n))
+			return nil
+		case uint64:
+			v.SetFloat(float64(n))
+			return nil
+		}
+		return errors.New("must be a number")
+	}
+
+	return fmt.Errorf("must be %s", describe(v))
+}
+
+// setString parses s, from a file or the environment, into v.
+func setString(v reflect.Value, s string) error {
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return errors.New("must be a duration such as 500ms, 5s or 1m")
+		}
+		v.SetInt(int64(d))
+
+	case v.Kind() == reflect.String:
+		v.SetString(s)
+
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		v.Set(reflect.ValueOf(strings.Fields(s)).Convert(v.Type()))
+
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return errors.New("must be true or false")
+		}
+		v.SetBool(b)
+
+	case isInt(v):
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || v.OverflowInt(n) {
+			return errors.New("must be an integer")
+		}
+		v.SetInt(n)
+
+	case v.Kind() == reflect.Float64 || v.Kind() == reflect.Float32:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return errors.New("must be a number")
+		}
+		v.SetFloat(f)
+
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+
+	return nil
+}
+
+func isInt(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func integer(value any) (int64, bool) {
+	switch n := value.(type) {
+	case int64:
+		return n, true
+	case uint64:
+		return int64(n), n <= 1<<63-1
+	case float64:
+		return int64(n), n == float64(int64(n))
+	}
+	return 0, false
+}
+
+func describe(v reflect.Value) string {
+	switch {
+	case v.Type() == durationType:
+		return "a duration such as 500ms, 5s or 1m"
+	case v.Kind() == reflect.String:
+		return "a string"
+	default:
+		return "a " + v.Type().String()
+	}
+}
+
+func toMapSlice(v reflect.Value) yaml.MapSlice {
+	t := v.Type()
+	out := yaml.MapSlice{}
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+
+		n := name(field)
+		if n == "" {
+			continue
+		}
+
+		fv := v.Field(i)
+
+		if !isLeaf(field.Type) {
+			out = append(out, yaml.MapItem{Key: n, Value: toMapSlice(fv)})
+			continue
+		}
+
+		out = append(out, yaml.MapItem{Key: n, Value: plain(fv, field.Tag.Get("secret") == "true")})
+	}
+
+	return out
+}
+
+// plain converts a setting to a value that marshals the way it is written in a file.
+func plain(v reflect.Value, secret bool) any {
+	switch {
+	case v.Type().Implements(textMarshalerType):
+		b, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return fmt.Sprintf("<%v>", err)
+		}
+		return redact(string(b), secret)
+
+	case v.Type() == durationType:
+		return time.Duration(v.Int()).String()
+
+	case v.Kind() == reflect.String:
+		return redact(v.String(), secret)
+
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		out := make([]string, v.Len())
+		for i := range out {
+			out[i] = redact(v.Index(i).String(), secret)
+		}
+		return out
+	}
+
+	return v.Interface()
+}
+
+// Redacted is what a secret is shown as.
+const Redacted = "REDACTED"
+
+func redact(s string, secret bool) string {
+	if !secret || s == "" {
+		return s
+	}
+
+	if u, err := url.Parse(s); err == nil && u.Scheme != "" && u.Host != "" {
+		if _, hasPassword := u.User.Password(); hasPassword {
+			return u.Redacted()
+		}
+		if u.User == nil && u.RawQuery == "" {
+			return s
+		}
+	}
+
+	return Redacted
+}
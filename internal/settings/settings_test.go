@@ -0,0 +1,234 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type level int
+
+func (l *level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return os.ErrInvalid
+	}
+	return nil
+}
+
+type testConfig struct {
+	Name    string        `yaml:"name" env:"LEGACY_NAME"`
+	Debug   bool          `yaml:"debug"`
+	Workers int           `yaml:"workers"`
+	Ratio   float64       `yaml:"ratio"`
+	Timeout time.Duration `yaml:"timeout"`
+	Level   level         `yaml:"level"`
+	DB      struct {
+		DSN      string   `yaml:"dsn" secret:"true"`
+		Replicas []string `yaml:"replicas" secret:"true"`
+		Token    string   `yaml:"token" secret:"true"`
+	} `yaml:"db"`
+	Tags []string `yaml:"tags"`
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	files := map[string]string{
+		"config.yaml": `
+name: api
+debug: true
+workers: 8
+ratio: 2
+timeout: 1m30s
+level: high
+db:
+  dsn: postgres://u:p@db/app
+  replicas: [postgres://u:p@r1/app]
+tags: [a, b]
+`,
+		"config.toml": `
+name = "api"
+debug = true
+workers = 8
+ratio = 2
+timeout = "1m30s"
+level = "high"
+tags = ["a", "b"]
+
+[db]
+dsn = "postgres://u:p@db/app"
+replicas = ["postgres://u:p@r1/app"]
+`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			cfg := testConfig{Name: "default", Workers: 1}
+			if err := LoadFile(writeFile(t, name, content), &cfg); err != nil {
+				t.Fatal(err)
+			}
+
+			var want testConfig
+			want.Name = "api"
+			want.Debug = true
+			want.Workers = 8
+			want.Ratio = 2
+			want.Timeout = 90 * time.Second
+			want.Level = 2
+			want.DB.DSN = "postgres://u:p@db/app"
+			want.DB.Replicas = []string{"postgres://u:p@r1/app"}
+			want.Tags = []string{"a", "b"}
+
+			if !reflect.DeepEqual(cfg, want) {
+				t.Errorf("got %+v, want %+v", cfg, want)
+			}
+		})
+	}
+}
+
+func TestLoadFileKeepsUnsetValues(t *testing.T) {
+	cfg := testConfig{Name: "default", Workers: 3}
+	if err := LoadFile(writeFile(t, "c.yaml", "debug: true\n"), &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Name != "default" || cfg.Workers != 3 || !cfg.Debug {
+		t.Errorf("got %+v", cfg)
+	}
+}
+
+func TestLoadFileReportsEveryError(t *testing.T) {
+	path := writeFile(t, "c.yaml", `
+workers: many
+timeout: 5
+level: medium
+colour: blue
+db:
+  dsn: [1]
+  port: 5432
+`)
+
+	err := LoadFile(path, &testConfig{})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	for _, key := range []string{"workers", "timeout", "level", "colour", "db.dsn", "db.port"} {
+		if !strings.Contains(err.Error(), key+":") {
+			t.Errorf("error does not mention %s:\n%v", key, err)
+		}
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	env := map[string]string{
+		"LEGACY_NAME":      "legacy",
+		"APP_WORKERS":      "4",
+		"APP_TIMEOUT":      "2s",
+		"APP_DB_REPLICAS":  "r1 r2",
+		"APP_DEBUG":        "true",
+		"UNRELATED_SECRET": "x",
+	}
+	lookup := func(k string) (string, bool) {
+		v, ok := env[k]
+		return v, ok
+	}
+
+	var cfg testConfig
+	if err := LoadEnv(&cfg, "APP_", lookup); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Name != "legacy" || cfg.Workers != 4 || cfg.Timeout != 2*time.Second || !cfg.Debug {
+		t.Errorf("got %+v", cfg)
+	}
+	if !reflect.DeepEqual(cfg.DB.Replicas, []string{"r1", "r2"}) {
+		t.Errorf("got replicas %q", cfg.DB.Replicas)
+	}
+
+	// The prefixed variable wins over the legacy one.
+	env["APP_NAME"] = "new"
+	if err := LoadEnv(&cfg, "APP_", lookup); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Name != "new" {
+		t.Errorf("got name %q, want the prefixed variable", cfg.Name)
+	}
+
+	env["APP_WORKERS"] = "x"
+	env["APP_TIMEOUT"] = "5"
+	err := LoadEnv(&cfg, "APP_", lookup)
+	if err == nil || !strings.Contains(err.Error(), "APP_WORKERS") || !strings.Contains(err.Error(), "APP_TIMEOUT") {
+		t.Errorf("got %v, want errors for both variables", err)
+	}
+}
+
+func TestMarshalRedactsSecrets(t *testing.T) {
+	var cfg testConfig
+	cfg.Name = "api"
+	cfg.Timeout = 5 * time.Second
+	cfg.DB.DSN = "postgres://user:hunter2@db:5432/app?sslmode=disable"
+	cfg.DB.Replicas = []string{"postgres://user:hunter2@r1/app"}
+	cfg.DB.Token = "hunter2"
+
+	b, err := Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(b)
+
+	if strings.Contains(out, "hunter2") {
+		t.Errorf("secret leaked:\n%s", out)
+	}
+
+	for _, want := range []string{
+		"name: api",
+		"timeout: 5s",
+		"postgres://user:xxxxx@db:5432/app?sslmode=disable",
+		"token: " + Redacted,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output is missing %q:\n%s", want, out)
+		}
+	}
+
+	// Settings come out in field order, which is the order they are documented in.
+	if strings.Index(out, "name:") > strings.Index(out, "db:") {
+		t.Errorf("settings are out of order:\n%s", out)
+	}
+}
+
+func TestChanged(t *testing.T) {
+	var a, b testConfig
+	a.Tags = nil
+	b.Tags = []string{}
+
+	if keys := Changed(a, b); len(keys) != 0 {
+		t.Errorf("got %v for equal configs", keys)
+	}
+
+	b.Workers = 2
+	b.DB.Replicas = []string{"r1"}
+	b.Timeout = time.Second
+
+	want := []string{"workers", "timeout", "db.replicas"}
+	if keys := Changed(a, b); !reflect.DeepEqual(keys, want) {
+		t.Errorf("got %v, want %v", keys, want)
+	}
+}
@@ -0,0 +1,146 @@
+// Package stream fans events out to live subscribers, such as Server-Sent Events clients.
+// The Hub is an outbox sink fed by an outbox.Tail, so subscribers see committed changes in
+// outbox order, and it keeps the most recent events in a ring buffer so a client that
+// reconnects with the last id it saw can pick up where it left off.
+package stream
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+)
+
+// subscriberBuffer is how many events may queue for a subscriber before it is considered
+// too slow and disconnected. It can resume from the replay buffer when it reconnects.
+const subscriberBuffer = 64
+
+// Hub broadcasts events to subscribers.
+type Hub struct {
+	mu     sync.Mutex
+	ring   []*data.OutboxEvent
+	next   int
+	full   bool
+	subs   map[*Subscription]struct{}
+	closed bool
+
+	// evicted is the highest id pushed out of the ring buffer. Only a subscriber that
+	// last saw an earlier event can have missed one that is no longer buffered.
+	evicted int64
+}
+
+// NewHub returns a hub that keeps the last size events for replay.
+func NewHub(size int) *Hub {
+	return &Hub{
+		ring: make([]*data.OutboxEvent, size),
+		subs: make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscription receives events on C. C is closed when the subscriber falls too far behind,
+// is unsubscribed, or the hub is closed.
+type Subscription struct {
+	C <-chan *data.OutboxEvent
+
+	c chan *data.OutboxEvent
+}
+
+func (h *Hub) Name() string { return "stream" }
+
+// Send adds the event to the replay buffer and delivers it to every subscriber.
+func (h *Hub) Send(_ context.Context, event *data.OutboxEvent) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if old := h.ring[h.next]; old != nil {
+		h.evicted = max(h.evicted, old.ID)
+	}
+
+	h.ring[h.next] = event
+	h.next = (h.next + 1) % len(h.ring)
+	if h.next == 0 {
+		h.full = true
+	}
+
+	for sub := range h.subs {
+		select {
+		case sub.c <- event:
+		default:
+			h.drop(sub)
+		}
+	}
+
+	return nil
+}
+
+// Subscribe registers a subscriber. When lastID is positive, the buffered events after it
+// are returned for replay. complete is false when an event after lastID has already been
+// pushed out of the buffer, so the subscriber has missed a change that can no longer be
+// replayed. Gaps in the ids, left by rolled-back transactions, don't make a replay
+// incomplete, and neither does an empty buffer: the hub can't know what happened before
+// it started.
+func (h *Hub) Subscribe(lastID int64) (sub *Subscription, replay []*data.OutboxEvent, complete bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	c := make(chan *data.OutboxEvent, subscriberBuffer)
+	sub = &Subscription{C: c, c: c}
+
+	if h.closed {
+		close(c)
+		return sub, nil, true
+	}
+
+	h.subs[sub] = struct{}{}
+
+	if lastID <= 0 {
+		return sub, nil, true
+	}
+
+	complete = lastID >= h.evicted
+
+	for _, event := range h.buffered() {
+		if event.ID > lastID {
+			replay = append(replay, event)
+		}
+	}
+
+	return sub, replay, complete
+}
+
+// Unsubscribe removes the subscriber and closes its channel.
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.drop(sub)
+}
+
+// Close disconnects every subscriber and refuses new ones. Events sent afterwards are still
+// buffered but reach no one.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+
+	for sub := range h.subs {
+		h.drop(sub)
+	}
+}
+
+func (h *Hub) drop(sub *Subscription) {
+	if _, ok := h.subs[sub]; ok {
+		delete(h.subs, sub)
+		close(sub.c)
+	}
+}
+
+// buffered returns the ring buffer contents, oldest first.
+func (h *Hub) buffered() []*data.OutboxEvent {
+	if !h.full {
+		return append([]*data.OutboxEvent(nil), h.ring[:h.next]...)
+	}
+
+	return append(append([]*data.OutboxEvent(nil), h.ring[h.next:]...), h.ring[:h.next]...)
+}
@@ -0,0 +1,123 @@
+package stream
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+)
+
+func send(h *Hub, ids ...int64) {
+	for _, id := range ids {
+		_ = h.Send(context.Background(), &data.OutboxEvent{ID: id, Type: data.EventStudentUpdated})
+	}
+}
+
+func ids(events []*data.OutboxEvent) []int64 {
+	out := []int64{}
+	for _, e := range events {
+		out = append(out, e.ID)
+	}
+	return out
+}
+
+func TestSubscribeReplay(t *testing.T) {
+	h := NewHub(3)
+	send(h, 1, 2, 3, 4, 5)
+
+	tests := []struct {
+		name         string
+		lastID       int64
+		wantReplay   []int64
+		wantComplete bool
+	}{
+		{"no last id", 0, nil, true},
+		{"within buffer", 3, []int64{4, 5}, true},
+		{"just before buffer", 2, []int64{3, 4, 5}, true},
+		{"older than buffer", 1, []int64{3, 4, 5}, false},
+		{"up to date", 5, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, replay, complete := h.Subscribe(tt.lastID)
+			defer h.Unsubscribe(sub)
+
+			if got := ids(replay); len(got) != len(tt.wantReplay) || (len(got) > 0 && got[0] != tt.wantReplay[0]) {
+				t.Fatalf("replay = %v, want %v", got, tt.wantReplay)
+			}
+			if complete != tt.wantComplete {
+				t.Fatalf("complete = %v, want %v", complete, tt.wantComplete)
+			}
+		})
+	}
+}
+
+func TestSubscribeAfterRestartIsComplete(t *testing.T) {
+	h := NewHub(3)
+
+	_, replay, complete := h.Subscribe(42)
+	if len(replay) != 0 || !complete {
+		t.Fatalf("expected a complete, empty replay from an empty hub, got %v, %v", ids(replay), complete)
+	}
+}
+
+func TestSubscribeAcrossGapIsComplete(t *testing.T) {
+	// 3 and 4 were rolled back, and 1 and 2 have been pushed out of the buffer.
+	h := NewHub(2)
+	send(h, 1, 2, 5, 6)
+
+	_, replay, complete := h.Subscribe(2)
+	if got := ids(replay); len(got) != 2 || got[0] != 5 || !complete {
+		t.Fatalf("expected a complete replay of 5 and 6, got %v, %v", got, complete)
+	}
+
+	_, _, complete = h.Subscribe(1)
+	if complete {
+		t.Fatal("expected a replay after 1 to be incomplete, since 2 is no longer buffered")
+	}
+}
+
+func TestSendDeliversAndDropsSlowSubscribers(t *testing.T) {
+	h := NewHub(10)
+
+	sub, _, _ := h.Subscribe(0)
+	send(h, 1)
+
+	if e := <-sub.C; e.ID != 1 {
+		t.Fatalf("expected event 1, got %d", e.ID)
+	}
+
+	for i := int64(0); i <= subscriberBuffer; i++ {
+		send(h, i+2)
+	}
+
+	n := 0
+	for range sub.C {
+		n++
+	}
+
+	if n != subscriberBuffer {
+		t.Fatalf("expected %d queued events before the slow subscriber was dropped, got %d", subscriberBuffer, n)
+	}
+}
+
+func TestClose(t *testing.T) {
+	h := NewHub(10)
+
+	sub, _, _ := h.Subscribe(0)
+	h.Close()
+
+	if _, ok := <-sub.C; ok {
+		t.Fatal("expected the subscription to be closed")
+	}
+
+	late, _, _ := h.Subscribe(0)
+	if _, ok := <-late.C; ok {
+		t.Fatal("expected subscriptions after Close to be closed immediately")
+	}
+
+	// Unsubscribing after Close must not close the channel twice.
+	h.Unsubscribe(sub)
+	send(h, 1)
+}
@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress is returned when a webhook URL resolves to an address the client
+// refuses to connect to.
+var ErrForbiddenAddress = errors.New("webhook: address is not publicly routable")
+
+// sharedAddressSpace is the carrier-grade NAT range, which IsPrivate does not cover.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// PublicAddress reports whether ip is a public unicast address. Loopback, private,
+// link-local (which includes cloud metadata endpoints), multicast, unspecified and
+// carrier-grade NAT addresses are not.
+func PublicAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !sharedAddressSpace.Contains(ip)
+}
+
+// PublicHost reports whether the host in rawURL may be public. An IP literal must be a
+// public address and localhost names are refused; any other name can only be checked
+// once it is resolved, which the client returned by NewClient does on every dial.
+func PublicHost(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return PublicAddress(ip)
+	}
+
+	return host != "localhost" && !strings.HasSuffix(host, ".localhost")
+}
+
+// NewClient returns the HTTP client deliveries are sent with. Unless allowPrivate is set,
+// the dialer refuses any address that is not public. The check runs on the resolved
+// address of every connection, redirects included, so a name that resolves to a public
+// address when the webhook is registered and to an internal one later is still refused.
+// Proxies from the environment are ignored, since the proxy would make the connection
+// the check can't see.
+func NewClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if !allowPrivate {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			ip, err := netip.ParseAddr(host)
+			if err != nil || !PublicAddress(ip) {
+				return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+			}
+			return nil
+		}
+		transport.Proxy = nil
+	}
+
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
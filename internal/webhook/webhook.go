@@ -0,0 +1,318 @@
+// Package webhook delivers events to the webhooks integrators register. The outbox relay
+// queues a delivery per subscribed webhook through Sink, and a Dispatcher sends due
+// deliveries, signing each request body with the webhook's secret.
+//
+// A failed delivery is retried with exponential backoff. Once MaxAttempts attempts have
+// failed it is marked dead and stays in the delivery log until it is redelivered by hand.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/jsonlog"
+)
+
+// SignatureHeader carries the request signature as "t=<unix seconds>,v1=<hex HMAC>".
+const SignatureHeader = "X-Webhook-Signature"
+
+// Sign returns the signature header value for body sent at ts. The HMAC-SHA256 covers the
+// timestamp and the body joined by a dot, so a receiver can reject replayed requests by
+// checking that t is recent.
+func Sign(secret string, ts time.Time, body []byte) string {
+	t := strconv.FormatInt(ts.Unix(), 10)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac(secret, t, body))
+}
+
+// Verify checks a signature header produced by Sign. It is what a receiver would run and
+// is exported for tests and Go integrators.
+func Verify(secret, header string, body []byte) bool {
+	var t, sig string
+
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(part, "=")
+		switch k {
+		case "t":
+			t = v
+		case "v1":
+			sig = v
+		}
+	}
+
+	got, err := hex.DecodeString(sig)
+	if t == "" || err != nil {
+		return false
+	}
+
+	return hmac.Equal(got, mac(secret, t, body))
+}
+
+func mac(secret, t string, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(t))
+	h.Write([]byte{'.'})
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+// Queue is the part of data.WebhookStore that Sink needs.
+type Queue interface {
+	Enqueue(ctx context.Context, eventID int64, eventType string, payload []byte) (int, error)
+}
+
+// Sink is an outbox sink that queues each event for the webhooks subscribed to it.
+type Sink struct {
+	Queue Queue
+}
+
+func (s Sink) Name() string { return "subscriptions" }
+
+func (s Sink) Send(ctx context.Context, event *data.OutboxEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Queue.Enqueue(ctx, event.ID, event.Type, body)
+	return err
+}
+
+// Store is the part of data.WebhookStore that Dispatcher needs.
+type Store interface {
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*data.WebhookDelivery, error)
+	RecordAttempt(ctx context.Context, delivery *data.WebhookDelivery) error
+}
+
+// Dispatcher sends due deliveries with a fixed pool of workers.
+type Dispatcher struct {
+	Store       Store
+	Client      *http.Client
+	Workers     int
+	Interval    time.Duration
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	Logger      *jsonlog.Logger
+
+	// OnAttempt, when set, is called after each attempt with the recorded delivery.
+	OnAttempt func(*data.WebhookDelivery)
+
+	now func() time.Time
+}
+
+// lease is how long a claimed delivery is hidden from other dispatchers. Deliveries are
+// only claimed for idle workers, so the lease starts when the request does and only needs
+// to cover one request: a little longer than the client timeout.
+func (d *Dispatcher) lease() time.Duration {
+	if d.Client != nil && d.Client.Timeout > 0 {
+		return d.Client.Timeout + 10*time.Second
+	}
+	return time.Minute
+}
+
+func (d *Dispatcher) clock() time.Time {
+	if d.now != nil {
+		return d.now()
+	}
+	return time.Now()
+}
+
+// Run claims and sends deliveries every Interval until stop is closed. Each poll claims
+// only as many deliveries as there are idle workers, so none waits in a queue while its
+// lease runs down. Closing stop cancels requests that are still in flight; their attempts
+// are not recorded, so they are sent again when their lease runs out.
+func (d *Dispatcher) Run(stop <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// jobs has room for every worker and idle holds a token per idle worker, so a claimed
+	// delivery is always picked up straight away.
+	workers := max(d.Workers, 1)
+
+	jobs := make(chan *data.WebhookDelivery, workers)
+	idle := make(chan struct{}, workers)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		idle <- struct{}{}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for delivery := range jobs {
+				d.attempt(ctx, delivery)
+				idle <- struct{}{}
+			}
+		}()
+	}
+
+	defer func() {
+		close(jobs)
+		wg.Wait()
+	}()
+
+	interval := d.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			cancel()
+			return
+		case <-ticker.C:
+		}
+
+		if len(idle) == 0 {
+			continue
+		}
+
+		deliveries, err := d.Store.ClaimDeliveries(ctx, len(idle), d.lease())
+		if err != nil {
+			d.Logger.PrintError(err, map[string]string{"component": "webhook dispatcher"})
+			continue
+		}
+
+		for _, delivery := range deliveries {
+			<-idle
+			jobs <- delivery
+		}
+	}
+}
+
+// attempt sends the delivery once and records the outcome.
+func (d *Dispatcher) attempt(ctx context.Context, delivery *data.WebhookDelivery) {
+	status, err := d.send(ctx, delivery)
+	if ctx.Err() != nil {
+		return
+	}
+
+	now := d.clock()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus = nil
+	delivery.LastError = nil
+
+	if status != 0 {
+		code := int32(status)
+		delivery.ResponseStatus = &code
+	}
+
+	switch {
+	case err == nil:
+		delivery.Status = data.DeliverySucceeded
+		delivery.NextAttemptAt = nil
+
+	case int(delivery.Attempts) >= d.MaxAttempts:
+		msg := err.Error()
+		delivery.LastError = &msg
+		delivery.Status = data.DeliveryDead
+		delivery.NextAttemptAt = nil
+
+		d.Logger.PrintError(err, map[string]string{
+			"component":   "webhook dispatcher",
+			"webhook_id":  strconv.FormatInt(delivery.WebhookID, 10),
+			"delivery_id": strconv.FormatInt(delivery.ID, 10),
+			"attempts":    strconv.Itoa(int(delivery.Attempts)),
+		})
+
+	default:
+		msg := err.Error()
+		delivery.LastError = &msg
+		delivery.Status = data.DeliveryPending
+		next := now.Add(Backoff(int(delivery.Attempts), d.BaseBackoff, d.MaxBackoff))
+		delivery.NextAttemptAt = &next
+	}
+
+	if err := d.Store.RecordAttempt(context.Background(), delivery); err != nil {
+		if errors.Is(err, data.ErrEditConflict) {
+			// The lease ran out and another dispatcher claimed the delivery, or it was
+			// redelivered by hand; that attempt's outcome wins.
+			d.Logger.PrintInfo("webhook delivery attempt superseded", map[string]string{
+				"component":   "webhook dispatcher",
+				"delivery_id": strconv.FormatInt(delivery.ID, 10),
+			})
+			return
+		}
+
+		d.Logger.PrintError(err, map[string]string{
+			"component":   "webhook dispatcher",
+			"delivery_id": strconv.FormatInt(delivery.ID, 10),
+		})
+		return
+	}
+
+	if d.OnAttempt != nil {
+		d.OnAttempt(delivery)
+	}
+}
+
+// send POSTs the signed payload and returns the response status, if there was a response.
+// Any status outside 2xx is an error.
+func (d *Dispatcher) send(ctx context.Context, delivery *data.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "students_api-webhooks")
+	req.Header.Set("X-Webhook-ID", strconv.FormatInt(delivery.WebhookID, 10))
+	req.Header.Set("X-Delivery-ID", strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set("X-Event-ID", strconv.FormatInt(delivery.EventID, 10))
+	req.Header.Set("X-Event-Type", delivery.EventType)
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, d.clock(), delivery.Payload))
+
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("webhook responded %s", res.Status)
+	}
+
+	return res.StatusCode, nil
+}
+
+// Backoff returns the wait after the given number of failed attempts: base, doubling per
+// attempt, capped at maxWait.
+func Backoff(attempts int, base, maxWait time.Duration) time.Duration {
+	wait := base
+
+	for i := 1; i < attempts; i++ {
+		wait *= 2
+		if wait >= maxWait {
+			return maxWait
+		}
+	}
+
+	return min(wait, maxWait)
+}
@@ -0,0 +1,339 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sai29/one2n_sre_bootcamp/internal/data"
+	"github.com/sai29/one2n_sre_bootcamp/internal/jsonlog"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	sig := Sign("whsec_test", time.Unix(1700000000, 0), body)
+
+	if sig[:13] != "t=1700000000," {
+		t.Fatalf("unexpected signature format %q", sig)
+	}
+
+	tests := []struct {
+		name   string
+		secret string
+		header string
+		body   []byte
+		want   bool
+	}{
+		{"valid", "whsec_test", sig, body, true},
+		{"wrong secret", "whsec_other", sig, body, false},
+		{"tampered body", "whsec_test", sig, []byte(`{"id":2}`), false},
+		{"tampered timestamp", "whsec_test", "t=1700000001" + sig[12:], body, false},
+		{"malformed", "whsec_test", "v1=zz", body, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.secret, tt.header, tt.body); got != tt.want {
+				t.Fatalf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 5 * time.Second},
+		{2, 10 * time.Second},
+		{4, 40 * time.Second},
+		{10, time.Minute},
+		{100, time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := Backoff(tt.attempts, 5*time.Second, time.Minute); got != tt.want {
+			t.Errorf("Backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+type memoryStore struct {
+	mu       sync.Mutex
+	due      []*data.WebhookDelivery
+	recorded []data.WebhookDelivery
+	limits   []int
+
+	// claims, when set, holds each delivery's stored claim, and RecordAttempt enforces
+	// the same claim check as WebhookModel.
+	claims map[int64]int64
+}
+
+func (s *memoryStore) ClaimDeliveries(_ context.Context, limit int, _ time.Duration) ([]*data.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.limits = append(s.limits, limit)
+
+	n := min(limit, len(s.due))
+	claimed := s.due[:n]
+	s.due = s.due[n:]
+	return claimed, nil
+}
+
+func (s *memoryStore) RecordAttempt(_ context.Context, d *data.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.claims != nil && s.claims[d.ID] != d.Claim {
+		return data.ErrEditConflict
+	}
+
+	s.recorded = append(s.recorded, *d)
+	return nil
+}
+
+func newTestDispatcher(store Store, maxAttempts int) *Dispatcher {
+	now := time.Unix(1700000000, 0)
+
+	return &Dispatcher{
+		Store:       store,
+		Client:      NewClient(5*time.Second, true),
+		Workers:     1,
+		Interval:    10 * time.Millisecond,
+		MaxAttempts: maxAttempts,
+		BaseBackoff: time.Second,
+		MaxBackoff:  time.Minute,
+		Logger:      jsonlog.NewLogger(io.Discard, jsonlog.LevelOff),
+		now:         func() time.Time { return now },
+	}
+}
+
+func TestAttempt(t *testing.T) {
+	var gotSignature bool
+
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotSignature = Verify("whsec_test", r.Header.Get(SignatureHeader), body)
+	}))
+	defer ok.Close()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	tests := []struct {
+		name        string
+		url         string
+		attempts    int32
+		wantStatus  string
+		wantNext    bool
+		wantErrText bool
+	}{
+		{"success", ok.URL, 0, data.DeliverySucceeded, false, false},
+		{"retry", failing.URL, 0, data.DeliveryPending, true, true},
+		{"dead letter", failing.URL, 2, data.DeliveryDead, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &memoryStore{}
+			d := newTestDispatcher(store, 3)
+
+			delivery := &data.WebhookDelivery{ID: 1, WebhookID: 1, EventID: 7, EventType: data.EventStudentCreated, Payload: []byte(`{"id":7}`), Attempts: tt.attempts, URL: tt.url, Secret: "whsec_test"}
+			d.attempt(context.Background(), delivery)
+
+			if len(store.recorded) != 1 {
+				t.Fatalf("expected one recorded attempt, got %d", len(store.recorded))
+			}
+
+			got := store.recorded[0]
+
+			if got.Status != tt.wantStatus {
+				t.Fatalf("expected status %s, got %s", tt.wantStatus, got.Status)
+			}
+			if got.Attempts != tt.attempts+1 {
+				t.Fatalf("expected %d attempts, got %d", tt.attempts+1, got.Attempts)
+			}
+			if (got.NextAttemptAt != nil) != tt.wantNext {
+				t.Fatalf("unexpected next attempt %v", got.NextAttemptAt)
+			}
+			if (got.LastError != nil) != tt.wantErrText {
+				t.Fatalf("unexpected last error %v", got.LastError)
+			}
+		})
+	}
+
+	if !gotSignature {
+		t.Fatal("expected the receiver to verify the signature")
+	}
+}
+
+func TestRetryIsScheduledWithBackoff(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+
+	store := &memoryStore{}
+	d := newTestDispatcher(store, 10)
+
+	delivery := &data.WebhookDelivery{ID: 1, Attempts: 2, URL: failing.URL}
+	d.attempt(context.Background(), delivery)
+
+	want := d.now().Add(4 * time.Second)
+	if got := store.recorded[0].NextAttemptAt; got == nil || !got.Equal(want) {
+		t.Fatalf("expected next attempt at %s, got %v", want, got)
+	}
+	if got := store.recorded[0].ResponseStatus; got == nil || *got != http.StatusBadGateway {
+		t.Fatalf("expected response status 502, got %v", got)
+	}
+}
+
+func TestRunStopsAndLeavesInterruptedDeliveriesUnrecorded(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	store := &memoryStore{due: []*data.WebhookDelivery{{ID: 1, URL: slow.URL}}}
+	d := newTestDispatcher(store, 3)
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		d.Run(stop)
+		close(done)
+	}()
+
+	<-started
+	close(stop)
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("dispatcher did not stop")
+	}
+
+	if len(store.recorded) != 0 {
+		t.Fatalf("expected the interrupted attempt not to be recorded, got %+v", store.recorded)
+	}
+}
+
+func TestRunClaimsOnlyForIdleWorkers(t *testing.T) {
+	release := make(chan struct{})
+	var requests sync.WaitGroup
+	requests.Add(2)
+
+	busy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Done()
+		<-release
+	}))
+	defer busy.Close()
+
+	store := &memoryStore{}
+	for i := int64(1); i <= 5; i++ {
+		store.due = append(store.due, &data.WebhookDelivery{ID: i, URL: busy.URL})
+	}
+
+	d := newTestDispatcher(store, 3)
+	d.Workers = 2
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		d.Run(stop)
+		close(done)
+	}()
+
+	// Both workers are stuck on a request; later polls must not claim anything.
+	requests.Wait()
+	time.Sleep(5 * d.Interval)
+
+	close(stop)
+	close(release)
+	<-done
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if len(store.due) != 3 {
+		t.Fatalf("expected 3 deliveries left unclaimed, got %d", len(store.due))
+	}
+	if store.limits[0] != 2 {
+		t.Fatalf("expected the first poll to claim for 2 workers, got %v", store.limits)
+	}
+}
+
+func TestAttemptSupersededByAnotherClaim(t *testing.T) {
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ok.Close()
+
+	// The delivery has been claimed again, or redelivered, since this attempt's claim.
+	store := &memoryStore{claims: map[int64]int64{1: 2}}
+	d := newTestDispatcher(store, 3)
+
+	var called bool
+	d.OnAttempt = func(*data.WebhookDelivery) { called = true }
+
+	d.attempt(context.Background(), &data.WebhookDelivery{ID: 1, URL: ok.URL, Claim: 1})
+
+	if len(store.recorded) != 0 || called {
+		t.Fatalf("expected the stale attempt to be dropped, got %+v", store.recorded)
+	}
+}
+
+func TestClientRefusesPrivateAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	_, err := NewClient(time.Second, false).Get(srv.URL)
+	if !errors.Is(err, ErrForbiddenAddress) {
+		t.Fatalf("expected ErrForbiddenAddress for a loopback server, got %v", err)
+	}
+
+	res, err := NewClient(time.Second, true).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("expected private addresses to be allowed, got %v", err)
+	}
+	_ = res.Body.Close()
+}
+
+func TestPublicHost(t *testing.T) {
+	tests := map[string]bool{
+		"https://example.com/hook":              true,
+		"https://93.184.216.34/hook":            true,
+		"http://localhost:8080/hook":            false,
+		"http://api.localhost/hook":             false,
+		"http://127.0.0.1/hook":                 false,
+		"http://10.0.0.5/hook":                  false,
+		"http://169.254.169.254/latest":         false,
+		"http://100.64.0.1/hook":                false,
+		"http://[::1]/hook":                     false,
+		"http://[fe80::1]/hook":                 false,
+		"http://[::ffff:192.168.0.1]:8080/hook": false,
+	}
+
+	for url, want := range tests {
+		if got := PublicHost(url); got != want {
+			t.Errorf("PublicHost(%q) = %v, want %v", url, got, want)
+		}
+	}
+}
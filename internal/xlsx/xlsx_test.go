@@ -0,0 +1,92 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+// readSheet unpacks a workbook written by Writer and returns the worksheet XML.
+func readSheet(t *testing.T, b []byte) string {
+	t.Helper()
+
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sheet string
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Every part must be well-formed XML.
+		dec := xml.NewDecoder(bytes.NewReader(body))
+		for {
+			if _, err := dec.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s is not well-formed: %v", f.Name, err)
+			}
+		}
+
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			sheet = string(body)
+		}
+	}
+
+	if sheet == "" {
+		t.Fatal("workbook has no worksheet")
+	}
+	return sheet
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	w, err := NewWriter(&buf, "students & staff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow("id", "name"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow(int64(7), "<Ada & Bob>"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	sheet := readSheet(t, buf.Bytes())
+
+	for _, want := range []string{
+		`<row r="1"><c r="A1" t="inlineStr"><is><t xml:space="preserve">id</t></is></c>`,
+		`<c r="A2"><v>7</v></c>`,
+		`<c r="B2" t="inlineStr"><is><t xml:space="preserve">&lt;Ada &amp; Bob&gt;</t></is></c>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet is missing %s:\n%s", want, sheet)
+		}
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	tests := map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"}
+
+	for i, want := range tests {
+		if got := columnName(i); got != want {
+			t.Errorf("columnName(%d) = %q, want %q", i, got, want)
+		}
+	}
+}
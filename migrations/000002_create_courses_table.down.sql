@@ -0,0 +1 @@
+DROP TABLE IF EXISTS courses;
\ No newline at end of file
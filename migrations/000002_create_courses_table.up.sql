@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS courses (
+id bigserial PRIMARY KEY,
+created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+code text NOT NULL,
+title text NOT NULL,
+credits integer NOT NULL,
+capacity integer NOT NULL,
+term text NOT NULL,
+version integer NOT NULL DEFAULT 1,
+CONSTRAINT courses_code_term_key UNIQUE (code, term),
+CONSTRAINT courses_credits_check CHECK (credits > 0),
+CONSTRAINT courses_capacity_check CHECK (capacity > 0)
+);
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS enrollments (
+id bigserial PRIMARY KEY,
+created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+course_id bigint NOT NULL REFERENCES courses ON DELETE CASCADE,
+student_id bigint NOT NULL REFERENCES students ON DELETE CASCADE,
+status text NOT NULL,
+CONSTRAINT enrollments_course_student_key UNIQUE (course_id, student_id),
+CONSTRAINT enrollments_status_check CHECK (status IN ('enrolled', 'waitlisted'))
+);
+
+CREATE INDEX IF NOT EXISTS enrollments_student_id_idx ON enrollments (student_id);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS grades;
\ No newline at end of file
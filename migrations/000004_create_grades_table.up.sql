@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS grades (
+id bigserial PRIMARY KEY,
+created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+enrollment_id bigint NOT NULL UNIQUE REFERENCES enrollments ON DELETE CASCADE,
+grade text NOT NULL,
+version integer NOT NULL DEFAULT 1
+);
\ No newline at end of file
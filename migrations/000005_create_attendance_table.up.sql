@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS attendance (
+id bigserial PRIMARY KEY,
+created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+course_id bigint NOT NULL REFERENCES courses ON DELETE CASCADE,
+student_id bigint NOT NULL REFERENCES students ON DELETE CASCADE,
+date date NOT NULL,
+status text NOT NULL,
+CONSTRAINT attendance_course_student_date_key UNIQUE (course_id, student_id, date),
+CONSTRAINT attendance_status_check CHECK (status IN ('present', 'absent', 'late', 'excused'))
+);
+
+CREATE INDEX IF NOT EXISTS attendance_student_id_date_idx ON attendance (student_id, date);
\ No newline at end of file
@@ -0,0 +1,9 @@
+ALTER TABLE students DROP CONSTRAINT IF EXISTS students_status_check;
+
+ALTER TABLE students
+DROP COLUMN IF EXISTS email,
+DROP COLUMN IF EXISTS phone,
+DROP COLUMN IF EXISTS date_of_birth,
+DROP COLUMN IF EXISTS address,
+DROP COLUMN IF EXISTS status,
+DROP COLUMN IF EXISTS updated_at;
\ No newline at end of file
@@ -0,0 +1,15 @@
+ALTER TABLE students
+ADD COLUMN IF NOT EXISTS email text NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS phone text NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS date_of_birth date,
+ADD COLUMN IF NOT EXISTS address text NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'active',
+ADD COLUMN IF NOT EXISTS updated_at timestamp(0) with time zone;
+
+UPDATE students SET updated_at = created_at WHERE updated_at IS NULL;
+
+ALTER TABLE students
+ALTER COLUMN updated_at SET DEFAULT NOW(),
+ALTER COLUMN updated_at SET NOT NULL;
+
+ALTER TABLE students ADD CONSTRAINT students_status_check CHECK (status IN ('active', 'graduated', 'withdrawn'));
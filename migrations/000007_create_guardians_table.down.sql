@@ -0,0 +1 @@
+DROP TABLE IF EXISTS guardians;
\ No newline at end of file
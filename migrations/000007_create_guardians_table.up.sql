@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS guardians (
+id bigserial PRIMARY KEY,
+created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+student_id bigint NOT NULL REFERENCES students ON DELETE CASCADE,
+name text NOT NULL,
+relationship text NOT NULL,
+email text NOT NULL DEFAULT '',
+phone text NOT NULL DEFAULT '',
+version integer NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS guardians_student_id_idx ON guardians (student_id);
\ No newline at end of file
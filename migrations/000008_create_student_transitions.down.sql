@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS student_transitions;
+
+ALTER TABLE students DROP CONSTRAINT IF EXISTS students_status_check;
+
+UPDATE students SET status = 'active' WHERE status IN ('applicant', 'enrolled', 'suspended');
+
+ALTER TABLE students ALTER COLUMN status SET DEFAULT 'active';
+
+ALTER TABLE students ADD CONSTRAINT students_status_check CHECK (status IN ('active', 'graduated', 'withdrawn'));
\ No newline at end of file
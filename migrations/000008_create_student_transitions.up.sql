@@ -0,0 +1,19 @@
+ALTER TABLE students DROP CONSTRAINT IF EXISTS students_status_check;
+
+UPDATE students SET status = 'enrolled' WHERE status = 'active';
+
+ALTER TABLE students ALTER COLUMN status SET DEFAULT 'applicant';
+
+ALTER TABLE students ADD CONSTRAINT students_status_check CHECK (status IN ('applicant', 'enrolled', 'suspended', 'graduated', 'withdrawn'));
+
+CREATE TABLE IF NOT EXISTS student_transitions (
+id bigserial PRIMARY KEY,
+created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+student_id bigint NOT NULL REFERENCES students ON DELETE CASCADE,
+from_status text NOT NULL,
+to_status text NOT NULL,
+reason text NOT NULL,
+actor text NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS student_transitions_student_id_idx ON student_transitions (student_id);
\ No newline at end of file
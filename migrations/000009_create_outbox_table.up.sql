@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS outbox (
+id bigserial PRIMARY KEY,
+created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+aggregate_type text NOT NULL,
+aggregate_id bigint NOT NULL,
+event_type text NOT NULL,
+payload jsonb NOT NULL,
+published_at timestamp(0) with time zone
+);
+
+CREATE INDEX IF NOT EXISTS outbox_unpublished_idx ON outbox (id) WHERE published_at IS NULL;
\ No newline at end of file
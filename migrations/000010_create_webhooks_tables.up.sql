@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+id bigserial PRIMARY KEY,
+created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+url text NOT NULL,
+event_types text[] NOT NULL,
+secret text NOT NULL,
+active boolean NOT NULL DEFAULT true,
+version integer NOT NULL DEFAULT 1
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+id bigserial PRIMARY KEY,
+created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+webhook_id bigint NOT NULL REFERENCES webhooks ON DELETE CASCADE,
+event_id bigint NOT NULL,
+event_type text NOT NULL,
+payload jsonb NOT NULL,
+status text NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'dead')),
+attempts integer NOT NULL DEFAULT 0,
+next_attempt_at timestamp(0) with time zone DEFAULT NOW(),
+last_attempt_at timestamp(0) with time zone,
+response_status integer,
+last_error text,
+UNIQUE (webhook_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS outbox_published_at_idx;
+
+ALTER TABLE outbox DROP COLUMN IF EXISTS claimed_until;
\ No newline at end of file
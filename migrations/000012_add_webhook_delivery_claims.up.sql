@@ -0,0 +1 @@
+ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS claim bigint NOT NULL DEFAULT 0;
\ No newline at end of file
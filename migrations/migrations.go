@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL migration files so the API binary can apply them
+// without a separate migrate image.
+package migrations
+
+import "embed"
+
+// FS holds the NNNNNN_name.up.sql and NNNNNN_name.down.sql files in this directory.
+//
+//go:embed *.sql
+var FS embed.FS
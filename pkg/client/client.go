@@ -0,0 +1,260 @@
+// Package client is a Go client for the Student API: students, CSV import and export,
+// and the health checks.
+//
+//	c, err := client.New("http://localhost:4000")
+//	student, err := c.GetStudent(ctx, 42)
+//	if errors.Is(err, client.ErrNotFound) {
+//		...
+//	}
+//
+// Failed calls that are safe to repeat are retried with exponential backoff; see
+// RetryPolicy.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client calls the Student API. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	retry      RetryPolicy
+	userAgent  string
+	token      string
+}
+
+// RetryPolicy controls how failed calls are retried. Reads are retried after a network
+// error, a 429, or a 502, 503 or 504. Writes (creates, updates and deletes) are only
+// retried after a 429 or 503, when the server has refused the request without acting on
+// it; after a network error, a 502 or a 504 the first attempt may have been applied, and
+// the API has no conditional writes that would make repeating it safe.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first. 1 disables
+	// retries.
+	MaxAttempts int
+
+	// MinBackoff is the wait before the first retry. It doubles on each further retry, up
+	// to MaxBackoff, and is jittered so that clients don't retry in lockstep. A
+	// Retry-After header from the server takes precedence, but is also capped at
+	// MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is used unless WithRetryPolicy is given.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  100 * time.Millisecond,
+	MaxBackoff:  2 * time.Second,
+}
+
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests. The default has a 30s timeout.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) { c.retry = p }
+}
+
+// WithUserAgent sets the User-Agent header, so that the API's logs show which service
+// is calling.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) { c.userAgent = ua }
+}
+
+// WithToken sends token as a bearer token on every request. The REST API doesn't check
+// tokens itself (only its gRPC server does); this is for deployments behind an
+// authenticating gateway.
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// New returns a client for the API at baseURL, such as "http://localhost:4000".
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("client: invalid base URL: %w", err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("client: base URL %q must be an absolute http or https URL", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		retry:      DefaultRetryPolicy,
+		userAgent:  "one2n-student-client",
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.retry.MaxAttempts < 1 {
+		c.retry.MaxAttempts = 1
+	}
+
+	return c, nil
+}
+
+// do sends a JSON request, retrying as the retry policy allows, and decodes a
+// successful response's body into out unless it is nil. Error responses are returned as
+// an error from errors.go.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out any) error {
+	var (
+		body        []byte
+		contentType string
+	)
+
+	if in != nil {
+		var err error
+		body, err = json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("client: encoding request: %w", err)
+		}
+		contentType = "application/json"
+	}
+
+	res, err := c.send(ctx, method, path, query, contentType, body)
+	if err != nil {
+		return err
+	}
+
+	return decode(res, out)
+}
+
+// send makes the request, retrying as the retry policy allows, and returns the last
+// response. The caller must close its body.
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, contentType string, body []byte) (*http.Response, error) {
+	u := c.baseURL.JoinPath(path)
+	u.RawQuery = query.Encode()
+
+	for attempt := 1; ; attempt++ {
+		res, err := c.attempt(ctx, method, u.String(), contentType, body)
+
+		retry, wait := c.shouldRetry(method, res, err, attempt)
+		if !retry {
+			return res, err
+		}
+
+		if res != nil {
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) attempt(ctx context.Context, method, url, contentType string, body []byte) (*http.Response, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, r)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	return c.httpClient.Do(req)
+}
+
+// shouldRetry reports whether another attempt should be made after the given attempt,
+// and how long to wait first.
+func (c *Client) shouldRetry(method string, res *http.Response, err error, attempt int) (bool, time.Duration) {
+	if attempt >= c.retry.MaxAttempts {
+		return false, 0
+	}
+
+	// Only reads are safe to repeat when it is unknown whether the server acted.
+	safe := method == http.MethodGet || method == http.MethodHead
+
+	if err != nil {
+		// A cancelled or expired context is the caller giving up, not a failure to retry.
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false, 0
+		}
+		return safe, c.backoff(attempt, "")
+	}
+
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true, c.backoff(attempt, res.Header.Get("Retry-After"))
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return safe, c.backoff(attempt, res.Header.Get("Retry-After"))
+	}
+
+	return false, 0
+}
+
+func (c *Client) backoff(attempt int, retryAfter string) time.Duration {
+	if secs, err := strconv.Atoi(retryAfter); err == nil && secs >= 0 {
+		return min(time.Duration(secs)*time.Second, c.retry.MaxBackoff)
+	}
+
+	d := c.retry.MinBackoff << (attempt - 1)
+	if d <= 0 || d > c.retry.MaxBackoff {
+		d = c.retry.MaxBackoff
+	}
+
+	// Wait between half and all of the backoff.
+	half := int64(d / 2)
+	if half <= 0 {
+		return d
+	}
+	return time.Duration(half + rand.Int64N(half+1))
+}
+
+func decode(res *http.Response, out any) error {
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return errorFromResponse(res)
+	}
+
+	if out == nil {
+		io.Copy(io.Discard, res.Body)
+		return nil
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("client: decoding response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,198 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var fastRetries = WithRetryPolicy(RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond})
+
+func newTestClient(t *testing.T, h http.HandlerFunc, opts ...Option) (*Client, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		h(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	c, err := New(srv.URL, append([]Option{fastRetries}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c, &calls
+}
+
+func respond(w http.ResponseWriter, status int, body string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write([]byte(body))
+}
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		name   string
+		call   func(c *Client) error
+		status int
+		calls  int32
+	}{
+		{"get retries 503", func(c *Client) error { _, err := c.GetStudent(context.Background(), 1); return err }, 503, 3},
+		{"get retries 502", func(c *Client) error { _, err := c.GetStudent(context.Background(), 1); return err }, 502, 3},
+		{"get doesn't retry 500", func(c *Client) error { _, err := c.GetStudent(context.Background(), 1); return err }, 500, 1},
+		{"create retries 429", func(c *Client) error { _, err := c.CreateStudent(context.Background(), NewStudent{}); return err }, 429, 3},
+		{"create doesn't retry 502", func(c *Client) error { _, err := c.CreateStudent(context.Background(), NewStudent{}); return err }, 502, 1},
+		{"delete doesn't retry 404", func(c *Client) error { return c.DeleteStudent(context.Background(), 1) }, 404, 1},
+		{"delete retries 503", func(c *Client) error { return c.DeleteStudent(context.Background(), 1) }, 503, 3},
+		{"delete doesn't retry 502", func(c *Client) error { return c.DeleteStudent(context.Background(), 1) }, 502, 1},
+		{"update doesn't retry 504", func(c *Client) error { _, err := c.UpdateStudent(context.Background(), 1, StudentUpdate{}); return err }, 504, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, calls := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				respond(w, tt.status, `{"error": "nope"}`)
+			})
+
+			var apiErr *APIError
+			if err := tt.call(c); !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+				t.Fatalf("expected an APIError with status %d, got %v", tt.status, err)
+			}
+
+			if got := calls.Load(); got != tt.calls {
+				t.Fatalf("expected %d calls, got %d", tt.calls, got)
+			}
+		})
+	}
+}
+
+func TestRetrySucceeds(t *testing.T) {
+	var attempts atomic.Int32
+
+	c, calls := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		// The body must be sent again on each attempt.
+		var in NewStudent
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil || in.Name != "Ada" {
+			t.Errorf("unexpected body %+v: %v", in, err)
+		}
+
+		if attempts.Add(1) == 1 {
+			respond(w, 503, `{"error": "busy"}`)
+			return
+		}
+
+		respond(w, 201, `{"student": {"id": 9, "name": "Ada", "rollno": 1, "date_of_birth": null}}`)
+	})
+
+	s, err := c.CreateStudent(context.Background(), NewStudent{Name: "Ada", RollNo: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.ID != 9 || s.DateOfBirth != "" || calls.Load() != 2 {
+		t.Fatalf("unexpected student %+v after %d calls", s, calls.Load())
+	}
+}
+
+func TestNetworkErrorsRetryOnlyReads(t *testing.T) {
+	tests := []struct {
+		name  string
+		call  func(c *Client) error
+		calls int32
+	}{
+		{"get", func(c *Client) error { _, err := c.GetStudent(context.Background(), 1); return err }, 3},
+		{"create", func(c *Client) error { _, err := c.CreateStudent(context.Background(), NewStudent{}); return err }, 1},
+		{"update", func(c *Client) error { _, err := c.UpdateStudent(context.Background(), 1, StudentUpdate{}); return err }, 1},
+		{"delete", func(c *Client) error { return c.DeleteStudent(context.Background(), 1) }, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Drop the connection without answering, as if it broke after the server acted.
+			c, calls := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				conn, _, err := http.NewResponseController(w).Hijack()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				conn.Close()
+			})
+
+			if err := tt.call(c); err == nil {
+				t.Fatal("expected an error")
+			}
+
+			if got := calls.Load(); got != tt.calls {
+				t.Fatalf("expected %d calls, got %d", tt.calls, got)
+			}
+		})
+	}
+}
+
+func TestRetryAfterAndCancel(t *testing.T) {
+	c, calls := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		respond(w, 503, `{"error": "busy"}`)
+	}, WithRetryPolicy(RetryPolicy{MaxAttempts: 5, MinBackoff: time.Millisecond, MaxBackoff: time.Hour}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.GetStudent(ctx, 1)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the context's error, got %v", err)
+	}
+
+	if calls.Load() != 1 || time.Since(start) > 5*time.Second {
+		t.Fatalf("expected to give up while waiting out Retry-After, got %d calls in %s", calls.Load(), time.Since(start))
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		check  func(error) bool
+	}{
+		{"not found", 404, `{"error": "the requested resource could not be found"}`, func(err error) bool { return errors.Is(err, ErrNotFound) }},
+		{"conflict", 409, `{"error": "edit conflict"}`, func(err error) bool { return errors.Is(err, ErrConflict) }},
+		{"validation", 422, `{"error": {"name": "must be provided"}}`, func(err error) bool {
+			var v *ValidationError
+			return errors.As(err, &v) && v.Fields["name"] == "must be provided"
+		}},
+		{"not json", 502, `<html>bad gateway</html>`, func(err error) bool {
+			var a *APIError
+			return errors.As(err, &a) && a.Message == "<html>bad gateway</html>" && !errors.Is(err, ErrNotFound)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				respond(w, tt.status, tt.body)
+			}, WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+
+			if _, err := c.UpdateStudent(context.Background(), 1, StudentUpdate{}); !tt.check(err) {
+				t.Fatalf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, u := range []string{"", "localhost:4000", "ftp://example.com", "http://"} {
+		if _, err := New(u); err == nil {
+			t.Errorf("expected New(%q) to fail", u)
+		}
+	}
+}
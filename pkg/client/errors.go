@@ -0,0 +1,89 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrNotFound matches errors for a 404: the student doesn't exist.
+	ErrNotFound = errors.New("client: not found")
+
+	// ErrConflict matches errors for a 409: the student was changed by someone else
+	// while it was being updated. Fetch it again and retry.
+	ErrConflict = errors.New("client: edit conflict")
+)
+
+// APIError is returned for an error response other than a validation failure.
+// errors.Is(err, ErrNotFound) and errors.Is(err, ErrConflict) match it by status code.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("client: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	}
+	return false
+}
+
+// ValidationError is returned for a 422: the request was rejected because of the
+// values sent. Fields maps each invalid field to what is wrong with it.
+type ValidationError struct {
+	Fields map[string]string
+}
+
+func (e *ValidationError) Error() string {
+	fields := make([]string, 0, len(e.Fields))
+	for field, msg := range e.Fields {
+		fields = append(fields, field+" "+msg)
+	}
+	sort.Strings(fields)
+
+	return "client: validation failed: " + strings.Join(fields, ", ")
+}
+
+// errorFromResponse converts an error response, whose body is {"error": message} or,
+// for a 422, {"error": {"field": message}} or a rejected import's report, into an error.
+func errorFromResponse(res *http.Response) error {
+	var envelope struct {
+		Error json.RawMessage `json:"error"`
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+
+	if err := json.Unmarshal(body, &envelope); err != nil || envelope.Error == nil {
+		return &APIError{StatusCode: res.StatusCode, Message: strings.TrimSpace(string(body))}
+	}
+
+	if res.StatusCode == http.StatusUnprocessableEntity {
+		if err := importErrorFrom(body); err != nil {
+			return err
+		}
+
+		var fields map[string]string
+		if json.Unmarshal(envelope.Error, &fields) == nil {
+			return &ValidationError{Fields: fields}
+		}
+	}
+
+	var message string
+	if json.Unmarshal(envelope.Error, &message) != nil {
+		message = string(envelope.Error)
+	}
+
+	return &APIError{StatusCode: res.StatusCode, Message: message}
+}
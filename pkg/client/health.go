@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Health is the answer to the liveness check.
+type Health struct {
+	Status string `json:"status"`
+	Env    string `json:"env"`
+}
+
+// Readiness is the answer to the readiness check. Schema is only reported by the
+// Postgres backend.
+type Readiness struct {
+	Status string          `json:"status"`
+	Schema json.RawMessage `json:"schema,omitempty"`
+}
+
+// Ready reports whether the API is ready to serve traffic.
+func (r *Readiness) Ready() bool {
+	return r.Status == "ready"
+}
+
+// Health calls the liveness check.
+func (c *Client) Health(ctx context.Context) (*Health, error) {
+	var out Health
+
+	if err := c.do(ctx, http.MethodGet, "/v1/healthcheck", nil, nil, &out); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// Readiness calls the readiness check. An API that is up but not ready answers with a
+// 503, which is retried like any other and then returned without an error.
+func (c *Client) Readiness(ctx context.Context) (*Readiness, error) {
+	res, err := c.send(ctx, http.MethodGet, "/v1/readiness", nil, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusServiceUnavailable {
+		var out Readiness
+		if err := decode(res, &out); err != nil {
+			return nil, err
+		}
+		return &out, nil
+	}
+
+	defer res.Body.Close()
+
+	var out Readiness
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("client: decoding response: %w", err)
+	}
+
+	return &out, nil
+}
@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ImportOptions controls an import.
+type ImportOptions struct {
+	// DryRun only validates the file.
+	DryRun bool
+
+	// Columns maps student fields to the CSV columns they are read from, for files
+	// whose header doesn't use the field names.
+	Columns map[string]string
+}
+
+// ImportReport is the API's verdict on every line of an imported file.
+type ImportReport struct {
+	DryRun bool              `json:"dry_run"`
+	Total  int               `json:"total"`
+	Valid  int               `json:"valid"`
+	Errors []ImportLineError `json:"errors"`
+}
+
+type ImportLineError struct {
+	Line   int               `json:"line"`
+	Errors map[string]string `json:"errors"`
+}
+
+// ImportJob is a large import running in the background.
+type ImportJob struct {
+	ID         string     `json:"id"`
+	Status     string     `json:"status"`
+	Total      int        `json:"total"`
+	Imported   int        `json:"imported"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+type ImportResult struct {
+	Report ImportReport `json:"report"`
+
+	// Imported is the number of students imported by the request itself.
+	Imported int `json:"imported"`
+
+	// Job is set when the file was large enough to be imported in the background;
+	// poll it with ImportJob.
+	Job *ImportJob `json:"job,omitempty"`
+}
+
+// ImportStudents imports students from a CSV file. A file with any invalid line is
+// rejected as a whole with an *ImportError listing the problems.
+func (c *Client) ImportStudents(ctx context.Context, csv []byte, opts ImportOptions) (*ImportResult, error) {
+	query := url.Values{}
+	if opts.DryRun {
+		query.Set("dry_run", "true")
+	}
+	for field, column := range opts.Columns {
+		query.Set("map["+field+"]", column)
+	}
+
+	res, err := c.send(ctx, http.MethodPost, "/v1/students/import", query, "text/csv", csv)
+	if err != nil {
+		return nil, err
+	}
+
+	var out ImportResult
+	if err := decode(res, &out); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
+
+// ImportJob returns the state of a background import.
+func (c *Client) ImportJob(ctx context.Context, id string) (*ImportJob, error) {
+	var out struct {
+		Job *ImportJob `json:"job"`
+	}
+
+	if err := c.do(ctx, http.MethodGet, "/v1/students/import/"+url.PathEscape(id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+
+	return out.Job, nil
+}
+
+// ExportStudents writes every student to w in format, which is csv, ndjson or xlsx.
+// The export is streamed; it is not held in memory.
+func (c *Client) ExportStudents(ctx context.Context, format string, w io.Writer) error {
+	res, err := c.send(ctx, http.MethodGet, "/v1/students/export", url.Values{"format": {format}}, "", nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return errorFromResponse(res)
+	}
+
+	if _, err := io.Copy(w, res.Body); err != nil {
+		return fmt.Errorf("client: reading export: %w", err)
+	}
+
+	return nil
+}
+
+// ImportError is returned when an import is rejected because lines of the file are
+// invalid.
+type ImportError struct {
+	Message string
+	Report  ImportReport
+}
+
+func (e *ImportError) Error() string {
+	return fmt.Sprintf("client: %s (%d of %d lines invalid)", e.Message, len(e.Report.Errors), e.Report.Total)
+}
+
+// importErrorFrom returns an *ImportError if body is a rejected import's response.
+func importErrorFrom(body []byte) error {
+	var rejected struct {
+		Error  string        `json:"error"`
+		Report *ImportReport `json:"report"`
+	}
+
+	if json.Unmarshal(body, &rejected) != nil || rejected.Report == nil {
+		return nil
+	}
+
+	return &ImportError{Message: rejected.Error, Report: *rejected.Report}
+}
@@ -0,0 +1,163 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Student is a student as the API returns it.
+type Student struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Name      string    `json:"name"`
+	RollNo    int32     `json:"rollno"`
+	Email     string    `json:"email"`
+	Phone     string    `json:"phone"`
+	// DateOfBirth is a YYYY-MM-DD date, or empty when it isn't known.
+	DateOfBirth string `json:"date_of_birth"`
+	Address     string `json:"address"`
+	Status      string `json:"status"`
+	Version     int32  `json:"version"`
+}
+
+// NewStudent holds the fields of a student to create. Name and RollNo are required.
+// Status defaults to "applicant".
+type NewStudent struct {
+	Name        string `json:"name"`
+	RollNo      int32  `json:"rollno"`
+	Email       string `json:"email,omitempty"`
+	Phone       string `json:"phone,omitempty"`
+	DateOfBirth string `json:"date_of_birth,omitempty"`
+	Address     string `json:"address,omitempty"`
+	Status      string `json:"status,omitempty"`
+}
+
+// StudentUpdate holds the fields to change; nil fields are left as they are. A
+// student's status is changed through its transitions, not here.
+type StudentUpdate struct {
+	Name        *string `json:"name,omitempty"`
+	RollNo      *int32  `json:"rollno,omitempty"`
+	Email       *string `json:"email,omitempty"`
+	Phone       *string `json:"phone,omitempty"`
+	DateOfBirth *string `json:"date_of_birth,omitempty"`
+	Address     *string `json:"address,omitempty"`
+}
+
+// ListOptions controls how ListStudents pages through students.
+type ListOptions struct {
+	// PageSize is the number of students fetched per request, at most 1000. It defaults
+	// to 100.
+	PageSize int
+}
+
+func studentPath(id int64) string {
+	return "/v1/students/" + strconv.FormatInt(id, 10)
+}
+
+// CreateStudent creates a student. It returns a *ValidationError if the API rejects
+// the fields.
+func (c *Client) CreateStudent(ctx context.Context, s NewStudent) (*Student, error) {
+	var out struct {
+		Student *Student `json:"student"`
+	}
+
+	if err := c.do(ctx, http.MethodPost, "/v1/students", nil, s, &out); err != nil {
+		return nil, err
+	}
+
+	return out.Student, nil
+}
+
+// GetStudent returns the student with the given ID, or an error matching ErrNotFound.
+func (c *Client) GetStudent(ctx context.Context, id int64) (*Student, error) {
+	var out struct {
+		Student *Student `json:"student"`
+	}
+
+	if err := c.do(ctx, http.MethodGet, studentPath(id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+
+	return out.Student, nil
+}
+
+// ListStudentsPage returns up to limit students in ID order, after skipping offset of
+// them.
+func (c *Client) ListStudentsPage(ctx context.Context, limit, offset int) ([]*Student, error) {
+	query := url.Values{
+		"limit":  {strconv.Itoa(limit)},
+		"offset": {strconv.Itoa(offset)},
+	}
+
+	var out struct {
+		Students []*Student `json:"students"`
+	}
+
+	if err := c.do(ctx, http.MethodGet, "/v1/students", query, nil, &out); err != nil {
+		return nil, err
+	}
+
+	return out.Students, nil
+}
+
+// ListStudents iterates over every student in ID order, fetching a page at a time:
+//
+//	for student, err := range c.ListStudents(ctx, client.ListOptions{}) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// Iteration stops after the first error. Pages are fetched by offset, so students
+// created or deleted while iterating may be missed or seen twice.
+func (c *Client) ListStudents(ctx context.Context, opts ListOptions) iter.Seq2[*Student, error] {
+	size := opts.PageSize
+	if size <= 0 {
+		size = 100
+	}
+	size = min(size, 1000)
+
+	return func(yield func(*Student, error) bool) {
+		for offset := 0; ; offset += size {
+			page, err := c.ListStudentsPage(ctx, size, offset)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			for _, s := range page {
+				if !yield(s, nil) {
+					return
+				}
+			}
+
+			if len(page) < size {
+				return
+			}
+		}
+	}
+}
+
+// UpdateStudent changes the fields set in u and returns the updated student.
+func (c *Client) UpdateStudent(ctx context.Context, id int64, u StudentUpdate) (*Student, error) {
+	var out struct {
+		Student *Student `json:"student"`
+	}
+
+	if err := c.do(ctx, http.MethodPatch, studentPath(id), nil, u, &out); err != nil {
+		return nil, err
+	}
+
+	return out.Student, nil
+}
+
+// DeleteStudent deletes the student with the given ID.
+func (c *Client) DeleteStudent(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, studentPath(id), nil, nil, nil)
+}